@@ -9,6 +9,9 @@ func main() {
 	log := config.NewLlogger(&conf.Logrus)
 	db := config.NewDatabase(&conf.Database, log)
 	validate := config.NewValidator()
+	jwt := config.NewJwt(&conf.Jwt)
+	hasher := config.NewPasswordHasher(&conf.PasswordHash, log)
+	mailer := config.NewMailer(&conf.Mail, log)
 	app := config.NewChi(conf)
 
 	config.Bootstrap(&config.BootstrapConfig{
@@ -16,6 +19,10 @@ func main() {
 		App: app,
 		Log: log,
 		Validate: validate,
+		Jwt: jwt,
+		Hasher: hasher,
+		Mailer: mailer,
+		Config: conf,
 	})
 
 	log.Fatal(config.StartServer(app, &conf.Server, log))
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS sessions (
+    id           VARCHAR(100) NOT NULL,
+    user_id      VARCHAR(100) NOT NULL,
+    token        VARCHAR(100) NOT NULL,
+    user_agent   VARCHAR(255),
+    ip_address   VARCHAR(100),
+    last_seen_at BIGINT       NOT NULL,
+    created_at   BIGINT       NOT NULL,
+    updated_at   BIGINT       NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT uq_sessions_token UNIQUE (token),
+    CONSTRAINT fk_sessions_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token VARCHAR(100) NULL;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE sessions DROP CONSTRAINT IF EXISTS chk_sessions_token_digest;
\ No newline at end of file
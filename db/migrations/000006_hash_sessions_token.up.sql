@@ -0,0 +1,5 @@
+-- tokens issued before this migration are stored in plaintext, they cannot be
+-- converted into digests the clients would match so those sessions are dropped
+DELETE FROM sessions WHERE char_length(token) <> 64;
+
+ALTER TABLE sessions ADD CONSTRAINT chk_sessions_token_digest CHECK (char_length(token) = 64);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id             VARCHAR(150) NOT NULL,
+    failures       INT          NOT NULL DEFAULT 0,
+    retry_at       BIGINT       NOT NULL DEFAULT 0,
+    locked_until   BIGINT       NOT NULL DEFAULT 0,
+    last_failed_at BIGINT       NOT NULL DEFAULT 0,
+    created_at     BIGINT       NOT NULL,
+    updated_at     BIGINT       NOT NULL,
+    PRIMARY KEY (id)
+);
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email VARCHAR(200) NULL;
\ No newline at end of file
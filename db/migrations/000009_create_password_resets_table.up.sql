@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS password_resets (
+    id         VARCHAR(100) NOT NULL,
+    user_id    VARCHAR(100) NOT NULL,
+    token      VARCHAR(100) NOT NULL,
+    expires_at BIGINT       NOT NULL,
+    created_at BIGINT       NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT uq_password_resets_token UNIQUE (token),
+    CONSTRAINT fk_password_resets_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+);
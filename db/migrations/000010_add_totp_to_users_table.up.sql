@@ -0,0 +1,4 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS totp_secret    VARCHAR(100) NULL,
+    ADD COLUMN IF NOT EXISTS totp_enabled   BOOLEAN      NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS totp_last_step BIGINT       NOT NULL DEFAULT 0;
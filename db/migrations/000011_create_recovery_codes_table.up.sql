@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id         VARCHAR(100) NOT NULL,
+    user_id    VARCHAR(100) NOT NULL,
+    code       VARCHAR(100) NOT NULL,
+    created_at BIGINT       NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT uq_recovery_codes_user_id_code UNIQUE (user_id, code),
+    CONSTRAINT fk_recovery_codes_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+);
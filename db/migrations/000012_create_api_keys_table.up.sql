@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           VARCHAR(100) NOT NULL,
+    user_id      VARCHAR(100) NOT NULL,
+    name         VARCHAR(100) NOT NULL,
+    prefix       VARCHAR(20)  NOT NULL,
+    token        VARCHAR(100) NOT NULL,
+    scopes       VARCHAR(255) NOT NULL,
+    last_used_at BIGINT       NOT NULL DEFAULT 0,
+    created_at   BIGINT       NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT uq_api_keys_token UNIQUE (token),
+    CONSTRAINT fk_api_keys_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys (user_id);
\ No newline at end of file
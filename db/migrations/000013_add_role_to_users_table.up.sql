@@ -0,0 +1,4 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role     VARCHAR(20) NOT NULL DEFAULT 'user',
+    ADD COLUMN IF NOT EXISTS disabled BOOLEAN     NOT NULL DEFAULT FALSE,
+    ADD CONSTRAINT chk_users_role CHECK (role IN ('user', 'admin'));
\ No newline at end of file
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_delete_at;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS delete_at;
\ No newline at end of file
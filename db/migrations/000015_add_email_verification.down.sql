@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS email_verifications;
+
+DROP INDEX IF EXISTS uq_users_email;
+
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
\ No newline at end of file
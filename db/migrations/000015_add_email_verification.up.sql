@@ -0,0 +1,18 @@
+UPDATE users SET email = lower(trim(email)) WHERE email IS NOT NULL;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at BIGINT NOT NULL DEFAULT 0;
+
+-- only a verified address is owned, unverified claims are released when an account verifies it
+CREATE UNIQUE INDEX IF NOT EXISTS uq_users_email ON users (email) WHERE email <> '' AND email_verified_at > 0;
+
+CREATE TABLE IF NOT EXISTS email_verifications (
+    id         VARCHAR(100) NOT NULL,
+    user_id    VARCHAR(100) NOT NULL,
+    email      VARCHAR(200) NOT NULL,
+    token      VARCHAR(100) NOT NULL,
+    expires_at BIGINT       NOT NULL,
+    created_at BIGINT       NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT uq_email_verifications_token UNIQUE (token),
+    CONSTRAINT fk_email_verifications_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+);
\ No newline at end of file
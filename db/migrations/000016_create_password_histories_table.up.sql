@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS password_histories (
+    id         VARCHAR(100) NOT NULL,
+    user_id    VARCHAR(100) NOT NULL,
+    password   VARCHAR(255) NOT NULL,
+    created_at BIGINT       NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_password_histories_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_histories_user_id ON password_histories (user_id, created_at);
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- argon2id hashes in the PHC format are close to 100 characters and grow with the parameters
+ALTER TABLE users ALTER COLUMN password TYPE VARCHAR(255);
\ No newline at end of file
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS user_identities (
+    id         VARCHAR(100) NOT NULL,
+    user_id    VARCHAR(100) NOT NULL,
+    provider   VARCHAR(100) NOT NULL,
+    subject    VARCHAR(255) NOT NULL,
+    email      VARCHAR(200) NOT NULL DEFAULT '',
+    created_at BIGINT       NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT uq_user_identities_provider_subject UNIQUE (provider, subject),
+    CONSTRAINT fk_user_identities_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities (user_id);
+
+-- pending authorization requests, user_id is only set when the flow links an identity to a logged in user
+CREATE TABLE IF NOT EXISTS oidc_states (
+    id            VARCHAR(100) NOT NULL,
+    provider      VARCHAR(100) NOT NULL,
+    state         VARCHAR(100) NOT NULL,
+    nonce         VARCHAR(100) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    user_id       VARCHAR(100) NOT NULL DEFAULT '',
+    expires_at    BIGINT       NOT NULL,
+    created_at    BIGINT       NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT uq_oidc_states_state UNIQUE (state)
+);
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS magic_links (
+    id         VARCHAR(100) NOT NULL,
+    user_id    VARCHAR(100) NOT NULL,
+    email      VARCHAR(200) NOT NULL,
+    token      VARCHAR(100) NOT NULL,
+    expires_at BIGINT       NOT NULL,
+    created_at BIGINT       NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT uq_magic_links_token UNIQUE (token),
+    CONSTRAINT fk_magic_links_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_magic_links_email_created_at ON magic_links (email, created_at);
\ No newline at end of file
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS audit_logs;
+
+ALTER TABLE sessions DROP COLUMN IF EXISTS read_only;
+ALTER TABLE sessions DROP COLUMN IF EXISTS impersonator_id;
\ No newline at end of file
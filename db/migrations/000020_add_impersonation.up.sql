@@ -0,0 +1,20 @@
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS impersonator_id VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS read_only BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- no foreign keys, the trail outlives both the impersonated user and the admin
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id              VARCHAR(100)  NOT NULL,
+    action          VARCHAR(50)   NOT NULL,
+    session_id      VARCHAR(100)  NOT NULL,
+    impersonator_id VARCHAR(100)  NOT NULL,
+    user_id         VARCHAR(100)  NOT NULL,
+    method          VARCHAR(10)   NOT NULL DEFAULT '',
+    path            VARCHAR(2048) NOT NULL DEFAULT '',
+    status          INT           NOT NULL DEFAULT 0,
+    ip_address      VARCHAR(100)  NOT NULL DEFAULT '',
+    created_at      BIGINT        NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_impersonator_id ON audit_logs (impersonator_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_user_id ON audit_logs (user_id, created_at);
\ No newline at end of file
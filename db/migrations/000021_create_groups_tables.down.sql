@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS contact_groups;
+DROP TABLE IF EXISTS groups;
\ No newline at end of file
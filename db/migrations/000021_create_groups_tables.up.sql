@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS groups (
+    id         VARCHAR(100) NOT NULL,
+    user_id    VARCHAR(100) NOT NULL,
+    name       VARCHAR(100) NOT NULL,
+    created_at BIGINT       NOT NULL,
+    updated_at BIGINT       NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT uq_groups_user_id_name UNIQUE (user_id, name),
+    CONSTRAINT fk_groups_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE TABLE IF NOT EXISTS contact_groups (
+    contact_id VARCHAR(100) NOT NULL,
+    group_id   VARCHAR(100) NOT NULL,
+    created_at BIGINT       NOT NULL,
+    PRIMARY KEY (contact_id, group_id),
+    CONSTRAINT fk_contact_groups_contact_id FOREIGN KEY (contact_id) REFERENCES contacts (id),
+    CONSTRAINT fk_contact_groups_group_id FOREIGN KEY (group_id) REFERENCES groups (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contact_groups_group_id ON contact_groups (group_id);
\ No newline at end of file
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_contacts_search_vector;
+DROP TRIGGER IF EXISTS trg_addresses_search_vector ON addresses;
+DROP FUNCTION IF EXISTS addresses_search_vector_update();
+DROP TRIGGER IF EXISTS trg_contacts_search_vector ON contacts;
+DROP FUNCTION IF EXISTS contacts_search_vector_update();
+DROP FUNCTION IF EXISTS contacts_search_vector(VARCHAR, VARCHAR, VARCHAR, VARCHAR, VARCHAR);
+ALTER TABLE contacts DROP COLUMN IF EXISTS search_vector;
\ No newline at end of file
@@ -0,0 +1,50 @@
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS search_vector TSVECTOR NOT NULL DEFAULT ''::tsvector;
+
+-- the simple configuration keeps names as typed instead of stemming them as english words,
+-- names weigh most, then email and phone, then the addresses of the contact
+CREATE OR REPLACE FUNCTION contacts_search_vector(contact_id VARCHAR, first_name VARCHAR, last_name VARCHAR, email VARCHAR, phone VARCHAR)
+RETURNS TSVECTOR AS $$
+    SELECT setweight(to_tsvector('simple', coalesce(first_name, '') || ' ' || coalesce(last_name, '')), 'A')
+        || setweight(to_tsvector('simple', coalesce(email, '') || ' ' || coalesce(phone, '')), 'B')
+        || setweight(to_tsvector('simple', coalesce((
+            SELECT string_agg(coalesce(a.street, '') || ' ' || coalesce(a.city, ''), ' ')
+            FROM addresses a
+            WHERE a.contact_id = contacts_search_vector.contact_id
+        ), '')), 'C');
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION contacts_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := contacts_search_vector(NEW.id, NEW.first_name, NEW.last_name, NEW.email, NEW.phone);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_contacts_search_vector
+    BEFORE INSERT OR UPDATE ON contacts
+    FOR EACH ROW EXECUTE FUNCTION contacts_search_vector_update();
+
+-- touching the contact lets its own trigger pick up the changed addresses
+CREATE OR REPLACE FUNCTION addresses_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE contacts SET search_vector = search_vector WHERE id = NEW.contact_id;
+    ELSIF TG_OP = 'UPDATE' THEN
+        UPDATE contacts SET search_vector = search_vector WHERE id = OLD.contact_id;
+        IF NEW.contact_id <> OLD.contact_id THEN
+            UPDATE contacts SET search_vector = search_vector WHERE id = NEW.contact_id;
+        END IF;
+    ELSE
+        UPDATE contacts SET search_vector = search_vector WHERE id = OLD.contact_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_addresses_search_vector
+    AFTER INSERT OR UPDATE OR DELETE ON addresses
+    FOR EACH ROW EXECUTE FUNCTION addresses_search_vector_update();
+
+UPDATE contacts SET search_vector = search_vector;
+
+CREATE INDEX IF NOT EXISTS idx_contacts_search_vector ON contacts USING GIN (search_vector);
\ No newline at end of file
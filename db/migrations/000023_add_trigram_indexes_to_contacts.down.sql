@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_contacts_email_trgm;
+DROP INDEX IF EXISTS idx_contacts_full_name_trgm;
\ No newline at end of file
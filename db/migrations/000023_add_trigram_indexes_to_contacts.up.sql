@@ -0,0 +1,5 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- the name expression has to match contactFullName in the contact repository
+CREATE INDEX IF NOT EXISTS idx_contacts_full_name_trgm ON contacts USING GIN ((first_name || ' ' || coalesce(last_name, '')) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_contacts_email_trgm ON contacts USING GIN (email gin_trgm_ops);
\ No newline at end of file
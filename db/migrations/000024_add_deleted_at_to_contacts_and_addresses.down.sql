@@ -0,0 +1,15 @@
+CREATE OR REPLACE FUNCTION contacts_search_vector(contact_id VARCHAR, first_name VARCHAR, last_name VARCHAR, email VARCHAR, phone VARCHAR)
+RETURNS TSVECTOR AS $$
+    SELECT setweight(to_tsvector('simple', coalesce(first_name, '') || ' ' || coalesce(last_name, '')), 'A')
+        || setweight(to_tsvector('simple', coalesce(email, '') || ' ' || coalesce(phone, '')), 'B')
+        || setweight(to_tsvector('simple', coalesce((
+            SELECT string_agg(coalesce(a.street, '') || ' ' || coalesce(a.city, ''), ' ')
+            FROM addresses a
+            WHERE a.contact_id = contacts_search_vector.contact_id
+        ), '')), 'C');
+$$ LANGUAGE sql STABLE;
+
+DROP INDEX IF EXISTS idx_addresses_deleted_at;
+DROP INDEX IF EXISTS idx_contacts_deleted_at;
+ALTER TABLE addresses DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE contacts DROP COLUMN IF EXISTS deleted_at;
\ No newline at end of file
@@ -0,0 +1,18 @@
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS deleted_at BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE addresses ADD COLUMN IF NOT EXISTS deleted_at BIGINT NOT NULL DEFAULT 0;
+
+-- only the trash view and the purge job look for deleted rows
+CREATE INDEX IF NOT EXISTS idx_contacts_deleted_at ON contacts (user_id, deleted_at) WHERE deleted_at > 0;
+CREATE INDEX IF NOT EXISTS idx_addresses_deleted_at ON addresses (contact_id, deleted_at) WHERE deleted_at > 0;
+
+-- addresses in the trash no longer make their contact searchable
+CREATE OR REPLACE FUNCTION contacts_search_vector(contact_id VARCHAR, first_name VARCHAR, last_name VARCHAR, email VARCHAR, phone VARCHAR)
+RETURNS TSVECTOR AS $$
+    SELECT setweight(to_tsvector('simple', coalesce(first_name, '') || ' ' || coalesce(last_name, '')), 'A')
+        || setweight(to_tsvector('simple', coalesce(email, '') || ' ' || coalesce(phone, '')), 'B')
+        || setweight(to_tsvector('simple', coalesce((
+            SELECT string_agg(coalesce(a.street, '') || ' ' || coalesce(a.city, ''), ' ')
+            FROM addresses a
+            WHERE a.contact_id = contacts_search_vector.contact_id AND a.deleted_at = 0
+        ), '')), 'C');
+$$ LANGUAGE sql STABLE;
\ No newline at end of file
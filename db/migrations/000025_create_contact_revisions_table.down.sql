@@ -0,0 +1 @@
+DROP TABLE IF EXISTS contact_revisions;
\ No newline at end of file
@@ -0,0 +1,19 @@
+-- every change to a contact or one of its addresses, address_id is empty for the contact itself.
+-- changes holds the old and new value of each changed field, snapshot the fields after the change
+-- (before it for a delete) so a revert does not have to replay the history
+CREATE TABLE IF NOT EXISTS contact_revisions (
+    id              VARCHAR(100) NOT NULL,
+    contact_id      VARCHAR(100) NOT NULL,
+    address_id      VARCHAR(100) NOT NULL DEFAULT '',
+    user_id         VARCHAR(100) NOT NULL,
+    impersonator_id VARCHAR(100) NOT NULL DEFAULT '',
+    action          VARCHAR(20)  NOT NULL,
+    changes         JSONB        NOT NULL,
+    snapshot        JSONB        NOT NULL,
+    created_at      BIGINT       NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_contact_revisions_contact_id FOREIGN KEY (contact_id) REFERENCES contacts (id),
+    CONSTRAINT fk_contact_revisions_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contact_revisions_contact_id ON contact_revisions (contact_id, created_at);
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_contacts_user_id_phone_digits;
+DROP INDEX IF EXISTS idx_contacts_user_id_email_lower;
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_contacts_user_id_email_lower ON contacts (user_id, lower(email));
+CREATE INDEX IF NOT EXISTS idx_contacts_user_id_phone_digits ON contacts (user_id, ltrim(regexp_replace(coalesce(phone, ''), '[^0-9]', '', 'g'), '0'));
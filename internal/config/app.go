@@ -1,11 +1,15 @@
 package config
 
 import (
+	"time"
+
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/iyasz/golang-clean-architecture/internal/delivery/http/controller"
 	"github.com/iyasz/golang-clean-architecture/internal/delivery/http/middleware"
 	"github.com/iyasz/golang-clean-architecture/internal/delivery/http/route"
+	"github.com/iyasz/golang-clean-architecture/internal/gateway/mail"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
 	"github.com/iyasz/golang-clean-architecture/internal/repository"
 	"github.com/iyasz/golang-clean-architecture/internal/usecase"
 	"github.com/sirupsen/logrus"
@@ -17,6 +21,10 @@ type BootstrapConfig struct {
 	App      *chi.Mux
 	Log      *logrus.Logger
 	Validate *validator.Validate
+	Jwt      *helper.Jwt
+	Hasher   helper.PasswordHasher
+	Mailer   mail.Mailer
+	Config   *Config
 }
 
 func Bootstrap(config *BootstrapConfig) {
@@ -25,28 +33,142 @@ func Bootstrap(config *BootstrapConfig) {
 	userRepository := repository.NewUserRepository(config.Log)
 	contactRepository := repository.NewContactRepository(config.Log)
 	addressRepository := repository.NewAddressRepository(config.Log)
+	sessionRepository := repository.NewSessionRepository(config.Log)
+	loginAttemptRepository := repository.NewLoginAttemptRepository(config.Log)
+	passwordResetRepository := repository.NewPasswordResetRepository(config.Log)
+	recoveryCodeRepository := repository.NewRecoveryCodeRepository(config.Log)
+	apiKeyRepository := repository.NewApiKeyRepository(config.Log)
+	emailVerificationRepository := repository.NewEmailVerificationRepository(config.Log)
+	passwordHistoryRepository := repository.NewPasswordHistoryRepository(config.Log)
+	userIdentityRepository := repository.NewUserIdentityRepository(config.Log)
+	oidcStateRepository := repository.NewOidcStateRepository(config.Log)
+	magicLinkRepository := repository.NewMagicLinkRepository(config.Log)
+	auditLogRepository := repository.NewAuditLogRepository(config.Log)
+	groupRepository := repository.NewGroupRepository(config.Log)
+	contactGroupRepository := repository.NewContactGroupRepository(config.Log)
+	contactRevisionRepository := repository.NewContactRevisionRepository(config.Log)
 
 	// setup use cases
-	userUseCase := usecase.NewUserUseCase(config.DB, config.Log, config.Validate, userRepository)
-	contactUseCase := usecase.NewContactUseCase(config.DB, config.Log, config.Validate, contactRepository)
-	addressUseCase := usecase.NewAddressUseCase(config.DB, config.Log, config.Validate, addressRepository, contactRepository)
+	passwordPolicyConfig := &usecase.PasswordPolicyConfig{
+		MinLength:           config.Config.PasswordPolicy.MinLength,
+		MinCharacterClasses: config.Config.PasswordPolicy.MinCharacterClasses,
+		History:             config.Config.PasswordPolicy.History,
+	}
+	passwordPolicy := usecase.NewPasswordPolicy(config.Log, config.Hasher, passwordPolicyConfig, passwordHistoryRepository)
+	emailVerificationConfig := &usecase.EmailVerificationConfig{
+		TokenTTL: time.Second * time.Duration(config.Config.EmailVerification.TTL),
+		AppUrl:   config.Config.App.Url,
+	}
+	emailVerificationUseCase := usecase.NewEmailVerificationUseCase(config.DB, config.Log, config.Validate, config.Mailer, emailVerificationConfig,
+		userRepository, emailVerificationRepository)
+	loginThrottleConfig := &usecase.LoginThrottleConfig{
+		MaxAttempts:      config.Config.Login.MaxAttempts,
+		MaxAttemptsPerIp: config.Config.Login.MaxAttemptsPerIp,
+		Delay:            time.Second * time.Duration(config.Config.Login.Delay),
+		Lockout:          time.Second * time.Duration(config.Config.Login.Lockout),
+	}
+	loginThrottle := usecase.NewLoginThrottle(config.Log, loginThrottleConfig, loginAttemptRepository)
+	tokenConfig := &usecase.TokenConfig{
+		SessionMaxAge:       time.Second * time.Duration(config.Config.Session.MaxAge),
+		SessionIdleTimeout:  time.Second * time.Duration(config.Config.Session.IdleTimeout),
+		ImpersonationMaxAge: time.Second * time.Duration(config.Config.Session.ImpersonationMaxAge),
+	}
+	tokenUseCase := usecase.NewTokenUseCase(config.Log, config.Jwt, tokenConfig, sessionRepository)
+	userUseCase := usecase.NewUserUseCase(config.DB, config.Log, config.Validate, config.Hasher, userRepository, recoveryCodeRepository,
+		sessionRepository, auditLogRepository, emailVerificationUseCase, passwordPolicy, loginThrottle, tokenUseCase)
+	accountConfig := &usecase.AccountConfig{
+		DeletionGrace: time.Second * time.Duration(config.Config.Account.DeletionGrace),
+	}
+	accountUseCase := usecase.NewAccountUseCase(config.DB, config.Log, config.Validate, config.Hasher, accountConfig, loginThrottle,
+		userRepository, sessionRepository, contactRepository, addressRepository, groupRepository, contactGroupRepository,
+		contactRevisionRepository, apiKeyRepository, recoveryCodeRepository, passwordResetRepository, emailVerificationRepository,
+		passwordHistoryRepository, userIdentityRepository, magicLinkRepository)
+	contactConfig := &usecase.ContactConfig{
+		TrashRetention: time.Hour * 24 * time.Duration(config.Config.Trash.RetentionDays),
+	}
+	contactUseCase := usecase.NewContactUseCase(config.DB, config.Log, config.Validate, contactConfig, contactRepository, addressRepository,
+		contactGroupRepository, contactRevisionRepository)
+	addressUseCase := usecase.NewAddressUseCase(config.DB, config.Log, config.Validate, addressRepository, contactRepository, contactRevisionRepository)
+	sessionUseCase := usecase.NewSessionUseCase(config.DB, config.Log, config.Validate, sessionRepository)
+	passwordResetConfig := &usecase.PasswordResetConfig{
+		TokenTTL: time.Second * time.Duration(config.Config.PasswordReset.TTL),
+		AppUrl:   config.Config.App.Url,
+	}
+	passwordResetUseCase := usecase.NewPasswordResetUseCase(config.DB, config.Log, config.Validate, config.Mailer, config.Hasher, passwordResetConfig,
+		userRepository, sessionRepository, passwordResetRepository, passwordPolicy)
+	totpConfig := &usecase.TotpConfig{
+		Issuer: config.Config.App.Name,
+	}
+	totpUseCase := usecase.NewTotpUseCase(config.DB, config.Log, config.Validate, config.Hasher, totpConfig, loginThrottle, userRepository,
+		recoveryCodeRepository)
+	adminUseCase := usecase.NewAdminUseCase(config.DB, config.Log, config.Validate, userRepository, contactRepository, sessionRepository)
+	apiKeyUseCase := usecase.NewApiKeyUseCase(config.DB, config.Log, config.Validate, userRepository, apiKeyRepository)
+	oidcUseCase := usecase.NewOidcUseCase(config.DB, config.Log, config.Validate, NewOidcProviders(&config.Config.Oidc), tokenUseCase,
+		userRepository, userIdentityRepository, oidcStateRepository)
+	magicLinkConfig := &usecase.MagicLinkConfig{
+		TokenTTL:    time.Second * time.Duration(config.Config.MagicLink.TTL),
+		MaxRequests: config.Config.MagicLink.MaxRequests,
+		Window:      time.Second * time.Duration(config.Config.MagicLink.Window),
+		AppUrl:      config.Config.App.Url,
+	}
+	magicLinkUseCase := usecase.NewMagicLinkUseCase(config.DB, config.Log, config.Validate, config.Mailer, magicLinkConfig, tokenUseCase,
+		userRepository, magicLinkRepository)
+	impersonationUseCase := usecase.NewImpersonationUseCase(config.DB, config.Log, config.Validate, tokenUseCase, userRepository,
+		sessionRepository, auditLogRepository)
+	groupUseCase := usecase.NewGroupUseCase(config.DB, config.Log, config.Validate, groupRepository, contactGroupRepository, contactRepository)
+	contactRevisionUseCase := usecase.NewContactRevisionUseCase(config.DB, config.Log, config.Validate, contactRepository, addressRepository,
+		contactRevisionRepository)
 
 	// setup controller
 	userController := controller.NewUserController(config.Log, userUseCase)
+	accountController := controller.NewAccountController(config.Log, accountUseCase)
 	contactController := controller.NewContactController(config.Log, contactUseCase)
 	addressController := controller.NewAddressController(config.Log, addressUseCase)
+	sessionController := controller.NewSessionController(config.Log, sessionUseCase)
+	passwordResetController := controller.NewPasswordResetController(config.Log, passwordResetUseCase)
+	emailVerificationController := controller.NewEmailVerificationController(config.Log, emailVerificationUseCase)
+	totpController := controller.NewTotpController(config.Log, totpUseCase)
+	adminController := controller.NewAdminController(config.Log, adminUseCase)
+	apiKeyController := controller.NewApiKeyController(config.Log, apiKeyUseCase)
+	oidcController := controller.NewOidcController(config.Log, oidcUseCase)
+	magicLinkController := controller.NewMagicLinkController(config.Log, magicLinkUseCase)
+	impersonationController := controller.NewImpersonationController(config.Log, impersonationUseCase)
+	groupController := controller.NewGroupController(config.Log, groupUseCase)
+	contactRevisionController := controller.NewContactRevisionController(config.Log, contactRevisionUseCase)
 
 	// setup middleware
-	authMiddleware := middleware.NewAuth(userUseCase)
+	authMiddleware := middleware.NewAuth(userUseCase, apiKeyUseCase)
+	auditMiddleware := middleware.NewAudit(impersonationUseCase)
 
 	routeConfig := route.RouteConfig{
-		App:               config.App,
-		UserController:    userController,
-		ContactController: contactController,
-		AddressController: addressController,
-		AuthMiddleware:    authMiddleware,
+		App:                         config.App,
+		UserController:              userController,
+		AccountController:           accountController,
+		ContactController:           contactController,
+		AddressController:           addressController,
+		SessionController:           sessionController,
+		PasswordResetController:     passwordResetController,
+		EmailVerificationController: emailVerificationController,
+		TotpController:              totpController,
+		ApiKeyController:            apiKeyController,
+		AdminController:             adminController,
+		OidcController:              oidcController,
+		MagicLinkController:         magicLinkController,
+		ImpersonationController:     impersonationController,
+		GroupController:             groupController,
+		ContactRevisionController:   contactRevisionController,
+		AuthMiddleware:              authMiddleware,
+		AuditMiddleware:             auditMiddleware,
 	}
 
 	routeConfig.Setup()
 
+	// setup jobs
+	if interval := config.Config.Account.PurgeInterval; interval > 0 {
+		schedule(config.Log, "purge deleted users", time.Second*time.Duration(interval), accountUseCase.PurgeDeleted)
+	}
+	if interval := config.Config.Trash.PurgeInterval; interval > 0 {
+		schedule(config.Log, "purge trash", time.Second*time.Duration(interval), contactUseCase.PurgeTrash)
+	}
+
 }
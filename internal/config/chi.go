@@ -6,6 +6,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	appmiddleware "github.com/iyasz/golang-clean-architecture/internal/delivery/http/middleware"
 	"github.com/sirupsen/logrus"
 )
 
@@ -15,7 +16,7 @@ func NewChi(conf *Config) *chi.Mux {
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
+	r.Use(appmiddleware.NewRealIP(conf.Server.TrustedProxies))
 	r.Use(middleware.Timeout(60 * time.Second))
 
 	// r.Use(middleware.SetHeader("Content-Type", "application/json"))
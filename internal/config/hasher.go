@@ -0,0 +1,23 @@
+package config
+
+import (
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/sirupsen/logrus"
+)
+
+// NewPasswordHasher hashes with the configured driver, hashes of the other one still verify
+// and are upgraded on the next login
+func NewPasswordHasher(config *PasswordHash, log *logrus.Logger) helper.PasswordHasher {
+	argon2id := helper.NewArgon2idHasher(uint32(config.Argon2Memory), uint32(config.Argon2Iterations), uint8(config.Argon2Parallelism))
+	bcrypt := helper.NewBcryptHasher(config.BcryptCost)
+
+	switch config.Driver {
+	case "argon2id":
+		return helper.NewMigratingHasher(argon2id, bcrypt)
+	case "bcrypt":
+		return helper.NewMigratingHasher(bcrypt, argon2id)
+	default:
+		log.Fatalf("unknown password hash driver: %s", config.Driver)
+		return nil
+	}
+}
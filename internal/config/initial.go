@@ -2,9 +2,11 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -18,6 +20,73 @@ func getEnvInt(val string) int {
 	return res
 }
 
+// getEnvIntOr falls back for keys added after a deployment was first configured
+func getEnvIntOr(key string, fallback int) int {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback
+	}
+
+	return getEnvInt(val)
+}
+
+func getEnvOr(key string, fallback string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+
+	return fallback
+}
+
+// getTrustedProxies parses a comma separated list of addresses or CIDR ranges
+func getTrustedProxies(val string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, entry := range strings.Split(val, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				panic(fmt.Errorf("failed to parse trusted proxy: %w", err))
+			}
+			entry = netip.PrefixFrom(addr, addr.BitLen()).String()
+		}
+
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			panic(fmt.Errorf("failed to parse trusted proxy: %w", err))
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return prefixes
+}
+
+// getOidcProviders reads OIDC_<NAME>_* for every name listed in OIDC_PROVIDERS
+func getOidcProviders(val string) []OidcProvider {
+	var providers []OidcProvider
+	for _, name := range strings.Split(val, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		providers = append(providers, OidcProvider{
+			Name: name,
+			Issuer: os.Getenv(prefix + "ISSUER"),
+			ClientId: os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectUrl: os.Getenv(prefix + "REDIRECT_URL"),
+		})
+	}
+
+	return providers
+}
+
 func Load(appEnv string) *Config {
 	err := godotenv.Load()
 
@@ -32,11 +101,13 @@ func Load(appEnv string) *Config {
 	return &Config{
 		App: App{
 			Name: os.Getenv("APP_NAME"),
+			Url: os.Getenv("APP_URL"),
 		},
 		Server: Server{
 			Host: os.Getenv("SERVER_HOST"),
 			Port: os.Getenv("SERVER_PORT"),
 			Prefork: os.Getenv("SERVER_PREFORK"),
+			TrustedProxies: getTrustedProxies(os.Getenv("TRUSTED_PROXIES")),
 		},
 		Database: Database{
 			Host: os.Getenv("DATABASE_HOST"),
@@ -51,6 +122,66 @@ func Load(appEnv string) *Config {
 				Lifetime: getEnvInt(os.Getenv("POOL_LIFETIME")),
 			},
 		},
+		Jwt: Jwt{
+			AccessKey: os.Getenv("JWT_ACCESS_KEY"),
+			RefreshKey: os.Getenv("JWT_REFRESH_KEY"),
+			AccessTTL: getEnvIntOr("JWT_ACCESS_TTL", 900),
+			RefreshTTL: getEnvIntOr("JWT_REFRESH_TTL", 604800),
+		},
+		Session: Session{
+			MaxAge: getEnvIntOr("SESSION_MAX_AGE", 2592000),
+			IdleTimeout: getEnvIntOr("SESSION_IDLE_TIMEOUT", 86400),
+			ImpersonationMaxAge: getEnvIntOr("SESSION_IMPERSONATION_MAX_AGE", 3600),
+		},
+		Login: Login{
+			MaxAttempts: getEnvIntOr("LOGIN_MAX_ATTEMPTS", 5),
+			MaxAttemptsPerIp: getEnvIntOr("LOGIN_MAX_ATTEMPTS_PER_IP", 20),
+			Delay: getEnvIntOr("LOGIN_DELAY", 1),
+			Lockout: getEnvIntOr("LOGIN_LOCKOUT", 900),
+		},
+		PasswordReset: PasswordReset{
+			TTL: getEnvIntOr("PASSWORD_RESET_TTL", 3600),
+		},
+		PasswordPolicy: PasswordPolicy{
+			MinLength: getEnvIntOr("PASSWORD_MIN_LENGTH", 8),
+			MinCharacterClasses: getEnvIntOr("PASSWORD_MIN_CHARACTER_CLASSES", 3),
+			History: getEnvIntOr("PASSWORD_HISTORY", 5),
+		},
+		PasswordHash: PasswordHash{
+			Driver: getEnvOr("PASSWORD_HASH_DRIVER", "argon2id"),
+			Argon2Memory: getEnvIntOr("ARGON2_MEMORY", 65536),
+			Argon2Iterations: getEnvIntOr("ARGON2_ITERATIONS", 3),
+			Argon2Parallelism: getEnvIntOr("ARGON2_PARALLELISM", 2),
+			BcryptCost: getEnvIntOr("BCRYPT_COST", 10),
+		},
+		EmailVerification: EmailVerification{
+			TTL: getEnvIntOr("EMAIL_VERIFICATION_TTL", 86400),
+		},
+		MagicLink: MagicLink{
+			TTL: getEnvIntOr("MAGIC_LINK_TTL", 900),
+			MaxRequests: getEnvIntOr("MAGIC_LINK_MAX_REQUESTS", 3),
+			Window: getEnvIntOr("MAGIC_LINK_WINDOW", 3600),
+		},
+		Oidc: Oidc{
+			Providers: getOidcProviders(os.Getenv("OIDC_PROVIDERS")),
+		},
+		Account: Account{
+			DeletionGrace: getEnvIntOr("ACCOUNT_DELETION_GRACE", 604800),
+			PurgeInterval: getEnvIntOr("ACCOUNT_PURGE_INTERVAL", 3600),
+		},
+		Trash: Trash{
+			RetentionDays: getEnvIntOr("TRASH_RETENTION_DAYS", 30),
+			PurgeInterval: getEnvIntOr("TRASH_PURGE_INTERVAL", 3600),
+		},
+		Mail: Mail{
+			Driver: getEnvOr("MAIL_DRIVER", "file"),
+			Host: os.Getenv("MAIL_HOST"),
+			Port: os.Getenv("MAIL_PORT"),
+			Username: os.Getenv("MAIL_USERNAME"),
+			Password: os.Getenv("MAIL_PASSWORD"),
+			From: getEnvOr("MAIL_FROM", "no-reply@localhost"),
+			Dir: getEnvOr("MAIL_DIR", "storage/mail"),
+		},
 		Logrus: Logrus{
 			Level: int32(getEnvInt(os.Getenv("LOG_LEVEL"))),
 		},
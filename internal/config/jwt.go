@@ -0,0 +1,16 @@
+package config
+
+import (
+	"time"
+
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+)
+
+func NewJwt(config *Jwt) *helper.Jwt {
+	return helper.NewJwt(
+		config.AccessKey,
+		config.RefreshKey,
+		time.Second*time.Duration(config.AccessTTL),
+		time.Second*time.Duration(config.RefreshTTL),
+	)
+}
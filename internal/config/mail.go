@@ -0,0 +1,20 @@
+package config
+
+import (
+	"github.com/iyasz/golang-clean-architecture/internal/gateway/mail"
+	"github.com/sirupsen/logrus"
+)
+
+func NewMailer(config *Mail, log *logrus.Logger) mail.Mailer {
+	switch config.Driver {
+	case "smtp":
+		return mail.NewSMTPMailer(config.Host, config.Port, config.Username, config.Password, config.From)
+	case "memory":
+		return mail.NewMemoryMailer()
+	case "file":
+		return mail.NewFileMailer(config.Dir, config.From)
+	default:
+		log.Fatalf("unknown mail driver: %s", config.Driver)
+		return nil
+	}
+}
@@ -1,21 +1,37 @@
 package config
 
+import "net/netip"
+
 type Config struct {
 	App
 	Server
 	Database 
-	// Jwt
+	Jwt
+	Session
+	Login
+	PasswordReset
+	PasswordPolicy
+	PasswordHash
+	EmailVerification
+	Oidc
+	MagicLink
+	Account
+	Trash
+	Mail
 	Logrus
 }
 
 type App struct {
 	Name string
+	Url  string
 }
 
 type Server struct {
 	Host    string
 	Port    string
 	Prefork string
+	// TrustedProxies are the only peers whose X-Forwarded-For and X-Real-IP headers are honored
+	TrustedProxies []netip.Prefix
 }
 type Database struct {
 	Host     string
@@ -36,6 +52,81 @@ type Pool struct {
 type Jwt struct {
 	RefreshKey string
 	AccessKey  string
+	AccessTTL  int
+	RefreshTTL int
+}
+
+type Session struct {
+	MaxAge              int
+	IdleTimeout         int
+	ImpersonationMaxAge int
+}
+
+type Login struct {
+	MaxAttempts      int
+	MaxAttemptsPerIp int
+	Delay            int
+	Lockout          int
+}
+
+type PasswordReset struct {
+	TTL int
+}
+
+type PasswordPolicy struct {
+	MinLength           int
+	MinCharacterClasses int
+	History             int
+}
+
+type PasswordHash struct {
+	Driver            string
+	Argon2Memory      int
+	Argon2Iterations  int
+	Argon2Parallelism int
+	BcryptCost        int
+}
+
+type EmailVerification struct {
+	TTL int
+}
+
+type Oidc struct {
+	Providers []OidcProvider
+}
+
+type OidcProvider struct {
+	Name         string
+	Issuer       string
+	ClientId     string
+	ClientSecret string
+	RedirectUrl  string
+}
+
+type MagicLink struct {
+	TTL         int
+	MaxRequests int
+	Window      int
+}
+
+type Account struct {
+	DeletionGrace int
+	PurgeInterval int
+}
+
+type Trash struct {
+	RetentionDays int
+	PurgeInterval int
+}
+
+type Mail struct {
+	Driver   string
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	Dir      string
 }
 
 type Logrus struct {
@@ -0,0 +1,13 @@
+package config
+
+import (
+	"github.com/iyasz/golang-clean-architecture/internal/gateway/oidc"
+)
+
+func NewOidcProviders(config *Oidc) map[string]*oidc.Provider {
+	providers := make(map[string]*oidc.Provider, len(config.Providers))
+	for _, provider := range config.Providers {
+		providers[provider.Name] = oidc.NewProvider(provider.Name, provider.Issuer, provider.ClientId, provider.ClientSecret, provider.RedirectUrl)
+	}
+	return providers
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func schedule(log *logrus.Logger, name string, interval time.Duration, job func(ctx context.Context) (int, error)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			// a job may fail for some records and still process the others
+			total, err := job(context.Background())
+			if err != nil {
+				log.Warnf("Job %s failed : %+v", name, err)
+			}
+
+			if total > 0 {
+				log.Infof("Job %s processed %d records", name, total)
+			}
+		}
+	}()
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/iyasz/golang-clean-architecture/internal/delivery/http/middleware"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/usecase"
+	"github.com/sirupsen/logrus"
+)
+
+type AccountController struct {
+	Log            *logrus.Logger
+	AccountUseCase *usecase.AccountUseCase
+}
+
+func NewAccountController(log *logrus.Logger, accountUseCase *usecase.AccountUseCase) *AccountController {
+	return &AccountController{
+		Log:            log,
+		AccountUseCase: accountUseCase,
+	}
+}
+
+func (c *AccountController) Delete(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	request := new(model.DeleteUserRequest)
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		helper.ErrorResponse(w, helper.ErrBadRequest)
+		return
+	}
+
+	request.ID = auth.ID
+	request.IpAddress = clientIp(r)
+
+	response, err := c.AccountUseCase.Delete(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to delete user")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[*model.UserResponse]{Data: response}, http.StatusOK)
+}
+
+func (c *AccountController) Restore(w http.ResponseWriter, r *http.Request) {
+	request := new(model.RestoreUserRequest)
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		helper.ErrorResponse(w, helper.ErrBadRequest)
+		return
+	}
+
+	request.IpAddress = clientIp(r)
+
+	response, err := c.AccountUseCase.Restore(r.Context(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to restore user : %+v", err)
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[*model.UserResponse]{Data: response}, http.StatusOK)
+}
@@ -35,6 +35,7 @@ func (c *AddressController) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	request.UserId = auth.ID
+	request.ImpersonatorId = auth.ImpersonatorId
 	request.ContactId = chi.URLParam(r, "contactId")
 
 	response, err := c.AddressUseCase.Create(r.Context(), request)
@@ -54,6 +55,7 @@ func (c *AddressController) List(w http.ResponseWriter, r *http.Request) {
 	request := &model.ListAddressRequest{
 		UserId:    auth.ID,
 		ContactId: contactId,
+		Sort:      sortParam(r),
 	}
 
 	responses, err := c.AddressUseCase.List(r.Context(), request)
@@ -98,6 +100,7 @@ func (c *AddressController) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	request.UserId = auth.ID
+	request.ImpersonatorId = auth.ImpersonatorId
 	request.ContactId = chi.URLParam(r, "contactId")
 	request.ID = chi.URLParam(r, "addressId")
 
@@ -117,9 +120,10 @@ func (c *AddressController) Delete(w http.ResponseWriter, r *http.Request) {
 	addressId := chi.URLParam(r, "addressId")
 
 	request := &model.DeleteAddressRequest{
-		UserId:    auth.ID,
-		ContactId: contactId,
-		ID:        addressId,
+		UserId:         auth.ID,
+		ImpersonatorId: auth.ImpersonatorId,
+		ContactId:      contactId,
+		ID:             addressId,
 	}
 
 	if err := c.AddressUseCase.Delete(r.Context(), request); err != nil {
@@ -130,3 +134,44 @@ func (c *AddressController) Delete(w http.ResponseWriter, r *http.Request) {
 
 	helper.SuccessResponse(w, model.WebResponse[bool]{Data: true}, http.StatusOK)
 }
+
+func (c *AddressController) Trash(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+	contactId := chi.URLParam(r, "contactId")
+
+	request := &model.ListTrashAddressRequest{
+		UserId:    auth.ID,
+		ContactId: contactId,
+	}
+
+	responses, err := c.AddressUseCase.Trash(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to list trashed addresses")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[[]model.AddressResponse]{Data: responses}, http.StatusOK)
+}
+
+func (c *AddressController) Restore(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+	contactId := chi.URLParam(r, "contactId")
+	addressId := chi.URLParam(r, "addressId")
+
+	request := &model.RestoreAddressRequest{
+		UserId:         auth.ID,
+		ImpersonatorId: auth.ImpersonatorId,
+		ContactId:      contactId,
+		ID:             addressId,
+	}
+
+	response, err := c.AddressUseCase.Restore(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to restore address")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[*model.AddressResponse]{Data: response}, http.StatusOK)
+}
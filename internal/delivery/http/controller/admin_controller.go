@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iyasz/golang-clean-architecture/internal/delivery/http/middleware"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/usecase"
+	"github.com/sirupsen/logrus"
+)
+
+type AdminController struct {
+	Log          *logrus.Logger
+	AdminUseCase *usecase.AdminUseCase
+}
+
+func NewAdminController(log *logrus.Logger, adminUseCase *usecase.AdminUseCase) *AdminController {
+	return &AdminController{
+		Log:          log,
+		AdminUseCase: adminUseCase,
+	}
+}
+
+func (c *AdminController) List(w http.ResponseWriter, r *http.Request) {
+	page := 1
+	if p := r.URL.Query().Get("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil {
+			page = parsed
+		}
+	}
+
+	size := 10
+	if s := r.URL.Query().Get("size"); s != "" {
+		if parsed, err := strconv.Atoi(s); err == nil {
+			size = parsed
+		}
+	}
+
+	request := &model.SearchUserRequest{
+		Key:  r.URL.Query().Get("key"),
+		Role: r.URL.Query().Get("role"),
+		Page: page,
+		Size: size,
+	}
+
+	responses, total, err := c.AdminUseCase.Search(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to search users")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	paging := &model.PageMetadata{
+		Page:      request.Page,
+		Size:      request.Size,
+		TotalItem: total,
+		TotalPage: int64(math.Ceil(float64(total) / float64(request.Size))),
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[[]model.AdminUserResponse]{
+		Data:   responses,
+		Paging: paging,
+	}, http.StatusOK)
+}
+
+func (c *AdminController) Get(w http.ResponseWriter, r *http.Request) {
+	request := &model.GetAdminUserRequest{
+		ID: chi.URLParam(r, "userId"),
+	}
+
+	response, err := c.AdminUseCase.Get(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to get user")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[*model.AdminUserResponse]{Data: response}, http.StatusOK)
+}
+
+func (c *AdminController) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	request := new(model.UpdateUserRoleRequest)
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		c.Log.WithError(err).Warnf("Failed to parse request body")
+		helper.ErrorResponse(w, helper.ErrBadRequest)
+		return
+	}
+
+	request.AdminId = auth.ID
+	request.ID = chi.URLParam(r, "userId")
+
+	response, err := c.AdminUseCase.UpdateRole(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to update user role")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[*model.AdminUserResponse]{Data: response}, http.StatusOK)
+}
+
+func (c *AdminController) Disable(w http.ResponseWriter, r *http.Request) {
+	c.setDisabled(w, r, true)
+}
+
+func (c *AdminController) Enable(w http.ResponseWriter, r *http.Request) {
+	c.setDisabled(w, r, false)
+}
+
+func (c *AdminController) setDisabled(w http.ResponseWriter, r *http.Request, disabled bool) {
+	auth := middleware.GetUser(r)
+
+	request := &model.DisableUserRequest{
+		AdminId:  auth.ID,
+		ID:       chi.URLParam(r, "userId"),
+		Disabled: disabled,
+	}
+
+	response, err := c.AdminUseCase.Disable(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to change user disabled state")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[*model.AdminUserResponse]{Data: response}, http.StatusOK)
+}
+
+func (c *AdminController) Logout(w http.ResponseWriter, r *http.Request) {
+	request := &model.LogoutUserSessionsRequest{
+		ID: chi.URLParam(r, "userId"),
+	}
+
+	if err := c.AdminUseCase.Logout(r.Context(), request); err != nil {
+		c.Log.WithError(err).Warnf("Failed to logout user")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[bool]{Data: true}, http.StatusOK)
+}
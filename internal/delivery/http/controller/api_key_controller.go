@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iyasz/golang-clean-architecture/internal/delivery/http/middleware"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/usecase"
+	"github.com/sirupsen/logrus"
+)
+
+type ApiKeyController struct {
+	Log           *logrus.Logger
+	ApiKeyUseCase *usecase.ApiKeyUseCase
+}
+
+func NewApiKeyController(log *logrus.Logger, apiKeyUseCase *usecase.ApiKeyUseCase) *ApiKeyController {
+	return &ApiKeyController{
+		Log:           log,
+		ApiKeyUseCase: apiKeyUseCase,
+	}
+}
+
+func (c *ApiKeyController) Create(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	request := new(model.CreateApiKeyRequest)
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		c.Log.WithError(err).Warnf("Failed to parse request body")
+		helper.ErrorResponse(w, helper.ErrBadRequest)
+		return
+	}
+
+	request.UserId = auth.ID
+
+	response, err := c.ApiKeyUseCase.Create(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to create api key")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[*model.ApiKeyResponse]{Data: response}, http.StatusCreated)
+}
+
+func (c *ApiKeyController) List(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	request := &model.ListApiKeyRequest{
+		UserId: auth.ID,
+	}
+
+	responses, err := c.ApiKeyUseCase.List(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to list api keys")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[[]model.ApiKeyResponse]{Data: responses}, http.StatusOK)
+}
+
+func (c *ApiKeyController) Delete(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	request := &model.DeleteApiKeyRequest{
+		UserId: auth.ID,
+		ID:     chi.URLParam(r, "apiKeyId"),
+	}
+
+	if err := c.ApiKeyUseCase.Delete(r.Context(), request); err != nil {
+		c.Log.WithError(err).Warnf("Failed to delete api key")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[bool]{Data: true}, http.StatusOK)
+}
@@ -2,10 +2,15 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"math"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/emersion/go-vcard"
 	"github.com/go-chi/chi/v5"
 	"github.com/iyasz/golang-clean-architecture/internal/delivery/http/middleware"
 	"github.com/iyasz/golang-clean-architecture/internal/helper"
@@ -14,6 +19,13 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// maxImportSize caps the body of a vCard import, photos make cards large
+const maxImportSize = 5 << 20
+
+// exportWriteTimeout is how long each card may take to reach the client, the deadline moves with
+// every card so a large export is not cut by the server WriteTimeout
+const exportWriteTimeout = 15 * time.Second
+
 type ContactController struct {
 	Log *logrus.Logger
 	ContactUseCase *usecase.ContactUseCase
@@ -37,6 +49,7 @@ func (c *ContactController) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	request.UserId = auth.ID
+	request.ImpersonatorId = auth.ImpersonatorId
 
 	response, err := c.ContactUseCase.Create(r.Context(), request)
 	if err != nil {
@@ -51,9 +64,12 @@ func (c *ContactController) Create(w http.ResponseWriter, r *http.Request) {
 func (c *ContactController) List(w http.ResponseWriter, r *http.Request) {
 	auth := middleware.GetUser(r)
 
+	q := r.URL.Query().Get("q")
+	fuzzy, _ := strconv.ParseBool(r.URL.Query().Get("fuzzy"))
 	name := r.URL.Query().Get("name")
 	email := r.URL.Query().Get("email")
 	phone := r.URL.Query().Get("phone")
+	group := r.URL.Query().Get("group")
 
 	page := 1
 	if p := r.URL.Query().Get("page"); p != "" {
@@ -71,13 +87,25 @@ func (c *ContactController) List(w http.ResponseWriter, r *http.Request) {
 
 	request := &model.SearchContactRequest{
 		UserId: auth.ID,
+		Query:  q,
+		Fuzzy:  fuzzy,
 		Name:   name,
 		Email: 	email,
 		Phone:  phone,
+		Group:  group,
+		Sort:   sortParam(r),
 		Page:   page,
 		Size:   size,
 	}
 
+	// cursor pagination is opt in, a cursor from an earlier page implies it
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" || r.URL.Query().Get("pagination") == "cursor" {
+		request.Cursor = cursor
+		request.SkipCount, _ = strconv.ParseBool(r.URL.Query().Get("skip_count"))
+		c.listCursor(w, r, request)
+		return
+	}
+
 	responses, total, err := c.ContactUseCase.Search(r.Context(), request)
 	if err != nil {
 		c.Log.WithError(err).Error("error searching contact")
@@ -99,6 +127,20 @@ func (c *ContactController) List(w http.ResponseWriter, r *http.Request) {
 
 }
 
+func (c *ContactController) listCursor(w http.ResponseWriter, r *http.Request, request *model.SearchContactRequest) {
+	responses, paging, err := c.ContactUseCase.SearchCursor(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("error searching contact")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[[]model.ContactResponse]{
+		Data:   responses,
+		Paging: paging,
+	}, http.StatusOK)
+}
+
 func (c *ContactController) Get(w http.ResponseWriter, r *http.Request) {
 	auth := middleware.GetUser(r)
 
@@ -128,6 +170,7 @@ func (c *ContactController) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	request.UserId = auth.ID
+	request.ImpersonatorId = auth.ImpersonatorId
 	request.ID = chi.URLParam(r, "contactId")
 
 	response, err := c.ContactUseCase.Update(r.Context(), request)
@@ -145,8 +188,9 @@ func (c *ContactController) Delete(w http.ResponseWriter, r *http.Request) {
 	contactId := chi.URLParam(r, "contactId")
 
 	request := &model.DeleteContactRequest{
-		UserId: auth.ID,
-		ID:     contactId,
+		UserId:         auth.ID,
+		ImpersonatorId: auth.ImpersonatorId,
+		ID:             contactId,
 	}
 
 	if err := c.ContactUseCase.Delete(r.Context(), request); err != nil {
@@ -157,3 +201,252 @@ func (c *ContactController) Delete(w http.ResponseWriter, r *http.Request) {
 
 	helper.SuccessResponse(w, model.WebResponse[bool]{Data: true}, http.StatusOK)
 }
+
+func (c *ContactController) Trash(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	page := 1
+	if p := r.URL.Query().Get("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil {
+			page = parsed
+		}
+	}
+
+	size := 10
+	if s := r.URL.Query().Get("size"); s != "" {
+		if parsed, err := strconv.Atoi(s); err == nil {
+			size = parsed
+		}
+	}
+
+	request := &model.ListTrashContactRequest{
+		UserId: auth.ID,
+		Page:   page,
+		Size:   size,
+	}
+
+	responses, total, err := c.ContactUseCase.Trash(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("error listing trashed contacts")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	paging := &model.PageMetadata{
+		Page:      request.Page,
+		Size:      request.Size,
+		TotalItem: total,
+		TotalPage: int64(math.Ceil(float64(total) / float64(request.Size))),
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[[]model.ContactResponse]{
+		Data:   responses,
+		Paging: paging,
+	}, http.StatusOK)
+}
+
+func (c *ContactController) Restore(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+	contactId := chi.URLParam(r, "contactId")
+
+	request := &model.RestoreContactRequest{
+		UserId:         auth.ID,
+		ImpersonatorId: auth.ImpersonatorId,
+		ID:             contactId,
+	}
+
+	response, err := c.ContactUseCase.Restore(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("error restoring contact")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[*model.ContactResponse]{Data: response}, http.StatusOK)
+}
+
+func (c *ContactController) Duplicates(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	page := 1
+	if p := r.URL.Query().Get("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil {
+			page = parsed
+		}
+	}
+
+	size := 10
+	if s := r.URL.Query().Get("size"); s != "" {
+		if parsed, err := strconv.Atoi(s); err == nil {
+			size = parsed
+		}
+	}
+
+	request := &model.ListDuplicateRequest{
+		UserId: auth.ID,
+		Page:   page,
+		Size:   size,
+	}
+
+	responses, total, err := c.ContactUseCase.Duplicates(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("error listing duplicate contacts")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	paging := &model.PageMetadata{
+		Page:      request.Page,
+		Size:      request.Size,
+		TotalItem: total,
+		TotalPage: int64(math.Ceil(float64(total) / float64(request.Size))),
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[[]model.DuplicateResponse]{
+		Data:   responses,
+		Paging: paging,
+	}, http.StatusOK)
+}
+
+func (c *ContactController) Merge(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	request := new(model.MergeContactRequest)
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		c.Log.WithError(err).Error("error parsing request body")
+		helper.ErrorResponse(w, helper.ErrBadRequest)
+		return
+	}
+	request.UserId = auth.ID
+	request.ImpersonatorId = auth.ImpersonatorId
+
+	response, err := c.ContactUseCase.Merge(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("error merging contacts")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[*model.ContactResponse]{Data: response}, http.StatusOK)
+}
+
+func (c *ContactController) Export(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+	fuzzy, _ := strconv.ParseBool(r.URL.Query().Get("fuzzy"))
+
+	request := &model.ExportContactRequest{
+		SearchContactRequest: model.SearchContactRequest{
+			UserId: auth.ID,
+			Query:  r.URL.Query().Get("q"),
+			Fuzzy:  fuzzy,
+			Name:   r.URL.Query().Get("name"),
+			Email:  r.URL.Query().Get("email"),
+			Phone:  r.URL.Query().Get("phone"),
+			Group:  r.URL.Query().Get("group"),
+			Sort:   sortParam(r),
+		},
+		Version:       vcardVersion(r),
+		WithAddresses: auth.HasScope(model.ScopeAddressesRead),
+	}
+
+	c.writeVCards(w, r, request, "contacts.vcf")
+}
+
+func (c *ContactController) ExportOne(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+	contactId := chi.URLParam(r, "contactId")
+
+	request := &model.ExportContactRequest{
+		SearchContactRequest: model.SearchContactRequest{UserId: auth.ID},
+		ID:                   contactId,
+		Version:              vcardVersion(r),
+		WithAddresses:        auth.HasScope(model.ScopeAddressesRead),
+	}
+
+	c.writeVCards(w, r, request, contactId+".vcf")
+}
+
+// writeVCards streams the cards, the status is only sent with the first one so an error
+// found before that still gets an error response
+func (c *ContactController) writeVCards(w http.ResponseWriter, r *http.Request, request *model.ExportContactRequest, filename string) {
+	started := false
+	start := func() {
+		w.Header().Set("Content-Type", "text/vcard; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		w.WriteHeader(http.StatusOK)
+		started = true
+	}
+
+	encoder := vcard.NewEncoder(w)
+	controller := http.NewResponseController(w)
+	write := func(card vcard.Card) error {
+		if err := controller.SetWriteDeadline(time.Now().Add(exportWriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		if !started {
+			start()
+		}
+		return encoder.Encode(card)
+	}
+
+	if err := c.ContactUseCase.Export(r.Context(), request, write); err != nil {
+		c.Log.WithError(err).Error("error exporting contacts")
+		if !started {
+			helper.ErrorResponse(w, err)
+		}
+		return
+	}
+
+	if !started {
+		start()
+	}
+}
+
+// Import reads the cards from the request body as is, a .vcf file can be posted without a form
+func (c *ContactController) Import(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportSize))
+	if err != nil {
+		c.Log.WithError(err).Error("error reading request body")
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			helper.ErrorResponse(w, helper.ErrRequestEntityTooLarge)
+			return
+		}
+		helper.ErrorResponse(w, helper.ErrBadRequest)
+		return
+	}
+
+	request := &model.ImportContactRequest{
+		UserId:         auth.ID,
+		ImpersonatorId: auth.ImpersonatorId,
+		VCard:          string(body),
+		WithAddresses:  auth.HasScope(model.ScopeAddressesWrite),
+	}
+
+	response, err := c.ContactUseCase.Import(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("error importing contacts")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[*model.ImportContactResponse]{Data: response}, http.StatusOK)
+}
+
+// vcardVersion is the version query parameter, 3.0 reads everywhere so it is the default
+func vcardVersion(r *http.Request) string {
+	if version := r.URL.Query().Get("version"); version != "" {
+		return version
+	}
+	return "3.0"
+}
+
+func sortParam(r *http.Request) []string {
+	sort := r.URL.Query().Get("sort")
+	if sort == "" {
+		return nil
+	}
+	return strings.Split(sort, ",")
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iyasz/golang-clean-architecture/internal/delivery/http/middleware"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/usecase"
+	"github.com/sirupsen/logrus"
+)
+
+type ContactRevisionController struct {
+	Log                    *logrus.Logger
+	ContactRevisionUseCase *usecase.ContactRevisionUseCase
+}
+
+func NewContactRevisionController(log *logrus.Logger, contactRevisionUseCase *usecase.ContactRevisionUseCase) *ContactRevisionController {
+	return &ContactRevisionController{
+		Log:                    log,
+		ContactRevisionUseCase: contactRevisionUseCase,
+	}
+}
+
+func (c *ContactRevisionController) List(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	page := 1
+	if p := r.URL.Query().Get("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil {
+			page = parsed
+		}
+	}
+
+	size := 10
+	if s := r.URL.Query().Get("size"); s != "" {
+		if parsed, err := strconv.Atoi(s); err == nil {
+			size = parsed
+		}
+	}
+
+	request := &model.ListRevisionRequest{
+		UserId:    auth.ID,
+		ContactId: chi.URLParam(r, "contactId"),
+		Page:      page,
+		Size:      size,
+	}
+
+	responses, total, err := c.ContactRevisionUseCase.List(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to list revisions")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	paging := &model.PageMetadata{
+		Page:      request.Page,
+		Size:      request.Size,
+		TotalItem: total,
+		TotalPage: int64(math.Ceil(float64(total) / float64(request.Size))),
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[[]model.RevisionResponse]{
+		Data:   responses,
+		Paging: paging,
+	}, http.StatusOK)
+}
+
+func (c *ContactRevisionController) Revert(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	request := &model.RevertRevisionRequest{
+		UserId:         auth.ID,
+		ImpersonatorId: auth.ImpersonatorId,
+		ContactId:      chi.URLParam(r, "contactId"),
+		ID:             chi.URLParam(r, "revisionId"),
+		AllowAddresses: auth.HasScope(model.ScopeAddressesWrite),
+	}
+
+	response, err := c.ContactRevisionUseCase.Revert(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to revert revision")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[*model.RevisionResponse]{Data: response}, http.StatusOK)
+}
@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/iyasz/golang-clean-architecture/internal/delivery/http/middleware"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/usecase"
+	"github.com/sirupsen/logrus"
+)
+
+type EmailVerificationController struct {
+	Log                      *logrus.Logger
+	EmailVerificationUseCase *usecase.EmailVerificationUseCase
+}
+
+func NewEmailVerificationController(log *logrus.Logger, emailVerificationUseCase *usecase.EmailVerificationUseCase) *EmailVerificationController {
+	return &EmailVerificationController{
+		Log:                      log,
+		EmailVerificationUseCase: emailVerificationUseCase,
+	}
+}
+
+func (c *EmailVerificationController) Send(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	request := &model.SendEmailVerificationRequest{UserId: auth.ID}
+
+	response, err := c.EmailVerificationUseCase.Send(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to send email verification")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[bool]{Data: response}, http.StatusOK)
+}
+
+func (c *EmailVerificationController) Verify(w http.ResponseWriter, r *http.Request) {
+	request := new(model.VerifyEmailRequest)
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		helper.ErrorResponse(w, helper.ErrBadRequest)
+		return
+	}
+
+	response, err := c.EmailVerificationUseCase.Verify(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to verify email")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[*model.UserResponse]{Data: response}, http.StatusOK)
+}
@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iyasz/golang-clean-architecture/internal/delivery/http/middleware"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/usecase"
+	"github.com/sirupsen/logrus"
+)
+
+type GroupController struct {
+	Log          *logrus.Logger
+	GroupUseCase *usecase.GroupUseCase
+}
+
+func NewGroupController(log *logrus.Logger, groupUseCase *usecase.GroupUseCase) *GroupController {
+	return &GroupController{
+		Log:          log,
+		GroupUseCase: groupUseCase,
+	}
+}
+
+func (c *GroupController) Create(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	request := new(model.CreateGroupRequest)
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		c.Log.WithError(err).Error("failed to parse request body")
+		helper.ErrorResponse(w, helper.ErrBadRequest)
+		return
+	}
+	request.UserId = auth.ID
+
+	response, err := c.GroupUseCase.Create(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to create group")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[*model.GroupResponse]{Data: response}, http.StatusCreated)
+}
+
+func (c *GroupController) List(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	request := &model.ListGroupRequest{
+		UserId: auth.ID,
+	}
+
+	responses, err := c.GroupUseCase.List(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to list groups")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[[]model.GroupResponse]{Data: responses}, http.StatusOK)
+}
+
+func (c *GroupController) Get(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	request := &model.GetGroupRequest{
+		UserId: auth.ID,
+		ID:     chi.URLParam(r, "groupId"),
+	}
+
+	response, err := c.GroupUseCase.Get(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to get group")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[*model.GroupResponse]{Data: response}, http.StatusOK)
+}
+
+func (c *GroupController) Update(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	request := new(model.UpdateGroupRequest)
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		c.Log.WithError(err).Error("failed to parse request body")
+		helper.ErrorResponse(w, helper.ErrBadRequest)
+		return
+	}
+
+	request.UserId = auth.ID
+	request.ID = chi.URLParam(r, "groupId")
+
+	response, err := c.GroupUseCase.Update(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to update group")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[*model.GroupResponse]{Data: response}, http.StatusOK)
+}
+
+func (c *GroupController) Delete(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	request := &model.DeleteGroupRequest{
+		UserId: auth.ID,
+		ID:     chi.URLParam(r, "groupId"),
+	}
+
+	if err := c.GroupUseCase.Delete(r.Context(), request); err != nil {
+		c.Log.WithError(err).Error("failed to delete group")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[bool]{Data: true}, http.StatusOK)
+}
+
+func (c *GroupController) AddMembers(w http.ResponseWriter, r *http.Request) {
+	c.updateMembers(w, r, c.GroupUseCase.AddMembers)
+}
+
+func (c *GroupController) RemoveMembers(w http.ResponseWriter, r *http.Request) {
+	c.updateMembers(w, r, c.GroupUseCase.RemoveMembers)
+}
+
+func (c *GroupController) updateMembers(w http.ResponseWriter, r *http.Request,
+	apply func(ctx context.Context, request *model.GroupMembersRequest) (*model.GroupResponse, error)) {
+	auth := middleware.GetUser(r)
+
+	request := new(model.GroupMembersRequest)
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		c.Log.WithError(err).Error("failed to parse request body")
+		helper.ErrorResponse(w, helper.ErrBadRequest)
+		return
+	}
+
+	request.UserId = auth.ID
+	request.ID = chi.URLParam(r, "groupId")
+
+	response, err := apply(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to update group members")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[*model.GroupResponse]{Data: response}, http.StatusOK)
+}
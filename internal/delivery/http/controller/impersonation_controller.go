@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iyasz/golang-clean-architecture/internal/delivery/http/middleware"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/usecase"
+	"github.com/sirupsen/logrus"
+)
+
+type ImpersonationController struct {
+	Log                  *logrus.Logger
+	ImpersonationUseCase *usecase.ImpersonationUseCase
+}
+
+func NewImpersonationController(log *logrus.Logger, impersonationUseCase *usecase.ImpersonationUseCase) *ImpersonationController {
+	return &ImpersonationController{
+		Log:                  log,
+		ImpersonationUseCase: impersonationUseCase,
+	}
+}
+
+func (c *ImpersonationController) Start(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	request := new(model.ImpersonateUserRequest)
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		c.Log.WithError(err).Warnf("Failed to parse request body")
+		helper.ErrorResponse(w, helper.ErrBadRequest)
+		return
+	}
+
+	request.AdminId = auth.ID
+	request.ID = chi.URLParam(r, "userId")
+	request.UserAgent = userAgent(r)
+	request.IpAddress = clientIp(r)
+
+	response, err := c.ImpersonationUseCase.Start(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to impersonate user")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[*model.TokenResponse]{Data: response}, http.StatusOK)
+}
+
+func (c *ImpersonationController) List(w http.ResponseWriter, r *http.Request) {
+	page := 1
+	if p := r.URL.Query().Get("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil {
+			page = parsed
+		}
+	}
+
+	size := 10
+	if s := r.URL.Query().Get("size"); s != "" {
+		if parsed, err := strconv.Atoi(s); err == nil {
+			size = parsed
+		}
+	}
+
+	request := &model.SearchAuditLogRequest{
+		ImpersonatorId: r.URL.Query().Get("impersonator_id"),
+		UserId:         r.URL.Query().Get("user_id"),
+		SessionId:      r.URL.Query().Get("session_id"),
+		Page:           page,
+		Size:           size,
+	}
+
+	responses, total, err := c.ImpersonationUseCase.Search(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to search audit logs")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	paging := &model.PageMetadata{
+		Page:      request.Page,
+		Size:      request.Size,
+		TotalItem: total,
+		TotalPage: int64(math.Ceil(float64(total) / float64(request.Size))),
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[[]model.AuditLogResponse]{
+		Data:   responses,
+		Paging: paging,
+	}, http.StatusOK)
+}
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/usecase"
+	"github.com/sirupsen/logrus"
+)
+
+type MagicLinkController struct {
+	Log              *logrus.Logger
+	MagicLinkUseCase *usecase.MagicLinkUseCase
+}
+
+func NewMagicLinkController(log *logrus.Logger, magicLinkUseCase *usecase.MagicLinkUseCase) *MagicLinkController {
+	return &MagicLinkController{
+		Log:              log,
+		MagicLinkUseCase: magicLinkUseCase,
+	}
+}
+
+func (c *MagicLinkController) Send(w http.ResponseWriter, r *http.Request) {
+	request := new(model.MagicLinkRequest)
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		helper.ErrorResponse(w, helper.ErrBadRequest)
+		return
+	}
+
+	response, err := c.MagicLinkUseCase.Send(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to request magic link")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[bool]{Data: response}, http.StatusOK)
+}
+
+func (c *MagicLinkController) Login(w http.ResponseWriter, r *http.Request) {
+	request := new(model.LoginMagicLinkRequest)
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		helper.ErrorResponse(w, helper.ErrBadRequest)
+		return
+	}
+
+	request.UserAgent = userAgent(r)
+	request.IpAddress = clientIp(r)
+
+	response, err := c.MagicLinkUseCase.Login(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to login with magic link")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[*model.TokenResponse]{Data: response}, http.StatusOK)
+}
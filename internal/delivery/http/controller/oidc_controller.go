@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iyasz/golang-clean-architecture/internal/delivery/http/middleware"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/usecase"
+	"github.com/sirupsen/logrus"
+)
+
+const oidcStateCookie = "oidc_state"
+
+type OidcController struct {
+	Log         *logrus.Logger
+	OidcUseCase *usecase.OidcUseCase
+}
+
+func NewOidcController(log *logrus.Logger, oidcUseCase *usecase.OidcUseCase) *OidcController {
+	return &OidcController{
+		Log:         log,
+		OidcUseCase: oidcUseCase,
+	}
+}
+
+func (c *OidcController) Start(w http.ResponseWriter, r *http.Request) {
+	request := &model.OidcStartRequest{
+		Provider: chi.URLParam(r, "provider"),
+	}
+
+	response, err := c.OidcUseCase.Start(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to start oidc login")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	setOidcStateCookie(w, r, response.Binding)
+
+	helper.SuccessResponse(w, model.WebResponse[*model.OidcAuthorizationResponse]{Data: response}, http.StatusOK)
+}
+
+func (c *OidcController) Callback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	request := &model.OidcCallbackRequest{
+		Provider:  chi.URLParam(r, "provider"),
+		Code:      query.Get("code"),
+		State:     query.Get("state"),
+		UserAgent: userAgent(r),
+		IpAddress: clientIp(r),
+	}
+
+	if cookie, err := r.Cookie(oidcStateCookie); err == nil {
+		request.Binding = cookie.Value
+	}
+
+	// only a login of the user itself can complete a link, not an api key nor an admin
+	if auth := middleware.FindUser(r); auth != nil && auth.ApiKeyId == "" && auth.ImpersonatorId == "" {
+		request.UserId = auth.ID
+	}
+
+	// the state is redeemed whatever the outcome
+	setOidcStateCookie(w, r, "")
+
+	response, err := c.OidcUseCase.Callback(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to complete oidc login")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[*model.TokenResponse]{Data: response}, http.StatusOK)
+}
+
+func (c *OidcController) Link(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	request := new(model.OidcStartRequest)
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		c.Log.WithError(err).Warnf("Failed to parse request body")
+		helper.ErrorResponse(w, helper.ErrBadRequest)
+		return
+	}
+
+	request.UserId = auth.ID
+
+	response, err := c.OidcUseCase.Start(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to start identity link")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	setOidcStateCookie(w, r, response.Binding)
+
+	helper.SuccessResponse(w, model.WebResponse[*model.OidcAuthorizationResponse]{Data: response}, http.StatusOK)
+}
+
+func (c *OidcController) List(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	request := &model.ListUserIdentityRequest{
+		UserId: auth.ID,
+	}
+
+	responses, err := c.OidcUseCase.List(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to list user identities")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[[]model.UserIdentityResponse]{Data: responses}, http.StatusOK)
+}
+
+func (c *OidcController) Delete(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	request := &model.DeleteUserIdentityRequest{
+		UserId: auth.ID,
+		ID:     chi.URLParam(r, "identityId"),
+	}
+
+	if err := c.OidcUseCase.Delete(r.Context(), request); err != nil {
+		c.Log.WithError(err).Warnf("Failed to delete user identity")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[bool]{Data: true}, http.StatusOK)
+}
+
+func setOidcStateCookie(w http.ResponseWriter, r *http.Request, value string) {
+	cookie := &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    value,
+		Path:     "/api/auth/oidc/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		// the callback is a top level redirect from the provider
+		SameSite: http.SameSiteLaxMode,
+	}
+	if value == "" {
+		cookie.MaxAge = -1
+	}
+
+	http.SetCookie(w, cookie)
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/usecase"
+	"github.com/sirupsen/logrus"
+)
+
+type PasswordResetController struct {
+	Log                  *logrus.Logger
+	PasswordResetUseCase *usecase.PasswordResetUseCase
+}
+
+func NewPasswordResetController(log *logrus.Logger, passwordResetUseCase *usecase.PasswordResetUseCase) *PasswordResetController {
+	return &PasswordResetController{
+		Log:                  log,
+		PasswordResetUseCase: passwordResetUseCase,
+	}
+}
+
+func (c *PasswordResetController) Forgot(w http.ResponseWriter, r *http.Request) {
+	request := new(model.ForgotPasswordRequest)
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		helper.ErrorResponse(w, helper.ErrBadRequest)
+		return
+	}
+
+	response, err := c.PasswordResetUseCase.Forgot(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to request password reset")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[bool]{Data: response}, http.StatusOK)
+}
+
+func (c *PasswordResetController) Reset(w http.ResponseWriter, r *http.Request) {
+	request := new(model.ResetPasswordRequest)
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		helper.ErrorResponse(w, helper.ErrBadRequest)
+		return
+	}
+
+	response, err := c.PasswordResetUseCase.Reset(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to reset password")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[bool]{Data: response}, http.StatusOK)
+}
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iyasz/golang-clean-architecture/internal/delivery/http/middleware"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/usecase"
+	"github.com/sirupsen/logrus"
+)
+
+type SessionController struct {
+	Log            *logrus.Logger
+	SessionUseCase *usecase.SessionUseCase
+}
+
+func NewSessionController(log *logrus.Logger, sessionUseCase *usecase.SessionUseCase) *SessionController {
+	return &SessionController{
+		Log:            log,
+		SessionUseCase: sessionUseCase,
+	}
+}
+
+func (c *SessionController) List(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	request := &model.ListSessionRequest{
+		UserId:           auth.ID,
+		CurrentSessionId: auth.SessionId,
+	}
+
+	responses, err := c.SessionUseCase.List(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to list sessions")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[[]model.SessionResponse]{Data: responses}, http.StatusOK)
+}
+
+func (c *SessionController) Revoke(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	request := &model.RevokeSessionRequest{
+		UserId: auth.ID,
+		ID:     chi.URLParam(r, "sessionId"),
+	}
+
+	if err := c.SessionUseCase.Revoke(r.Context(), request); err != nil {
+		c.Log.WithError(err).Warnf("Failed to revoke session")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[bool]{Data: true}, http.StatusOK)
+}
+
+func (c *SessionController) RevokeAll(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	request := &model.RevokeAllSessionRequest{
+		UserId: auth.ID,
+	}
+
+	if err := c.SessionUseCase.RevokeAll(r.Context(), request); err != nil {
+		c.Log.WithError(err).Warnf("Failed to revoke sessions")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[bool]{Data: true}, http.StatusOK)
+}
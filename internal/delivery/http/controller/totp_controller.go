@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/iyasz/golang-clean-architecture/internal/delivery/http/middleware"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/usecase"
+	"github.com/sirupsen/logrus"
+)
+
+type TotpController struct {
+	Log         *logrus.Logger
+	TotpUseCase *usecase.TotpUseCase
+}
+
+func NewTotpController(log *logrus.Logger, totpUseCase *usecase.TotpUseCase) *TotpController {
+	return &TotpController{
+		Log:         log,
+		TotpUseCase: totpUseCase,
+	}
+}
+
+func (c *TotpController) Enroll(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	request := &model.EnrollTotpRequest{
+		UserId: auth.ID,
+	}
+
+	response, err := c.TotpUseCase.Enroll(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to enroll totp")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[*model.TotpResponse]{Data: response}, http.StatusOK)
+}
+
+func (c *TotpController) Confirm(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	request := new(model.ConfirmTotpRequest)
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		c.Log.WithError(err).Warnf("Failed to parse request body")
+		helper.ErrorResponse(w, helper.ErrBadRequest)
+		return
+	}
+
+	request.UserId = auth.ID
+
+	response, err := c.TotpUseCase.Confirm(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to confirm totp")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[*model.RecoveryCodesResponse]{Data: response}, http.StatusOK)
+}
+
+func (c *TotpController) Disable(w http.ResponseWriter, r *http.Request) {
+	auth := middleware.GetUser(r)
+
+	request := new(model.DisableTotpRequest)
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		c.Log.WithError(err).Warnf("Failed to parse request body")
+		helper.ErrorResponse(w, helper.ErrBadRequest)
+		return
+	}
+
+	request.UserId = auth.ID
+	request.IpAddress = clientIp(r)
+
+	response, err := c.TotpUseCase.Disable(r.Context(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to disable totp")
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[bool]{Data: response}, http.StatusOK)
+}
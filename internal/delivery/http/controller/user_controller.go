@@ -2,6 +2,7 @@ package controller
 
 import (
 	"encoding/json"
+	"net"
 	"net/http"
 
 	"github.com/iyasz/golang-clean-architecture/internal/delivery/http/middleware"
@@ -53,6 +54,9 @@ func (c *UserController) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	request.UserAgent = userAgent(r)
+	request.IpAddress = clientIp(r)
+
 	response, err := c.UserUseCase.Login(ctx, request)
 	if err != nil {
 		c.Log.Warnf("Failed to login user : %+v", err)
@@ -60,7 +64,53 @@ func (c *UserController) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	helper.SuccessResponse(w, model.WebResponse[*model.UserResponse]{Data: response}, http.StatusOK)
+	helper.SuccessResponse(w, model.WebResponse[*model.TokenResponse]{Data: response}, http.StatusOK)
+}
+
+func (c *UserController) LoginTotp(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	request := new(model.LoginTotpRequest)
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		helper.ErrorResponse(w, helper.ErrBadRequest)
+		return
+	}
+
+	request.UserAgent = userAgent(r)
+	request.IpAddress = clientIp(r)
+
+	response, err := c.UserUseCase.LoginTotp(ctx, request)
+	if err != nil {
+		c.Log.Warnf("Failed to login user with second factor : %+v", err)
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[*model.TokenResponse]{Data: response}, http.StatusOK)
+}
+
+func (c *UserController) Refresh(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	request := new(model.RefreshTokenRequest)
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		helper.ErrorResponse(w, helper.ErrBadRequest)
+		return
+	}
+
+	request.UserAgent = userAgent(r)
+	request.IpAddress = clientIp(r)
+
+	response, err := c.UserUseCase.Refresh(ctx, request)
+	if err != nil {
+		c.Log.Warnf("Failed to refresh token : %+v", err)
+		helper.ErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, model.WebResponse[*model.TokenResponse]{Data: response}, http.StatusOK)
 }
 
 func (c *UserController) Current(w http.ResponseWriter, r *http.Request) {
@@ -85,7 +135,8 @@ func (c *UserController) Logout(w http.ResponseWriter, r *http.Request) {
 	auth := middleware.GetUser(r)
 
 	request := &model.LogoutUserRequest{
-		ID: auth.ID,
+		ID:        auth.ID,
+		SessionId: auth.SessionId,
 	}
 
 	response, err := c.UserUseCase.Logout(r.Context(), request)
@@ -119,3 +170,21 @@ func (c *UserController) Update(w http.ResponseWriter, r *http.Request) {
 
 	helper.SuccessResponse(w, model.WebResponse[*model.UserResponse]{Data: response}, http.StatusOK)
 }
+
+// userAgent is cut to the size of sessions.user_agent, it is only kept to tell sessions apart
+func userAgent(r *http.Request) string {
+	ua := []rune(r.UserAgent())
+	if len(ua) > 255 {
+		return string(ua[:255])
+	}
+	return string(ua)
+}
+
+// clientIp returns the address resolved by the RealIP middleware without the port
+func clientIp(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"context"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/iyasz/golang-clean-architecture/internal/helper"
@@ -21,7 +22,7 @@ func extractToken(authHeader string) string {
 	return authHeader
 }
 
-func NewAuth(userUserCase *usecase.UserUseCase) func(http.Handler) http.Handler {
+func NewAuth(userUserCase *usecase.UserUseCase, apiKeyUseCase *usecase.ApiKeyUseCase) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
@@ -39,12 +40,23 @@ func NewAuth(userUserCase *usecase.UserUseCase) func(http.Handler) http.Handler
 				return
 			}
 
-			userUserCase.Log.Debugf("Processing authorization token: %s", token)
-
 			request := &model.VerifyUserRequest{Token: token}
-			auth, err := userUserCase.Verify(r.Context(), request)
+			verify := userUserCase.Verify
+			if helper.IsApiKey(token) {
+				verify = apiKeyUseCase.Verify
+			}
+
+			auth, err := verify(r.Context(), request)
+			if err == helper.ErrTokenExpired {
+				// clients should refresh or login again instead of treating it as a bad token
+				userUserCase.Log.Warnf("Expired token : %+v", err)
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token", error_description="token expired"`)
+				helper.ErrorResponse(w, helper.ErrTokenExpired)
+				return
+			}
+
 			if err != nil {
-				userUserCase.Log.Warnf("Failed find user by token : %+v", err)
+				userUserCase.Log.Warnf("Failed verify token : %+v", err)
 				helper.ErrorResponse(w, helper.ErrUnauthorized)
 				return
 			}
@@ -57,6 +69,64 @@ func NewAuth(userUserCase *usecase.UserUseCase) func(http.Handler) http.Handler
 	}
 }
 
+// OptionalAuth runs auth only for requests that carry credentials, FindUser is nil otherwise
+func OptionalAuth(auth func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		authenticated := auth(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			authenticated.ServeHTTP(w, r)
+		})
+	}
+}
+
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !GetUser(r).HasScope(scope) {
+				helper.ErrorResponse(w, helper.ErrForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !slices.Contains(roles, GetUser(r).Role) {
+				helper.ErrorResponse(w, helper.ErrForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireSession keeps api keys out of account management, whatever their scopes
+func RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if GetUser(r).ApiKeyId != "" {
+			helper.ErrorResponse(w, helper.ErrForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 func GetUser(r *http.Request) *model.Auth {
 	return r.Context().Value(AuthContextKey).(*model.Auth)
+}
+
+func FindUser(r *http.Request) *model.Auth {
+	auth, _ := r.Context().Value(AuthContextKey).(*model.Auth)
+	return auth
 }
\ No newline at end of file
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/usecase"
+)
+
+// NewAudit records every request made while impersonating, it has to run after NewAuth
+func NewAudit(impersonationUseCase *usecase.ImpersonationUseCase) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth := GetUser(r)
+			if !auth.IsImpersonated() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			ip, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				ip = r.RemoteAddr
+			}
+
+			// the query string is up to the client, audit_logs.path only keeps what fits
+			path := r.URL.RequestURI()
+			if len(path) > 2048 {
+				path = path[:2048]
+			}
+
+			request := &model.CreateAuditLogRequest{
+				SessionId:      auth.SessionId,
+				ImpersonatorId: auth.ImpersonatorId,
+				UserId:         auth.ID,
+				Method:         r.Method,
+				Path:           path,
+				Status:         ww.Status(),
+				IpAddress:      ip,
+			}
+
+			// the response is already written, a lost entry can only be logged
+			if err := impersonationUseCase.Record(r.Context(), request); err != nil {
+				impersonationUseCase.Log.WithError(err).Errorf("Failed to record request of impersonator %s", auth.RealId())
+			}
+		})
+	}
+}
+
+func RequireWritable(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if GetUser(r).ReadOnly && !isSafeMethod(r.Method) {
+			helper.ErrorResponse(w, helper.ErrForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RejectImpersonation keeps an impersonating admin away from the credentials of the user
+func RejectImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if GetUser(r).IsImpersonated() {
+			helper.ErrorResponse(w, helper.ErrForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
@@ -3,41 +3,127 @@ package route
 import (
 	"net/http"
 
-	"github.com/iyasz/golang-clean-architecture/internal/delivery/http/controller"
 	"github.com/go-chi/chi/v5"
+	"github.com/iyasz/golang-clean-architecture/internal/delivery/http/controller"
+	"github.com/iyasz/golang-clean-architecture/internal/delivery/http/middleware"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
 )
 
 type RouteConfig struct {
-	App               *chi.Mux
-	UserController    *controller.UserController
-	ContactController *controller.ContactController
-	AddressController *controller.AddressController
-	AuthMiddleware    func(http.Handler) http.Handler
+	App                         *chi.Mux
+	UserController              *controller.UserController
+	AccountController           *controller.AccountController
+	ContactController           *controller.ContactController
+	AddressController           *controller.AddressController
+	SessionController           *controller.SessionController
+	PasswordResetController     *controller.PasswordResetController
+	EmailVerificationController *controller.EmailVerificationController
+	TotpController              *controller.TotpController
+	ApiKeyController            *controller.ApiKeyController
+	AdminController             *controller.AdminController
+	OidcController              *controller.OidcController
+	MagicLinkController         *controller.MagicLinkController
+	ImpersonationController     *controller.ImpersonationController
+	GroupController             *controller.GroupController
+	ContactRevisionController   *controller.ContactRevisionController
+	AuthMiddleware              func(http.Handler) http.Handler
+	AuditMiddleware             func(http.Handler) http.Handler
 }
 
 func (c *RouteConfig) Setup() {
 
-
 	c.App.Post("/api/users", c.UserController.Register)
 	c.App.Post("/api/users/_login", c.UserController.Login)
+	c.App.Post("/api/users/_login/totp", c.UserController.LoginTotp)
+	c.App.Post("/api/users/_refresh", c.UserController.Refresh)
+	c.App.Post("/api/users/_forgot-password", c.PasswordResetController.Forgot)
+	c.App.Post("/api/users/_reset-password", c.PasswordResetController.Reset)
+	c.App.Post("/api/users/_restore", c.AccountController.Restore)
+	c.App.Post("/api/users/_verify-email", c.EmailVerificationController.Verify)
+	c.App.Post("/api/users/_magic-link", c.MagicLinkController.Send)
+	c.App.Post("/api/users/_magic-link/_login", c.MagicLinkController.Login)
+	c.App.Get("/api/auth/oidc/{provider}/start", c.OidcController.Start)
+	// a link is completed with the access token of the user who started it
+	c.App.With(middleware.OptionalAuth(c.AuthMiddleware)).Get("/api/auth/oidc/{provider}/callback", c.OidcController.Callback)
 
 	c.App.Route("/api", func(r chi.Router) {
-		r.Use(c.AuthMiddleware)
-		r.Delete("/users", c.UserController.Logout)
-		r.Patch("/users/_current", c.UserController.Update)
-		r.Get("/users/_current", c.UserController.Current)
-
-		r.Get("/contacts", c.ContactController.List)
-		r.Post("/contacts", c.ContactController.Create)
-		r.Put("/contacts/{contactId}", c.ContactController.Update)
-		r.Get("/contacts/{contactId}", c.ContactController.Get)
-		r.Delete("/contacts/{contactId}", c.ContactController.Delete)
-
-		r.Get("/contacts/{contactId}/addresses", c.AddressController.List)
-		r.Post("/contacts/{contactId}/addresses", c.AddressController.Create)
-		r.Put("/contacts/{contactId}/addresses/{addressId}", c.AddressController.Update)
-		r.Get("/contacts/{contactId}/addresses/{addressId}", c.AddressController.Get)
-		r.Delete("/contacts/{contactId}/addresses/{addressId}", c.AddressController.Delete)
+		r.Use(c.AuthMiddleware, c.AuditMiddleware)
+
+		// account management needs a login, api keys cannot reach it
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireSession)
+			r.Delete("/users", c.UserController.Logout)
+			r.Get("/users/_current", c.UserController.Current)
+			r.Get("/users/_current/sessions", c.SessionController.List)
+			r.Get("/users/_current/api-keys", c.ApiKeyController.List)
+			r.Get("/users/_current/identities", c.OidcController.List)
+
+			// an impersonating admin may look but never change how the user signs in
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RejectImpersonation)
+				r.Patch("/users/_current", c.UserController.Update)
+				r.Delete("/users/_current", c.AccountController.Delete)
+				r.Post("/users/_current/_send-verification", c.EmailVerificationController.Send)
+				r.Delete("/users/_current/sessions", c.SessionController.RevokeAll)
+				r.Delete("/users/_current/sessions/{sessionId}", c.SessionController.Revoke)
+				r.Post("/users/_current/totp", c.TotpController.Enroll)
+				r.Post("/users/_current/totp/_confirm", c.TotpController.Confirm)
+				r.Delete("/users/_current/totp", c.TotpController.Disable)
+				r.Post("/users/_current/api-keys", c.ApiKeyController.Create)
+				r.Delete("/users/_current/api-keys/{apiKeyId}", c.ApiKeyController.Delete)
+				r.Post("/users/_current/identities", c.OidcController.Link)
+				r.Delete("/users/_current/identities/{identityId}", c.OidcController.Delete)
+			})
+		})
+
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(middleware.RequireSession, middleware.RequireRole(model.RoleAdmin))
+			r.Get("/users", c.AdminController.List)
+			r.Get("/users/{userId}", c.AdminController.Get)
+			r.Put("/users/{userId}/role", c.AdminController.UpdateRole)
+			r.Post("/users/{userId}/_disable", c.AdminController.Disable)
+			r.Post("/users/{userId}/_enable", c.AdminController.Enable)
+			r.Delete("/users/{userId}/sessions", c.AdminController.Logout)
+			r.Post("/users/{userId}/_impersonate", c.ImpersonationController.Start)
+			r.Get("/audit-logs", c.ImpersonationController.List)
+		})
+
+		contactsRead := middleware.RequireScope(model.ScopeContactsRead)
+		contactsWrite := middleware.RequireScope(model.ScopeContactsWrite)
+		r.With(contactsRead).Get("/contacts", c.ContactController.List)
+		r.With(contactsWrite, middleware.RequireWritable).Post("/contacts", c.ContactController.Create)
+		r.With(contactsWrite, middleware.RequireWritable).Put("/contacts/{contactId}", c.ContactController.Update)
+		r.With(contactsRead).Get("/contacts/{contactId}", c.ContactController.Get)
+		r.With(contactsWrite, middleware.RequireWritable).Delete("/contacts/{contactId}", c.ContactController.Delete)
+		r.With(contactsRead).Get("/contacts/_trash", c.ContactController.Trash)
+		r.With(contactsRead).Get("/contacts/_export.vcf", c.ContactController.Export)
+		r.With(contactsRead).Get("/contacts/{contactId}.vcf", c.ContactController.ExportOne)
+		r.With(contactsWrite, middleware.RequireWritable).Post("/contacts/_import", c.ContactController.Import)
+		r.With(contactsWrite, middleware.RequireWritable).Post("/contacts/{contactId}/_restore", c.ContactController.Restore)
+		r.With(contactsRead).Get("/contacts/{contactId}/history", c.ContactRevisionController.List)
+		r.With(contactsWrite, middleware.RequireWritable).Post("/contacts/{contactId}/history/{revisionId}/_revert", c.ContactRevisionController.Revert)
+
+		// groups only organise contacts, so they share the contact scopes
+		r.With(contactsRead).Get("/groups", c.GroupController.List)
+		r.With(contactsWrite, middleware.RequireWritable).Post("/groups", c.GroupController.Create)
+		r.With(contactsRead).Get("/groups/{groupId}", c.GroupController.Get)
+		r.With(contactsWrite, middleware.RequireWritable).Put("/groups/{groupId}", c.GroupController.Update)
+		r.With(contactsWrite, middleware.RequireWritable).Delete("/groups/{groupId}", c.GroupController.Delete)
+		r.With(contactsWrite, middleware.RequireWritable).Post("/groups/{groupId}/members", c.GroupController.AddMembers)
+		r.With(contactsWrite, middleware.RequireWritable).Post("/groups/{groupId}/members/_remove", c.GroupController.RemoveMembers)
+
+		addressesRead := middleware.RequireScope(model.ScopeAddressesRead)
+		addressesWrite := middleware.RequireScope(model.ScopeAddressesWrite)
+		r.With(addressesRead).Get("/contacts/{contactId}/addresses", c.AddressController.List)
+		r.With(addressesWrite, middleware.RequireWritable).Post("/contacts/{contactId}/addresses", c.AddressController.Create)
+		r.With(addressesWrite, middleware.RequireWritable).Put("/contacts/{contactId}/addresses/{addressId}", c.AddressController.Update)
+		r.With(addressesRead).Get("/contacts/{contactId}/addresses/{addressId}", c.AddressController.Get)
+		r.With(addressesWrite, middleware.RequireWritable).Delete("/contacts/{contactId}/addresses/{addressId}", c.AddressController.Delete)
+		r.With(addressesRead).Get("/contacts/{contactId}/addresses/_trash", c.AddressController.Trash)
+		r.With(addressesWrite, middleware.RequireWritable).Post("/contacts/{contactId}/addresses/{addressId}/_restore", c.AddressController.Restore)
+
+		r.With(contactsRead).Get("/contacts/_duplicates", c.ContactController.Duplicates)
+		// a merge moves the addresses of the merged contacts, so it needs both write scopes
+		r.With(contactsWrite, addressesWrite, middleware.RequireWritable).Post("/contacts/_merge", c.ContactController.Merge)
 	})
 }
-
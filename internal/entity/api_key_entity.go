@@ -0,0 +1,17 @@
+package entity
+
+type ApiKey struct {
+	ID         string `gorm:"column:id;primaryKey"`
+	UserId     string `gorm:"column:user_id"`
+	Name       string `gorm:"column:name"`
+	Prefix     string `gorm:"column:prefix"`
+	Token      string `gorm:"column:token"`
+	Scopes     string `gorm:"column:scopes"`
+	LastUsedAt int64  `gorm:"column:last_used_at"`
+	CreatedAt  int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	User       User   `gorm:"foreignKey:user_id;references:id"`
+}
+
+func (a *ApiKey) TableName() string {
+	return "api_keys"
+}
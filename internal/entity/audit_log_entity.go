@@ -0,0 +1,18 @@
+package entity
+
+type AuditLog struct {
+	ID             string `gorm:"column:id;primaryKey"`
+	Action         string `gorm:"column:action"`
+	SessionId      string `gorm:"column:session_id"`
+	ImpersonatorId string `gorm:"column:impersonator_id"`
+	UserId         string `gorm:"column:user_id"`
+	Method         string `gorm:"column:method"`
+	Path           string `gorm:"column:path"`
+	Status         int    `gorm:"column:status"`
+	IpAddress      string `gorm:"column:ip_address"`
+	CreatedAt      int64  `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (a *AuditLog) TableName() string {
+	return "audit_logs"
+}
@@ -1,16 +1,19 @@
 package entity
 
+import "gorm.io/plugin/soft_delete"
+
 type Contact struct {
-	ID        string    `gorm:"column:id;primaryKey"`
-	FirstName string    `gorm:"column:first_name"`
-	LastName  string    `gorm:"column:last_name"`
-	Email     string    `gorm:"column:email"`
-	Phone     string    `gorm:"column:phone"`
-	UserId    string    `gorm:"column:user_id"`
-	CreatedAt int64     `gorm:"column:created_at;autoCreateTime:milli"`
-	UpdatedAt int64     `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
-	User      User      `gorm:"foreignKey:user_id;references:id"`
-	Addresses []Address `gorm:"foreignKey:contact_id;references:id"`
+	ID        string                `gorm:"column:id;primaryKey"`
+	FirstName string                `gorm:"column:first_name"`
+	LastName  string                `gorm:"column:last_name"`
+	Email     string                `gorm:"column:email"`
+	Phone     string                `gorm:"column:phone"`
+	UserId    string                `gorm:"column:user_id"`
+	CreatedAt int64                 `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt int64                 `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	User      User                  `gorm:"foreignKey:user_id;references:id"`
+	Addresses []Address             `gorm:"foreignKey:contact_id;references:id"`
+	DeletedAt soft_delete.DeletedAt `gorm:"column:deleted_at;softDelete:milli"`
 }
 
 func (c *Contact) TableName() string {
@@ -0,0 +1,17 @@
+package entity
+
+type ContactRevision struct {
+	ID             string `gorm:"column:id;primaryKey"`
+	ContactId      string `gorm:"column:contact_id"`
+	AddressId      string `gorm:"column:address_id"`
+	UserId         string `gorm:"column:user_id"`
+	ImpersonatorId string `gorm:"column:impersonator_id"`
+	Action         string `gorm:"column:action"`
+	Changes        string `gorm:"column:changes"`
+	Snapshot       string `gorm:"column:snapshot"`
+	CreatedAt      int64  `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (c *ContactRevision) TableName() string {
+	return "contact_revisions"
+}
@@ -0,0 +1,24 @@
+package entity
+
+type Group struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	UserId    string `gorm:"column:user_id"`
+	Name      string `gorm:"column:name"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt int64  `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	User      User   `gorm:"foreignKey:user_id;references:id"`
+}
+
+func (g *Group) TableName() string {
+	return "groups"
+}
+
+type ContactGroup struct {
+	ContactId string `gorm:"column:contact_id;primaryKey"`
+	GroupId   string `gorm:"column:group_id;primaryKey"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (c *ContactGroup) TableName() string {
+	return "contact_groups"
+}
@@ -0,0 +1,15 @@
+package entity
+
+type LoginAttempt struct {
+	ID           string `gorm:"column:id;primaryKey"`
+	Failures     int    `gorm:"column:failures"`
+	RetryAt      int64  `gorm:"column:retry_at"`
+	LockedUntil  int64  `gorm:"column:locked_until"`
+	LastFailedAt int64  `gorm:"column:last_failed_at"`
+	CreatedAt    int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt    int64  `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+}
+
+func (l *LoginAttempt) TableName() string {
+	return "login_attempts"
+}
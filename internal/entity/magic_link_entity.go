@@ -0,0 +1,15 @@
+package entity
+
+type MagicLink struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	UserId    string `gorm:"column:user_id"`
+	Email     string `gorm:"column:email"`
+	Token     string `gorm:"column:token"`
+	ExpiresAt int64  `gorm:"column:expires_at"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	User      User   `gorm:"foreignKey:user_id;references:id"`
+}
+
+func (m *MagicLink) TableName() string {
+	return "magic_links"
+}
@@ -0,0 +1,16 @@
+package entity
+
+type OidcState struct {
+	ID           string `gorm:"column:id;primaryKey"`
+	Provider     string `gorm:"column:provider"`
+	State        string `gorm:"column:state"`
+	Nonce        string `gorm:"column:nonce"`
+	CodeVerifier string `gorm:"column:code_verifier"`
+	UserId       string `gorm:"column:user_id"`
+	ExpiresAt    int64  `gorm:"column:expires_at"`
+	CreatedAt    int64  `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (o *OidcState) TableName() string {
+	return "oidc_states"
+}
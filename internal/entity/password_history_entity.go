@@ -0,0 +1,13 @@
+package entity
+
+type PasswordHistory struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	UserId    string `gorm:"column:user_id"`
+	Password  string `gorm:"column:password"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	User      User   `gorm:"foreignKey:user_id;references:id"`
+}
+
+func (p *PasswordHistory) TableName() string {
+	return "password_histories"
+}
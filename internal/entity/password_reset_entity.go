@@ -0,0 +1,14 @@
+package entity
+
+type PasswordReset struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	UserId    string `gorm:"column:user_id"`
+	Token     string `gorm:"column:token"`
+	ExpiresAt int64  `gorm:"column:expires_at"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	User      User   `gorm:"foreignKey:user_id;references:id"`
+}
+
+func (p *PasswordReset) TableName() string {
+	return "password_resets"
+}
@@ -0,0 +1,13 @@
+package entity
+
+type RecoveryCode struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	UserId    string `gorm:"column:user_id"`
+	Code      string `gorm:"column:code"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	User      User   `gorm:"foreignKey:user_id;references:id"`
+}
+
+func (r *RecoveryCode) TableName() string {
+	return "recovery_codes"
+}
@@ -0,0 +1,20 @@
+package entity
+
+type Session struct {
+	ID         string `gorm:"column:id;primaryKey"`
+	UserId     string `gorm:"column:user_id"`
+	Token      string `gorm:"column:token"`
+	UserAgent  string `gorm:"column:user_agent"`
+	IpAddress  string `gorm:"column:ip_address"`
+	LastSeenAt int64  `gorm:"column:last_seen_at"`
+	CreatedAt  int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt  int64  `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	User       User   `gorm:"foreignKey:user_id;references:id"`
+
+	ImpersonatorId string `gorm:"column:impersonator_id"`
+	ReadOnly       bool   `gorm:"column:read_only"`
+}
+
+func (s *Session) TableName() string {
+	return "sessions"
+}
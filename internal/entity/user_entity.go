@@ -2,13 +2,20 @@ package entity
 
 // User is a struct that represents a user entity
 type User struct {
-	ID        string    `gorm:"column:id;primaryKey"`
-	Password  string    `gorm:"column:password"`
-	Name      string    `gorm:"column:name"`
-	Token     string    `gorm:"column:token"`
-	CreatedAt int64     `gorm:"column:created_at;autoCreateTime:milli"`
-	UpdatedAt int64     `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
-	Contacts  []Contact `gorm:"foreignKey:user_id;references:id"`
+	ID              string    `gorm:"column:id;primaryKey"`
+	Password        string    `gorm:"column:password"`
+	Name            string    `gorm:"column:name"`
+	Email           string    `gorm:"column:email"`
+	EmailVerifiedAt int64     `gorm:"column:email_verified_at"`
+	TotpSecret      string    `gorm:"column:totp_secret"`
+	TotpEnabled     bool      `gorm:"column:totp_enabled"`
+	TotpLastStep    int64     `gorm:"column:totp_last_step"`
+	Role            string    `gorm:"column:role"`
+	Disabled        bool      `gorm:"column:disabled"`
+	DeleteAt        int64     `gorm:"column:delete_at"`
+	CreatedAt       int64     `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt       int64     `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	Contacts        []Contact `gorm:"foreignKey:user_id;references:id"`
 }
 
 func (u *User) TableName() string {
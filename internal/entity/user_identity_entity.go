@@ -0,0 +1,15 @@
+package entity
+
+type UserIdentity struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	UserId    string `gorm:"column:user_id"`
+	Provider  string `gorm:"column:provider"`
+	Subject   string `gorm:"column:subject"`
+	Email     string `gorm:"column:email"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	User      User   `gorm:"foreignKey:user_id;references:id"`
+}
+
+func (u *UserIdentity) TableName() string {
+	return "user_identities"
+}
@@ -0,0 +1,117 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+var ErrInvalidNonce = errors.New("id token nonce does not match")
+
+type Claims struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+}
+
+// Provider runs the authorization code flow with PKCE against one OpenID Connect issuer.
+// Discovery happens on first use so a provider being down does not stop the app from starting
+type Provider struct {
+	Name         string
+	Issuer       string
+	ClientId     string
+	ClientSecret string
+	RedirectUrl  string
+	Scopes       []string
+
+	mutex    sync.Mutex
+	provider *gooidc.Provider
+}
+
+func NewProvider(name string, issuer string, clientId string, clientSecret string, redirectUrl string) *Provider {
+	return &Provider{
+		Name:         name,
+		Issuer:       issuer,
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+		RedirectUrl:  redirectUrl,
+		Scopes:       []string{gooidc.ScopeOpenID, "profile", "email"},
+	}
+}
+
+// AuthCodeURL is where the user agent is sent to authenticate, the verifier stays with us
+// and only its S256 challenge goes to the provider
+func (p *Provider) AuthCodeURL(ctx context.Context, state string, nonce string, verifier string) (string, error) {
+	config, _, err := p.config(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return config.AuthCodeURL(state, gooidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+func (p *Provider) Exchange(ctx context.Context, code string, nonce string, verifier string) (*Claims, error) {
+	config, idTokenVerifier, err := p.config(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("exchange code: %w", err)
+	}
+
+	rawIdToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("token response has no id_token")
+	}
+
+	idToken, err := idTokenVerifier.Verify(ctx, rawIdToken)
+	if err != nil {
+		return nil, fmt.Errorf("verify id token: %w", err)
+	}
+
+	if idToken.Nonce != nonce {
+		return nil, ErrInvalidNonce
+	}
+
+	claims := new(Claims)
+	if err := idToken.Claims(claims); err != nil {
+		return nil, fmt.Errorf("read id token claims: %w", err)
+	}
+
+	return claims, nil
+}
+
+func (p *Provider) config(ctx context.Context) (*oauth2.Config, *gooidc.IDTokenVerifier, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.provider == nil {
+		// the provider keeps this context to fetch signing keys later, it must outlive the request
+		provider, err := gooidc.NewProvider(context.WithoutCancel(ctx), p.Issuer)
+		if err != nil {
+			return nil, nil, fmt.Errorf("discover %s: %w", p.Issuer, err)
+		}
+		p.provider = provider
+	}
+
+	config := &oauth2.Config{
+		ClientID:     p.ClientId,
+		ClientSecret: p.ClientSecret,
+		RedirectURL:  p.RedirectUrl,
+		Endpoint:     p.provider.Endpoint(),
+		Scopes:       p.Scopes,
+	}
+
+	return config, p.provider.Verifier(&gooidc.Config{ClientID: p.ClientId}), nil
+}
+
+func GenerateVerifier() string {
+	return oauth2.GenerateVerifier()
+}
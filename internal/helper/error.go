@@ -3,6 +3,7 @@ package helper
 type Error struct {
 	Code int `json:"code"`
 	Message string `json:"message"`
+	Fields map[string][]string `json:"fields,omitempty"`
 }
 
 func (e *Error) Error() string {
@@ -18,6 +19,14 @@ func NewError(code int) *Error {
 	return err
 }
 
+func NewFieldError(fields map[string][]string) *Error {
+	return &Error{
+		Code:    StatusBadRequest,
+		Message: statusMessage[StatusBadRequest],
+		Fields:  fields,
+	}
+}
+
 const (
 	StatusBadRequest                   = 400 // RFC 9110, 15.5.1
 	StatusUnauthorized                 = 401 // RFC 9110, 15.5.2
@@ -107,6 +116,14 @@ var (
 	ErrNetworkAuthenticationRequired = NewError(StatusNetworkAuthenticationRequired) // 511
 )
 
+// Auth errors, they share a status code with the generic errors above but
+// carry their own message so clients can tell them apart
+var (
+	ErrTokenExpired           = &Error{Code: StatusUnauthorized, Message: "Token Expired"}         // 401
+	ErrAccountPendingDeletion = &Error{Code: StatusForbidden, Message: "Account Pending Deletion"} // 403
+	ErrPasswordNotSet         = &Error{Code: StatusConflict, Message: "Password Not Set"}          // 409
+)
+
 var statusMessage = []string{
 	400: "Bad Request",                     // StatusBadRequest
 	401: "Unauthorized",                    // StatusUnauthorized
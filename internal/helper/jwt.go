@@ -0,0 +1,164 @@
+package helper
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type Jwt struct {
+	AccessKey  []byte
+	RefreshKey []byte
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+var ErrUnexpectedAudience = errors.New("token has an audience")
+
+// challengeAudience marks tokens only good for the second login step
+const challengeAudience = "login-challenge"
+
+// magicLinkAudience marks tokens only good for a passwordless login
+const magicLinkAudience = "magic-link"
+
+type JwtClaims struct {
+	SessionId string `json:"sid"`
+	Role      string `json:"role,omitempty"`
+	// Impersonator is the admin acting as the subject, ReadOnly blocks their writes
+	Impersonator string `json:"imp,omitempty"`
+	ReadOnly     bool   `json:"ro,omitempty"`
+	jwt.RegisteredClaims
+}
+
+func NewJwt(accessKey string, refreshKey string, accessTTL time.Duration, refreshTTL time.Duration) *Jwt {
+	return &Jwt{
+		AccessKey:  []byte(accessKey),
+		RefreshKey: []byte(refreshKey),
+		AccessTTL:  accessTTL,
+		RefreshTTL: refreshTTL,
+	}
+}
+
+type AccessToken struct {
+	UserId       string
+	SessionId    string
+	Role         string
+	Impersonator string
+	ReadOnly     bool
+	// NotAfter caps the expiry below the access ttl when set
+	NotAfter time.Time
+}
+
+func (j *Jwt) GenerateAccessToken(token *AccessToken) (string, int64, error) {
+	expiresAt := time.Now().Add(j.AccessTTL)
+	if !token.NotAfter.IsZero() {
+		expiresAt = Earliest(expiresAt, token.NotAfter)
+	}
+
+	signed, err := j.sign(j.AccessKey, &JwtClaims{
+		SessionId:    token.SessionId,
+		Role:         token.Role,
+		Impersonator: token.Impersonator,
+		ReadOnly:     token.ReadOnly,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   token.UserId,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+
+	return signed, expiresAt.UnixMilli(), err
+}
+
+func (j *Jwt) GenerateRefreshToken(userId string, sessionId string, tokenId string, notAfter time.Time) (string, error) {
+	return j.sign(j.RefreshKey, &JwtClaims{
+		SessionId: sessionId,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenId,
+			Subject:   userId,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(Earliest(time.Now().Add(j.RefreshTTL), notAfter)),
+		},
+	})
+}
+
+// GenerateChallengeToken signs a short-lived token proving the password step of a login
+// succeeded, it is exchanged together with a second factor for a session
+func (j *Jwt) GenerateChallengeToken(userId string, ttl time.Duration) (string, error) {
+	return j.sign(j.RefreshKey, &JwtClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userId,
+			Audience:  jwt.ClaimStrings{challengeAudience},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		},
+	})
+}
+
+func (j *Jwt) GenerateMagicLinkToken(userId string, tokenId string, ttl time.Duration) (string, error) {
+	return j.sign(j.RefreshKey, &JwtClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenId,
+			Subject:   userId,
+			Audience:  jwt.ClaimStrings{magicLinkAudience},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		},
+	})
+}
+
+func (j *Jwt) ParseAccessToken(token string) (*JwtClaims, error) {
+	return j.parse(j.AccessKey, token)
+}
+
+// ParseRefreshToken refuses challenge and magic link tokens, they share the key but carry an audience
+func (j *Jwt) ParseRefreshToken(token string) (*JwtClaims, error) {
+	claims, err := j.parse(j.RefreshKey, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(claims.Audience) > 0 {
+		return nil, ErrUnexpectedAudience
+	}
+
+	return claims, nil
+}
+
+func (j *Jwt) ParseChallengeToken(token string) (*JwtClaims, error) {
+	return j.parse(j.RefreshKey, token, jwt.WithAudience(challengeAudience))
+}
+
+func (j *Jwt) ParseMagicLinkToken(token string) (*JwtClaims, error) {
+	return j.parse(j.RefreshKey, token, jwt.WithAudience(magicLinkAudience))
+}
+
+func (j *Jwt) sign(key []byte, claims *JwtClaims) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+}
+
+func (j *Jwt) parse(key []byte, token string, options ...jwt.ParserOption) (*JwtClaims, error) {
+	options = append(options, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+
+	claims := new(JwtClaims)
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return key, nil
+	}, options...)
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, ErrTokenExpired
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func Earliest(a time.Time, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
@@ -21,17 +21,23 @@ func SuccessResponse(w http.ResponseWriter, data interface{}, statusCode int) {
 
 func ErrorResponse(w http.ResponseWriter, err error) {
 	code := http.StatusInternalServerError
+	var fields map[string][]string
 	
 	if e, ok := err.(*Error); ok {
 		code = e.Code
+		fields = e.Fields
 	}
 	
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
 	
-	response := map[string]string{
+	response := map[string]any{
 		"error": err.Error(),
 	}
+
+	if len(fields) > 0 {
+		response["fields"] = fields
+	}
 	
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		logrus.Warnf("Failed to encode response: %+v", err)
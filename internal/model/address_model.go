@@ -9,32 +9,37 @@ type AddressResponse struct {
 	Country    string `json:"country"`
 	CreatedAt  int64  `json:"created_at"`
 	UpdatedAt  int64  `json:"updated_at"`
+	DeletedAt  int64  `json:"deleted_at,omitempty"`
 }
 
 type ListAddressRequest struct {
 	UserId    string `json:"-" validate:"required"`
 	ContactId string `json:"-" validate:"required,max=100,uuid"`
+	// Sort fields, a leading - sorts descending
+	Sort []string `json:"sort" validate:"max=6,dive,oneof=street -street city -city province -province postal_code -postal_code country -country created_at -created_at updated_at -updated_at"`
 }
 
 type CreateAddressRequest struct {
-	UserId     string `json:"-" validate:"required"`
-	ContactId  string `json:"-" validate:"required,max=100,uuid"`
-	Street     string `json:"street" validate:"max=255"`
-	City       string `json:"city" validate:"max=255"`
-	Province   string `json:"province" validate:"max=255"`
-	PostalCode string `json:"postal_code" validate:"max=10"`
-	Country    string `json:"country" validate:"max=100"`
+	UserId         string `json:"-" validate:"required"`
+	ImpersonatorId string `json:"-"`
+	ContactId      string `json:"-" validate:"required,max=100,uuid"`
+	Street         string `json:"street" validate:"max=255"`
+	City           string `json:"city" validate:"max=255"`
+	Province       string `json:"province" validate:"max=255"`
+	PostalCode     string `json:"postal_code" validate:"max=10"`
+	Country        string `json:"country" validate:"max=100"`
 }
 
 type UpdateAddressRequest struct {
-	UserId     string `json:"-" validate:"required"`
-	ContactId  string `json:"-" validate:"required,max=100,uuid"`
-	ID         string `json:"-" validate:"required,max=100,uuid"`
-	Street     string `json:"street" validate:"max=255"`
-	City       string `json:"city" validate:"max=255"`
-	Province   string `json:"province" validate:"max=255"`
-	PostalCode string `json:"postal_code" validate:"max=10"`
-	Country    string `json:"country" validate:"max=100"`
+	UserId         string `json:"-" validate:"required"`
+	ImpersonatorId string `json:"-"`
+	ContactId      string `json:"-" validate:"required,max=100,uuid"`
+	ID             string `json:"-" validate:"required,max=100,uuid"`
+	Street         string `json:"street" validate:"max=255"`
+	City           string `json:"city" validate:"max=255"`
+	Province       string `json:"province" validate:"max=255"`
+	PostalCode     string `json:"postal_code" validate:"max=10"`
+	Country        string `json:"country" validate:"max=100"`
 }
 
 type GetAddressRequest struct {
@@ -44,7 +49,20 @@ type GetAddressRequest struct {
 }
 
 type DeleteAddressRequest struct {
+	UserId         string `json:"-" validate:"required"`
+	ImpersonatorId string `json:"-"`
+	ContactId      string `json:"-" validate:"required,max=100,uuid"`
+	ID             string `json:"-" validate:"required,max=100,uuid"`
+}
+
+type ListTrashAddressRequest struct {
 	UserId    string `json:"-" validate:"required"`
 	ContactId string `json:"-" validate:"required,max=100,uuid"`
-	ID        string `json:"-" validate:"required,max=100,uuid"`
+}
+
+type RestoreAddressRequest struct {
+	UserId         string `json:"-" validate:"required"`
+	ImpersonatorId string `json:"-"`
+	ContactId      string `json:"-" validate:"required,max=100,uuid"`
+	ID             string `json:"-" validate:"required,max=100,uuid"`
 }
@@ -0,0 +1,87 @@
+package model
+
+type AdminUserResponse struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Email        string `json:"email,omitempty"`
+	Role         string `json:"role"`
+	Disabled     bool   `json:"disabled"`
+	DeleteAt     int64  `json:"delete_at,omitempty"`
+	TotpEnabled  bool   `json:"totp_enabled"`
+	ContactCount int64  `json:"contact_count"`
+	CreatedAt    int64  `json:"created_at"`
+	UpdatedAt    int64  `json:"updated_at"`
+}
+
+type SearchUserRequest struct {
+	Key  string `json:"key" validate:"max=100"`
+	Role string `json:"role" validate:"omitempty,oneof=user admin"`
+	Page int    `json:"page" validate:"min=1"`
+	Size int    `json:"size" validate:"min=1,max=100"`
+}
+
+type GetAdminUserRequest struct {
+	ID string `json:"-" validate:"required,max=100"`
+}
+
+type UpdateUserRoleRequest struct {
+	AdminId string `json:"-" validate:"required,max=100"`
+	ID      string `json:"-" validate:"required,max=100"`
+	Role    string `json:"role" validate:"required,oneof=user admin"`
+}
+
+type DisableUserRequest struct {
+	AdminId  string `json:"-" validate:"required,max=100"`
+	ID       string `json:"-" validate:"required,max=100"`
+	Disabled bool   `json:"-"`
+}
+
+type LogoutUserSessionsRequest struct {
+	ID string `json:"-" validate:"required,max=100"`
+}
+
+const (
+	AuditActionImpersonate = "impersonate"
+	AuditActionRequest     = "request"
+	AuditActionRefresh     = "refresh"
+)
+
+type ImpersonateUserRequest struct {
+	AdminId string `json:"-" validate:"required,max=100"`
+	ID      string `json:"-" validate:"required,max=100"`
+	// AllowWrites lets the admin change data as the user, impersonation is read only by default
+	AllowWrites bool   `json:"allow_writes"`
+	UserAgent   string `json:"-"`
+	IpAddress   string `json:"-" validate:"max=100"`
+}
+
+type AuditLogResponse struct {
+	ID             string `json:"id"`
+	Action         string `json:"action"`
+	SessionId      string `json:"session_id"`
+	ImpersonatorId string `json:"impersonator_id"`
+	UserId         string `json:"user_id"`
+	Method         string `json:"method,omitempty"`
+	Path           string `json:"path,omitempty"`
+	Status         int    `json:"status,omitempty"`
+	IpAddress      string `json:"ip_address,omitempty"`
+	CreatedAt      int64  `json:"created_at"`
+}
+
+type CreateAuditLogRequest struct {
+	SessionId      string `validate:"required,max=100"`
+	ImpersonatorId string `validate:"required,max=100"`
+	UserId         string `validate:"required,max=100"`
+	Method         string `validate:"required,max=10"`
+	Path           string `validate:"required,max=2048"`
+	Status         int
+	IpAddress      string `validate:"max=100"`
+}
+
+type SearchAuditLogRequest struct {
+	ImpersonatorId string `json:"impersonator_id" validate:"max=100"`
+	UserId         string `json:"user_id" validate:"max=100"`
+	SessionId      string `json:"session_id" validate:"max=100"`
+	Page           int    `json:"page" validate:"min=1"`
+	Size           int    `json:"size" validate:"min=1,max=100"`
+}
@@ -0,0 +1,34 @@
+package model
+
+// Scopes an api key can be granted, session tokens are not limited by them
+const (
+	ScopeContactsRead   = "contacts:read"
+	ScopeContactsWrite  = "contacts:write"
+	ScopeAddressesRead  = "addresses:read"
+	ScopeAddressesWrite = "addresses:write"
+)
+
+type ApiKeyResponse struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Key        string   `json:"key,omitempty"`
+	Scopes     []string `json:"scopes"`
+	LastUsedAt int64    `json:"last_used_at,omitempty"`
+	CreatedAt  int64    `json:"created_at"`
+}
+
+type CreateApiKeyRequest struct {
+	UserId string   `json:"-" validate:"required,max=100"`
+	Name   string   `json:"name" validate:"required,max=100"`
+	Scopes []string `json:"scopes" validate:"required,min=1,unique,dive,oneof=contacts:read contacts:write addresses:read addresses:write"`
+}
+
+type ListApiKeyRequest struct {
+	UserId string `json:"-" validate:"required,max=100"`
+}
+
+type DeleteApiKeyRequest struct {
+	UserId string `json:"-" validate:"required,max=100"`
+	ID     string `json:"-" validate:"required,max=100,uuid"`
+}
@@ -1,6 +1,33 @@
 package model
 
+import "slices"
+
 type Auth struct {
-	// Login user id
-	ID string
+	// Login user id, the effective user while impersonating
+	ID        string
+	SessionId string
+	// Role of the user when the access token was issued, empty for api keys
+	Role           string
+	ApiKeyId       string
+	Scopes         []string
+	ImpersonatorId string
+	// Writes are refused for the whole impersonation
+	ReadOnly bool
+}
+
+// RealId is the user actually making the request, the admin while impersonating
+func (a *Auth) RealId() string {
+	if a.ImpersonatorId != "" {
+		return a.ImpersonatorId
+	}
+	return a.ID
+}
+
+func (a *Auth) IsImpersonated() bool {
+	return a.ImpersonatorId != ""
+}
+
+// HasScope reports whether the request may use scope, session tokens hold every scope
+func (a *Auth) HasScope(scope string) bool {
+	return a.ApiKeyId == "" || slices.Contains(a.Scopes, scope)
 }
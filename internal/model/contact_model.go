@@ -9,32 +9,44 @@ type ContactResponse struct {
 	CreatedAt int64             `json:"created_at"`
 	UpdatedAt int64             `json:"updated_at"`
 	Addresses []AddressResponse `json:"addresses,omitempty"`
+	Score     float64           `json:"score,omitempty"`
+	DeletedAt int64             `json:"deleted_at,omitempty"`
 }
 
 type CreateContactRequest struct {
-	UserId    string `json:"-" validate:"required"`
-	FirstName string `json:"first_name" validate:"required,max=100"`
-	LastName  string `json:"last_name" validate:"max=100"`
-	Email     string `json:"email" validate:"max=200,email"`
-	Phone     string `json:"phone" validate:"max=20"`
+	UserId         string `json:"-" validate:"required"`
+	ImpersonatorId string `json:"-"`
+	FirstName      string `json:"first_name" validate:"required,max=100"`
+	LastName       string `json:"last_name" validate:"max=100"`
+	Email          string `json:"email" validate:"max=200,email"`
+	Phone          string `json:"phone" validate:"max=20"`
 }
 
 type UpdateContactRequest struct {
-	UserId    string `json:"-" validate:"required"`
-	ID        string `json:"-" validate:"required,max=100,uuid"`
-	FirstName string `json:"first_name" validate:"required,max=100"`
-	LastName  string `json:"last_name" validate:"max=100"`
-	Email     string `json:"email" validate:"max=200,email"`
-	Phone     string `json:"phone" validate:"max=20"`
+	UserId         string `json:"-" validate:"required"`
+	ImpersonatorId string `json:"-"`
+	ID             string `json:"-" validate:"required,max=100,uuid"`
+	FirstName      string `json:"first_name" validate:"required,max=100"`
+	LastName       string `json:"last_name" validate:"max=100"`
+	Email          string `json:"email" validate:"max=200,email"`
+	Phone          string `json:"phone" validate:"max=20"`
 }
 
 type SearchContactRequest struct {
 	UserId string `json:"-" validate:"required"`
+	Query  string `json:"q" validate:"max=200"`
+	Fuzzy  bool   `json:"fuzzy"`
 	Name   string `json:"name" validate:"max=100"`
 	Email  string `json:"email" validate:"max=200"`
 	Phone  string `json:"phone" validate:"max=20"`
+	Group  string `json:"group" validate:"max=100"`
 	Page   int    `json:"page" validate:"min=1"`
 	Size   int    `json:"size" validate:"min=1,max=100"`
+	// Sort fields, a leading - sorts descending
+	Sort   []string `json:"sort" validate:"max=6,dive,oneof=first_name -first_name last_name -last_name email -email phone -phone created_at -created_at updated_at -updated_at"`
+	Cursor string   `json:"cursor" validate:"max=2000"`
+	// SkipCount leaves out the total with cursor pagination, it costs a scan of every match
+	SkipCount bool `json:"skip_count"`
 }
 
 type GetContactRequest struct {
@@ -43,6 +55,82 @@ type GetContactRequest struct {
 }
 
 type DeleteContactRequest struct {
+	UserId         string `json:"-" validate:"required"`
+	ImpersonatorId string `json:"-"`
+	ID             string `json:"-" validate:"required,max=100,uuid"`
+}
+
+type ListTrashContactRequest struct {
 	UserId string `json:"-" validate:"required"`
-	ID     string `json:"-" validate:"required,max=100,uuid"`
+	Page   int    `json:"page" validate:"min=1"`
+	Size   int    `json:"size" validate:"min=1,max=100"`
+}
+
+type RestoreContactRequest struct {
+	UserId         string `json:"-" validate:"required"`
+	ImpersonatorId string `json:"-"`
+	ID             string `json:"-" validate:"required,max=100,uuid"`
+}
+
+const (
+	// MergeKeepTarget keeps the values of the target, only its empty fields are filled from the others
+	MergeKeepTarget = "keep_target"
+	// MergeNewest takes each field from the most recently updated contact that has it
+	MergeNewest = "newest"
+)
+
+type DuplicateResponse struct {
+	Reasons  []string          `json:"reasons"`
+	Contacts []ContactResponse `json:"contacts"`
+}
+
+type ListDuplicateRequest struct {
+	UserId string `json:"-" validate:"required"`
+	Page   int    `json:"page" validate:"min=1"`
+	Size   int    `json:"size" validate:"min=1,max=100"`
+}
+
+type MergeContactRequest struct {
+	UserId         string `json:"-" validate:"required"`
+	ImpersonatorId string `json:"-"`
+	// TargetId is the contact the others are merged into, the only one left afterwards
+	TargetId  string   `json:"target_id" validate:"required,max=100,uuid"`
+	SourceIds []string `json:"source_ids" validate:"required,min=1,max=20,unique,dive,required,max=100,uuid"`
+	Strategy  string   `json:"strategy" validate:"omitempty,oneof=keep_target newest"`
+	// Fields picks the contact a field is taken from, overriding the strategy for that field
+	Fields map[string]string `json:"fields" validate:"max=4,dive,keys,oneof=first_name last_name email phone,endkeys,required,max=100,uuid"`
+}
+
+// ExportContactRequest picks the contacts to export the way a search does, paging aside, or the
+// single contact ID when it is set
+type ExportContactRequest struct {
+	SearchContactRequest
+	ID      string `json:"-" validate:"omitempty,max=100,uuid"`
+	Version string `json:"version" validate:"oneof=3.0 4.0"`
+	// WithAddresses is false for api keys without the addresses:read scope
+	WithAddresses bool `json:"-"`
+}
+
+type ImportContactRequest struct {
+	UserId         string `json:"-" validate:"required"`
+	ImpersonatorId string `json:"-"`
+	// VCard holds one or more vCard 3.0 or 4.0 cards
+	VCard string `json:"-" validate:"required"`
+	// WithAddresses is false for api keys without the addresses:write scope, ADR is skipped then
+	WithAddresses bool `json:"-"`
+}
+
+type ImportContactResponse struct {
+	Imported int                   `json:"imported"`
+	Failed   int                   `json:"failed"`
+	Results  []ImportContactResult `json:"results"`
+}
+
+type ImportContactResult struct {
+	Index     int    `json:"index"`
+	Name      string `json:"name,omitempty"`
+	ContactId string `json:"contact_id,omitempty"`
+	Addresses int    `json:"addresses"`
+	// Errors are the json names of the rejected fields, card when the card could not be read at all
+	Errors []string `json:"errors,omitempty"`
 }
@@ -0,0 +1,42 @@
+package model
+
+const (
+	RevisionCreate  = "create"
+	RevisionUpdate  = "update"
+	RevisionDelete  = "delete"
+	RevisionRestore = "restore"
+	RevisionRevert  = "revert"
+	RevisionMerge   = "merge"
+)
+
+type RevisionResponse struct {
+	ID             string                    `json:"id"`
+	AddressId      string                    `json:"address_id,omitempty"`
+	Action         string                    `json:"action"`
+	UserId         string                    `json:"user_id"`
+	ImpersonatorId string                    `json:"impersonator_id,omitempty"`
+	Changes        map[string]RevisionChange `json:"changes"`
+	CreatedAt      int64                     `json:"created_at"`
+}
+
+// RevisionChange is the value of a field before and after a revision, empty when there was none
+type RevisionChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+type ListRevisionRequest struct {
+	UserId    string `json:"-" validate:"required"`
+	ContactId string `json:"-" validate:"required,max=100,uuid"`
+	Page      int    `json:"page" validate:"min=1"`
+	Size      int    `json:"size" validate:"min=1,max=100"`
+}
+
+type RevertRevisionRequest struct {
+	UserId         string `json:"-" validate:"required"`
+	ImpersonatorId string `json:"-"`
+	ContactId      string `json:"-" validate:"required,max=100,uuid"`
+	ID             string `json:"-" validate:"required,max=100,uuid"`
+	// AllowAddresses is false for api keys without the addresses:write scope
+	AllowAddresses bool `json:"-"`
+}
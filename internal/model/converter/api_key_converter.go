@@ -0,0 +1,19 @@
+package converter
+
+import (
+	"strings"
+
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+)
+
+func ApiKeyToResponse(apiKey *entity.ApiKey) *model.ApiKeyResponse {
+	return &model.ApiKeyResponse{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     strings.Fields(apiKey.Scopes),
+		LastUsedAt: apiKey.LastUsedAt,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
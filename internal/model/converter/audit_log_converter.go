@@ -0,0 +1,21 @@
+package converter
+
+import (
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+)
+
+func AuditLogToResponse(auditLog *entity.AuditLog) *model.AuditLogResponse {
+	return &model.AuditLogResponse{
+		ID:             auditLog.ID,
+		Action:         auditLog.Action,
+		SessionId:      auditLog.SessionId,
+		ImpersonatorId: auditLog.ImpersonatorId,
+		UserId:         auditLog.UserId,
+		Method:         auditLog.Method,
+		Path:           auditLog.Path,
+		Status:         auditLog.Status,
+		IpAddress:      auditLog.IpAddress,
+		CreatedAt:      auditLog.CreatedAt,
+	}
+}
@@ -14,5 +14,6 @@ func ContactToResponse(contact *entity.Contact) *model.ContactResponse {
 		Phone:     contact.Phone,
 		CreatedAt: contact.CreatedAt,
 		UpdatedAt: contact.UpdatedAt,
+		DeletedAt: int64(contact.DeletedAt),
 	}
-}
\ No newline at end of file
+}
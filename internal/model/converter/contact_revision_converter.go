@@ -0,0 +1,24 @@
+package converter
+
+import (
+	"encoding/json"
+
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+)
+
+func RevisionToResponse(revision *entity.ContactRevision) *model.RevisionResponse {
+	// changes are only ever written by the use case, so they always decode
+	changes := make(map[string]model.RevisionChange)
+	_ = json.Unmarshal([]byte(revision.Changes), &changes)
+
+	return &model.RevisionResponse{
+		ID:             revision.ID,
+		AddressId:      revision.AddressId,
+		Action:         revision.Action,
+		UserId:         revision.UserId,
+		ImpersonatorId: revision.ImpersonatorId,
+		Changes:        changes,
+		CreatedAt:      revision.CreatedAt,
+	}
+}
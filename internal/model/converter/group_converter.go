@@ -0,0 +1,16 @@
+package converter
+
+import (
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+)
+
+func GroupToResponse(group *entity.Group, contactCount int64) *model.GroupResponse {
+	return &model.GroupResponse{
+		ID:           group.ID,
+		Name:         group.Name,
+		ContactCount: contactCount,
+		CreatedAt:    group.CreatedAt,
+		UpdatedAt:    group.UpdatedAt,
+	}
+}
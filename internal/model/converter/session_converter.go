@@ -0,0 +1,18 @@
+package converter
+
+import (
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+)
+
+func SessionToResponse(session *entity.Session, currentSessionId string) *model.SessionResponse {
+	return &model.SessionResponse{
+		ID:           session.ID,
+		UserAgent:    session.UserAgent,
+		IpAddress:    session.IpAddress,
+		Current:      session.ID == currentSessionId,
+		Impersonated: session.ImpersonatorId != "",
+		LastSeenAt:   session.LastSeenAt,
+		CreatedAt:    session.CreatedAt,
+	}
+}
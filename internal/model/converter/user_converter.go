@@ -7,16 +7,37 @@ import (
 
 func UserToResponse(user *entity.User) *model.UserResponse {
 	return &model.UserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:            user.ID,
+		Name:          user.Name,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt > 0,
+		Role:          user.Role,
+		TotpEnabled:   user.TotpEnabled,
+		DeleteAt:      user.DeleteAt,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
 	}
 }
 
-func UserToTokenResponse(user *entity.User) *model.UserResponse {
-	return &model.UserResponse{
-		Token: user.Token,
+func TokenToResponse(accessToken string, refreshToken string, expiresAt int64) *model.TokenResponse {
+	return &model.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt,
 	}
 }
 
+func UserToAdminResponse(user *entity.User, contactCount int64) *model.AdminUserResponse {
+	return &model.AdminUserResponse{
+		ID:           user.ID,
+		Name:         user.Name,
+		Email:        user.Email,
+		Role:         user.Role,
+		Disabled:     user.Disabled,
+		DeleteAt:     user.DeleteAt,
+		TotpEnabled:  user.TotpEnabled,
+		ContactCount: contactCount,
+		CreatedAt:    user.CreatedAt,
+		UpdatedAt:    user.UpdatedAt,
+	}
+}
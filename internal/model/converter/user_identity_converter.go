@@ -0,0 +1,16 @@
+package converter
+
+import (
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+)
+
+func UserIdentityToResponse(identity *entity.UserIdentity) *model.UserIdentityResponse {
+	return &model.UserIdentityResponse{
+		ID:        identity.ID,
+		Provider:  identity.Provider,
+		Subject:   identity.Subject,
+		Email:     identity.Email,
+		CreatedAt: identity.CreatedAt,
+	}
+}
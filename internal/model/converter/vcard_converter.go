@@ -0,0 +1,83 @@
+package converter
+
+import (
+	"strings"
+	"time"
+
+	"github.com/emersion/go-vcard"
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+)
+
+func ContactToVCard(contact *entity.Contact, version string) vcard.Card {
+	card := make(vcard.Card)
+	card.SetValue(vcard.FieldVersion, version)
+	card.SetValue(vcard.FieldUID, "urn:uuid:"+contact.ID)
+	card.SetName(&vcard.Name{GivenName: contact.FirstName, FamilyName: contact.LastName})
+	card.SetValue(vcard.FieldFormattedName, strings.TrimSpace(contact.FirstName+" "+contact.LastName))
+	card.SetRevision(time.UnixMilli(contact.UpdatedAt).UTC())
+
+	if contact.Email != "" {
+		card.SetValue(vcard.FieldEmail, contact.Email)
+	}
+	if contact.Phone != "" {
+		card.SetValue(vcard.FieldTelephone, contact.Phone)
+	}
+
+	for _, address := range contact.Addresses {
+		card.AddAddress(&vcard.Address{
+			StreetAddress: address.Street,
+			Locality:      address.City,
+			Region:        address.Province,
+			PostalCode:    address.PostalCode,
+			Country:       address.Country,
+		})
+	}
+
+	return card
+}
+
+// VCardToContact reads the contact and its addresses from a card. The name comes from N, or FN split
+// at its first space when N has none. Preferred emails and phones win, empty addresses are skipped
+func VCardToContact(card vcard.Card) (*model.CreateContactRequest, []model.CreateAddressRequest) {
+	contact := new(model.CreateContactRequest)
+	if name := card.Name(); name != nil {
+		contact.FirstName = strings.TrimSpace(strings.Join([]string{name.GivenName, name.AdditionalName}, " "))
+		contact.LastName = strings.TrimSpace(name.FamilyName)
+	}
+	if contact.FirstName == "" && contact.LastName == "" {
+		first, last, _ := strings.Cut(strings.TrimSpace(card.PreferredValue(vcard.FieldFormattedName)), " ")
+		contact.FirstName, contact.LastName = first, strings.TrimSpace(last)
+	}
+
+	// vCard 4.0 may write them as uris
+	contact.Email = strings.TrimPrefix(strings.TrimSpace(card.PreferredValue(vcard.FieldEmail)), "mailto:")
+	contact.Phone = strings.TrimPrefix(strings.TrimSpace(card.PreferredValue(vcard.FieldTelephone)), "tel:")
+
+	var addresses []model.CreateAddressRequest
+	for _, adr := range card.Addresses() {
+		street := strings.Join(nonEmpty(adr.StreetAddress, adr.ExtendedAddress, adr.PostOfficeBox), ", ")
+		address := model.CreateAddressRequest{
+			Street:     street,
+			City:       strings.TrimSpace(adr.Locality),
+			Province:   strings.TrimSpace(adr.Region),
+			PostalCode: strings.TrimSpace(adr.PostalCode),
+			Country:    strings.TrimSpace(adr.Country),
+		}
+		if address.Street+address.City+address.Province+address.PostalCode+address.Country != "" {
+			addresses = append(addresses, address)
+		}
+	}
+
+	return contact, addresses
+}
+
+func nonEmpty(values ...string) []string {
+	var result []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+)
+
+// PageCursor points between two rows of a listing by the sort values and id of the row next to it.
+// Clients only see it encoded and should treat it as opaque
+type PageCursor struct {
+	Sort     string `json:"s"`
+	Values   []any  `json:"v"`
+	ID       string `json:"id"`
+	Backward bool   `json:"b,omitempty"`
+}
+
+func (c *PageCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Fits reports whether the cursor was made for sort, values are those of any row sorted that way
+// and only serve to compare types
+func (c *PageCursor) Fits(sort string, values []any) bool {
+	if c.Sort != sort || c.ID == "" || len(c.Values) != len(values) {
+		return false
+	}
+
+	for i, value := range values {
+		if reflect.TypeOf(c.Values[i]) != reflect.TypeOf(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// DecodePageCursor reverses Encode, numbers come back as int64 since sort columns hold no fractions
+func DecodePageCursor(cursor string) (*PageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	pageCursor := new(PageCursor)
+	if err := decoder.Decode(pageCursor); err != nil {
+		return nil, err
+	}
+
+	for i, value := range pageCursor.Values {
+		if number, ok := value.(json.Number); ok {
+			if pageCursor.Values[i], err = number.Int64(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return pageCursor, nil
+}
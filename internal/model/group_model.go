@@ -0,0 +1,40 @@
+package model
+
+type GroupResponse struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	ContactCount int64  `json:"contact_count"`
+	CreatedAt    int64  `json:"created_at"`
+	UpdatedAt    int64  `json:"updated_at"`
+}
+
+type CreateGroupRequest struct {
+	UserId string `json:"-" validate:"required"`
+	Name   string `json:"name" validate:"required,max=100"`
+}
+
+type UpdateGroupRequest struct {
+	UserId string `json:"-" validate:"required"`
+	ID     string `json:"-" validate:"required,max=100,uuid"`
+	Name   string `json:"name" validate:"required,max=100"`
+}
+
+type ListGroupRequest struct {
+	UserId string `json:"-" validate:"required"`
+}
+
+type GetGroupRequest struct {
+	UserId string `json:"-" validate:"required"`
+	ID     string `json:"-" validate:"required,max=100,uuid"`
+}
+
+type DeleteGroupRequest struct {
+	UserId string `json:"-" validate:"required"`
+	ID     string `json:"-" validate:"required,max=100,uuid"`
+}
+
+type GroupMembersRequest struct {
+	UserId     string   `json:"-" validate:"required"`
+	ID         string   `json:"-" validate:"required,max=100,uuid"`
+	ContactIds []string `json:"contact_ids" validate:"required,min=1,max=100,unique,dive,required,max=100,uuid"`
+}
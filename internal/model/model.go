@@ -12,9 +12,12 @@ type PageResponse[T any] struct {
 	PageMetadata PageMetadata `json:"paging,omitempty"`
 }
 
+// PageMetadata describes a page of a listing, totals are -1 when counting was skipped
 type PageMetadata struct {
-	Page      int   `json:"page"`
-	Size      int   `json:"size"`
-	TotalItem int64 `json:"total_item"`
-	TotalPage int64 `json:"total_page"`
+	Page       int    `json:"page"`
+	Size       int    `json:"size"`
+	TotalItem  int64  `json:"total_item"`
+	TotalPage  int64  `json:"total_page"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
@@ -0,0 +1,42 @@
+package model
+
+type OidcAuthorizationResponse struct {
+	AuthorizationUrl string `json:"authorization_url"`
+	// Binding is kept in a cookie of the user agent, the callback has to present it
+	Binding string `json:"-"`
+}
+
+type UserIdentityResponse struct {
+	ID        string `json:"id"`
+	Provider  string `json:"provider"`
+	Subject   string `json:"subject"`
+	Email     string `json:"email,omitempty"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// OidcStartRequest starts a login, or links the identity to UserId when it is set
+type OidcStartRequest struct {
+	Provider string `json:"provider" validate:"required,max=100"`
+	UserId   string `json:"-" validate:"max=100"`
+}
+
+// OidcCallbackRequest carries the digest of the state kept by the user agent as Binding,
+// UserId is set when the callback comes with an access token
+type OidcCallbackRequest struct {
+	Provider  string `json:"-" validate:"required,max=100"`
+	Code      string `json:"code" validate:"required,max=2048"`
+	State     string `json:"state" validate:"required,max=100"`
+	Binding   string `json:"-" validate:"required,max=100"`
+	UserId    string `json:"-" validate:"max=100"`
+	UserAgent string `json:"-"`
+	IpAddress string `json:"-" validate:"max=100"`
+}
+
+type ListUserIdentityRequest struct {
+	UserId string `json:"-" validate:"required,max=100"`
+}
+
+type DeleteUserIdentityRequest struct {
+	UserId string `json:"-" validate:"required,max=100"`
+	ID     string `json:"-" validate:"required,max=100,uuid"`
+}
@@ -0,0 +1,25 @@
+package model
+
+type SessionResponse struct {
+	ID           string `json:"id"`
+	UserAgent    string `json:"user_agent"`
+	IpAddress    string `json:"ip_address"`
+	Current      bool   `json:"current"`
+	LastSeenAt   int64  `json:"last_seen_at"`
+	CreatedAt    int64  `json:"created_at"`
+	Impersonated bool   `json:"impersonated,omitempty"`
+}
+
+type ListSessionRequest struct {
+	UserId           string `json:"-" validate:"required,max=100"`
+	CurrentSessionId string `json:"-"`
+}
+
+type RevokeSessionRequest struct {
+	UserId string `json:"-" validate:"required,max=100"`
+	ID     string `json:"-" validate:"required,max=100,uuid"`
+}
+
+type RevokeAllSessionRequest struct {
+	UserId string `json:"-" validate:"required,max=100"`
+}
@@ -0,0 +1,25 @@
+package model
+
+type TotpResponse struct {
+	Secret string `json:"secret"`
+	Uri    string `json:"uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type EnrollTotpRequest struct {
+	UserId string `json:"-" validate:"required,max=100"`
+}
+
+type ConfirmTotpRequest struct {
+	UserId string `json:"-" validate:"required,max=100"`
+	Code   string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type DisableTotpRequest struct {
+	UserId    string `json:"-" validate:"required,max=100"`
+	Password  string `json:"password" validate:"required,max=100"`
+	IpAddress string `json:"-"`
+}
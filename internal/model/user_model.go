@@ -1,38 +1,115 @@
 package model
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type UserResponse struct {
-	ID        string `json:"id,omitempty"`
-	Name      string `json:"name,omitempty"`
-	Token     string `json:"token,omitempty"`
-	CreatedAt int64  `json:"created_at,omitempty"`
-	UpdatedAt int64  `json:"updated_at,omitempty"`
+	ID            string `json:"id,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified bool   `json:"email_verified,omitempty"`
+	Role          string `json:"role,omitempty"`
+	TotpEnabled   bool   `json:"totp_enabled,omitempty"`
+	DeleteAt      int64  `json:"delete_at,omitempty"`
+	CreatedAt     int64  `json:"created_at,omitempty"`
+	UpdatedAt     int64  `json:"updated_at,omitempty"`
+}
+
+// TokenResponse carries either a token pair or, when the account has a second
+// factor, the challenge token to send back to the second login step
+type TokenResponse struct {
+	AccessToken    string `json:"access_token,omitempty"`
+	RefreshToken   string `json:"refresh_token,omitempty"`
+	ExpiresAt      int64  `json:"expires_at,omitempty"`
+	ChallengeToken string `json:"challenge_token,omitempty"`
 }
 
 type VerifyUserRequest struct {
-	Token string `validate:"required,max=100"`
+	Token string `validate:"required"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+	UserAgent    string `json:"-"`
+	IpAddress    string `json:"-" validate:"max=100"`
 }
 
 type RegisterUserRequest struct {
-	ID       string `json:"id" validate:"required,max=100"`
+	ID       string `json:"id" validate:"required,max=100,excludes=@"`
 	Password string `json:"password" validate:"required,max=100"`
 	Name     string `json:"name" validate:"required,max=100"`
+	Email    string `json:"email" validate:"omitempty,max=200,email"`
 }
 
 type UpdateUserRequest struct {
 	ID       string `json:"-" validate:"required,max=100"`
 	Password string `json:"password,omitempty" validate:"max=100"`
 	Name     string `json:"name,omitempty" validate:"max=100"`
+	Email    string `json:"email,omitempty" validate:"omitempty,max=200,email"`
 }
 
+// LoginUserRequest takes either the user id or a verified email address as ID
 type LoginUserRequest struct {
-	ID       string `json:"id" validate:"required,max=100"`
-	Password string `json:"password" validate:"required,max=100"`
+	ID        string `json:"id" validate:"required,max=200"`
+	Password  string `json:"password" validate:"required,max=100"`
+	UserAgent string `json:"-"`
+	IpAddress string `json:"-" validate:"max=100"`
+}
+
+type LoginTotpRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code,omitempty" validate:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	RecoveryCode   string `json:"recovery_code,omitempty" validate:"required_without=Code,omitempty,max=20"`
+	UserAgent      string `json:"-"`
+	IpAddress      string `json:"-" validate:"max=100"`
 }
 
 type LogoutUserRequest struct {
-	ID string `json:"id" validate:"required,max=100"`
+	ID        string `json:"id" validate:"required,max=100"`
+	SessionId string `json:"-" validate:"required,max=100"`
 }
 
 type GetUserRequest struct {
 	ID string `json:"id" validate:"required,max=100"`
 }
+
+type DeleteUserRequest struct {
+	ID        string `json:"-" validate:"required,max=100"`
+	Password  string `json:"password" validate:"required,max=100"`
+	IpAddress string `json:"-"`
+}
+
+type RestoreUserRequest struct {
+	ID        string `json:"id" validate:"required,max=100"`
+	Password  string `json:"password" validate:"required,max=100"`
+	IpAddress string `json:"-"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,max=200,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required,max=100"`
+	Password string `json:"password" validate:"required,max=100"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required,max=100"`
+}
+
+type SendEmailVerificationRequest struct {
+	UserId string `json:"-" validate:"required,max=100"`
+}
+
+type MagicLinkRequest struct {
+	Email string `json:"email" validate:"required,max=200,email"`
+}
+
+type LoginMagicLinkRequest struct {
+	Token     string `json:"token" validate:"required,max=1000"`
+	UserAgent string `json:"-"`
+	IpAddress string `json:"-" validate:"max=100"`
+}
@@ -21,10 +21,48 @@ func (r *AddressRepository) FindByIdAndContactId(tx *gorm.DB, address *entity.Ad
 	return tx.Where("id = ? AND contact_id = ?", id, contactId).First(address).Error
 }
 
-func (r *AddressRepository) FindAllByContactId(tx *gorm.DB, contactId string) ([]entity.Address, error) {
+func (r *AddressRepository) FindAllByContactId(tx *gorm.DB, contactId string, sort []string) ([]entity.Address, error) {
+	if len(sort) == 0 {
+		sort = []string{"created_at"}
+	}
+
+	var addresses []entity.Address
+	if err := tx.Where("contact_id = ?", contactId).Clauses(orderBy(sort)).Find(&addresses).Error; err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+func (r *AddressRepository) FindAnyByIdAndContactId(tx *gorm.DB, address *entity.Address, id string, contactId string) error {
+	return tx.Unscoped().Where("id = ? AND contact_id = ?", id, contactId).Take(address).Error
+}
+
+func (r *AddressRepository) FindTrashedByIdAndContactId(tx *gorm.DB, address *entity.Address, id string, contactId string) error {
+	return tx.Unscoped().Where("id = ? AND contact_id = ? AND deleted_at > 0", id, contactId).Take(address).Error
+}
+
+func (r *AddressRepository) FindAllTrashedByContactId(tx *gorm.DB, contactId string) ([]entity.Address, error) {
 	var addresses []entity.Address
-	if err := tx.Where("contact_id = ?", contactId).Find(&addresses).Error; err != nil {
+	if err := tx.Unscoped().Where("contact_id = ? AND deleted_at > 0", contactId).Order("deleted_at DESC, id").Find(&addresses).Error; err != nil {
 		return nil, err
 	}
 	return addresses, nil
 }
+
+func (r *AddressRepository) MoveAll(tx *gorm.DB, contactIds []string, targetId string) error {
+	return tx.Unscoped().Model(&entity.Address{}).Where("contact_id IN ?", contactIds).Update("contact_id", targetId).Error
+}
+
+func (r *AddressRepository) DeleteAllByContactId(tx *gorm.DB, contactId string) error {
+	return tx.Unscoped().Where("contact_id = ?", contactId).Delete(&entity.Address{}).Error
+}
+
+func (r *AddressRepository) DeleteAllTrashedBefore(tx *gorm.DB, before int64) (int64, error) {
+	result := tx.Unscoped().Where("deleted_at > 0 AND deleted_at <= ?", before).Delete(&entity.Address{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *AddressRepository) DeleteAllByUserId(tx *gorm.DB, userId string) error {
+	contactIds := tx.Unscoped().Model(&entity.Contact{}).Select("id").Where("user_id = ?", userId)
+	return tx.Unscoped().Where("contact_id IN (?)", contactIds).Delete(&entity.Address{}).Error
+}
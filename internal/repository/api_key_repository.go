@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ApiKeyRepository struct {
+	Repository[entity.ApiKey]
+	Log *logrus.Logger
+}
+
+func NewApiKeyRepository(log *logrus.Logger) *ApiKeyRepository {
+	return &ApiKeyRepository{
+		Log: log,
+	}
+}
+
+func (r *ApiKeyRepository) FindByToken(db *gorm.DB, apiKey *entity.ApiKey, token string) error {
+	return db.Where("token = ?", token).Take(apiKey).Error
+}
+
+func (r *ApiKeyRepository) FindByIdAndUserId(db *gorm.DB, apiKey *entity.ApiKey, id string, userId string) error {
+	return db.Where("id = ? AND user_id = ?", id, userId).Take(apiKey).Error
+}
+
+func (r *ApiKeyRepository) FindAllByUserId(db *gorm.DB, userId string) ([]entity.ApiKey, error) {
+	var apiKeys []entity.ApiKey
+	if err := db.Where("user_id = ?", userId).Order("created_at DESC").Find(&apiKeys).Error; err != nil {
+		return nil, err
+	}
+	return apiKeys, nil
+}
+
+func (r *ApiKeyRepository) DeleteAllByUserId(db *gorm.DB, userId string) error {
+	return db.Where("user_id = ?", userId).Delete(&entity.ApiKey{}).Error
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type AuditLogRepository struct {
+	Repository[entity.AuditLog]
+	Log *logrus.Logger
+}
+
+func NewAuditLogRepository(log *logrus.Logger) *AuditLogRepository {
+	return &AuditLogRepository{
+		Log: log,
+	}
+}
+
+func (r *AuditLogRepository) Search(db *gorm.DB, request *model.SearchAuditLogRequest) ([]entity.AuditLog, int64, error) {
+	var auditLogs []entity.AuditLog
+	if err := db.Scopes(r.FilterAuditLog(request)).Order("created_at DESC").Offset((request.Page - 1) * request.Size).Limit(request.Size).Find(&auditLogs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var total int64 = 0
+	if err := db.Model(&entity.AuditLog{}).Scopes(r.FilterAuditLog(request)).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return auditLogs, total, nil
+}
+
+func (r *AuditLogRepository) FilterAuditLog(request *model.SearchAuditLogRequest) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		if impersonatorId := request.ImpersonatorId; impersonatorId != "" {
+			tx = tx.Where("impersonator_id = ?", impersonatorId)
+		}
+
+		if userId := request.UserId; userId != "" {
+			tx = tx.Where("user_id = ?", userId)
+		}
+
+		if sessionId := request.SessionId; sessionId != "" {
+			tx = tx.Where("session_id = ?", sessionId)
+		}
+
+		return tx
+	}
+}
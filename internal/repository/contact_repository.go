@@ -1,12 +1,60 @@
 package repository
 
 import (
+	"database/sql"
+	"strings"
+	"unicode"
+
 	"github.com/iyasz/golang-clean-architecture/internal/entity"
 	"github.com/iyasz/golang-clean-architecture/internal/model"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// contactFullName has to stay the same as the trigram index expression for the index to be used
+const contactFullName = "(first_name || ' ' || coalesce(last_name, ''))"
+
+// duplicateNameSimilarity is how similar full names have to be for contacts to count as duplicates,
+// stricter than the pg_trgm threshold searches use since there is no query to match against
+const duplicateNameSimilarity = 0.6
+
+// duplicateQuery pairs up contacts of a user that share an email ignoring case, a phone by its digits
+// without leading zeros, or have similar full names. Each pair comes once with the lower id first.
+// The email and phone expressions have to stay the same as their indexes
+const duplicateQuery = `
+SELECT a.id AS contact_id, b.id AS other_id, 'email' AS reason
+FROM contacts a JOIN contacts b ON b.user_id = a.user_id AND b.id > a.id AND lower(b.email) = lower(a.email)
+WHERE a.user_id = @user AND a.deleted_at = 0 AND b.deleted_at = 0 AND coalesce(a.email, '') <> ''
+UNION ALL
+SELECT a.id, b.id, 'phone'
+FROM contacts a JOIN contacts b ON b.user_id = a.user_id AND b.id > a.id
+	AND ltrim(regexp_replace(coalesce(b.phone, ''), '[^0-9]', '', 'g'), '0') = ltrim(regexp_replace(coalesce(a.phone, ''), '[^0-9]', '', 'g'), '0')
+WHERE a.user_id = @user AND a.deleted_at = 0 AND b.deleted_at = 0 AND ltrim(regexp_replace(coalesce(a.phone, ''), '[^0-9]', '', 'g'), '0') <> ''
+UNION ALL
+SELECT a.id, b.id, 'name'
+FROM contacts a JOIN contacts b ON b.user_id = a.user_id AND b.id > a.id
+	AND (b.first_name || ' ' || coalesce(b.last_name, '')) % (a.first_name || ' ' || coalesce(a.last_name, ''))
+	AND similarity(b.first_name || ' ' || coalesce(b.last_name, ''), a.first_name || ' ' || coalesce(a.last_name, '')) >= @similarity
+WHERE a.user_id = @user AND a.deleted_at = 0 AND b.deleted_at = 0`
+
+type DuplicatePair struct {
+	ContactId string
+	OtherId   string
+	Reason    string
+}
+
+// contactSortColumns are the expressions contacts are paged by, nullable columns are coalesced
+// so keyset comparisons never meet NULL
+var contactSortColumns = map[string]string{
+	"first_name": "first_name",
+	"last_name":  "coalesce(last_name, '')",
+	"email":      "coalesce(email, '')",
+	"phone":      "coalesce(phone, '')",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
 type ContactRepository struct {
 	Repository[entity.Contact]
 	Log *logrus.Logger
@@ -22,25 +70,210 @@ func (r *ContactRepository) FindByIdAndUserId(db *gorm.DB, contact *entity.Conta
 	return db.Where("id =  ? AND user_id = ?", id, userId).Take(contact).Error
 }
 
+func (r *ContactRepository) CountByIdsAndUserId(db *gorm.DB, ids []string, userId string) (int64, error) {
+	var total int64
+	err := db.Model(&entity.Contact{}).Where("id IN ? AND user_id = ?", ids, userId).Count(&total).Error
+	return total, err
+}
+
+func (r *ContactRepository) FindAllByIdsAndUserId(db *gorm.DB, ids []string, userId string) ([]entity.Contact, error) {
+	var contacts []entity.Contact
+	if err := db.Where("id IN ? AND user_id = ?", ids, userId).Order("created_at, id").Find(&contacts).Error; err != nil {
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// FindDuplicatePairs returns up to limit pairs of contacts of the user that look alike, a pair
+// matching for more than one reason comes once per reason. The pairs are ordered so every page
+// of duplicates is built from the same ones
+func (r *ContactRepository) FindDuplicatePairs(db *gorm.DB, userId string, limit int) ([]DuplicatePair, error) {
+	var pairs []DuplicatePair
+	err := db.Raw("SELECT * FROM ("+duplicateQuery+") pairs ORDER BY contact_id, other_id, reason LIMIT @limit",
+		sql.Named("user", userId), sql.Named("similarity", duplicateNameSimilarity), sql.Named("limit", limit)).Scan(&pairs).Error
+	return pairs, err
+}
+
+func (r *ContactRepository) FindTrashedByIdAndUserId(db *gorm.DB, contact *entity.Contact, id string, userId string) error {
+	return db.Unscoped().Where("id = ? AND user_id = ? AND deleted_at > 0", id, userId).Take(contact).Error
+}
+
+func (r *ContactRepository) SearchTrash(db *gorm.DB, request *model.ListTrashContactRequest) ([]entity.Contact, int64, error) {
+	trash := db.Unscoped().Model(&entity.Contact{}).Where("user_id = ? AND deleted_at > 0", request.UserId)
 
-func (r *ContactRepository) Search(db *gorm.DB, request *model.SearchContactRequest) ([]entity.Contact, int64, error) {
 	var contacts []entity.Contact
-	if err := db.Scopes(r.FilterContact(request)).Offset((request.Page - 1) * request.Size).Limit(request.Size).Find(&contacts).Error; err != nil {
+	if err := trash.Session(&gorm.Session{}).Order("deleted_at DESC, id").Offset((request.Page - 1) * request.Size).Limit(request.Size).Find(&contacts).Error; err != nil {
 		return nil, 0, err
 	}
 
 	var total int64 = 0
-	if err := db.Model(&entity.Contact{}).Scopes(r.FilterContact(request)).Count(&total).Error; err != nil {
+	if err := trash.Session(&gorm.Session{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	return contacts, total, nil
 }
 
+func (r *ContactRepository) FindAllTrashedBefore(db *gorm.DB, before int64, limit int) ([]entity.Contact, error) {
+	var contacts []entity.Contact
+	if err := db.Unscoped().Where("deleted_at > 0 AND deleted_at <= ?", before).Order("deleted_at").Limit(limit).Find(&contacts).Error; err != nil {
+		return nil, err
+	}
+	return contacts, nil
+}
+
+func (r *ContactRepository) DeleteAllByUserId(db *gorm.DB, userId string) error {
+	return db.Unscoped().Where("user_id = ?", userId).Delete(&entity.Contact{}).Error
+}
+
+func (r *ContactRepository) CountByUserIds(db *gorm.DB, userIds []string) (map[string]int64, error) {
+	var rows []struct {
+		UserId string
+		Total  int64
+	}
+	if err := db.Model(&entity.Contact{}).Select("user_id, COUNT(*) AS total").Where("user_id IN ?", userIds).Group("user_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.UserId] = row.Total
+	}
+	return counts, nil
+}
+
+type ScoredContact struct {
+	entity.Contact
+	Score float64 `gorm:"column:score"`
+}
+
+func (r *ContactRepository) Search(db *gorm.DB, request *model.SearchContactRequest) ([]ScoredContact, int64, error) {
+	var contacts []ScoredContact
+	query := db.Scopes(r.FilterContact(request), selectScore(request), orderSearch(request))
+
+	if err := query.Offset((request.Page - 1) * request.Size).Limit(request.Size).Find(&contacts).Error; err != nil {
+		return nil, 0, err
+	}
+
+	total, err := r.Count(db, request)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return contacts, total, nil
+}
+
+// FindInBatches hands every contact the request filters for to fn, paging aside, in the order of a
+// search and size contacts at a time. Addresses are only loaded along when withAddresses is set
+func (r *ContactRepository) FindInBatches(db *gorm.DB, request *model.SearchContactRequest, withAddresses bool, size int, fn func(contacts []entity.Contact) error) error {
+	query := db.Scopes(r.FilterContact(request), selectScore(request), orderSearch(request))
+	if withAddresses {
+		query = query.Preload("Addresses", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at, id")
+		})
+	}
+
+	for offset := 0; ; offset += size {
+		var contacts []entity.Contact
+		if err := query.Session(&gorm.Session{}).Offset(offset).Limit(size).Find(&contacts).Error; err != nil {
+			return err
+		}
+
+		if len(contacts) > 0 {
+			if err := fn(contacts); err != nil {
+				return err
+			}
+		}
+
+		if len(contacts) < size {
+			return nil
+		}
+	}
+}
+
+func (r *ContactRepository) Count(db *gorm.DB, request *model.SearchContactRequest) (int64, error) {
+	var total int64 = 0
+	err := db.Model(&entity.Contact{}).Scopes(r.FilterContact(request)).Count(&total).Error
+	return total, err
+}
+
+// SearchCursor returns up to size+1 contacts past the cursor in the direction it points, the
+// extra one tells whether there is more. Without a cursor it starts at the top of the listing
+func (r *ContactRepository) SearchCursor(db *gorm.DB, request *model.SearchContactRequest, sort []string, cursor *model.PageCursor) ([]ScoredContact, error) {
+	backward := cursor != nil && cursor.Backward
+	query := db.Scopes(r.FilterContact(request), selectScore(request))
+
+	if cursor != nil {
+		query = query.Where(keyset(contactSortColumns, sort, cursor.Values, cursor.ID, backward))
+	}
+
+	var contacts []ScoredContact
+	if err := query.Order(keysetOrder(contactSortColumns, sort, backward)).Limit(request.Size + 1).Find(&contacts).Error; err != nil {
+		return nil, err
+	}
+
+	return contacts, nil
+}
+
+func (r *ContactRepository) SortValues(contact *entity.Contact, sort []string) []any {
+	values := make([]any, len(sort))
+	for i, field := range sort {
+		switch strings.TrimPrefix(field, "-") {
+		case "first_name":
+			values[i] = contact.FirstName
+		case "last_name":
+			values[i] = contact.LastName
+		case "email":
+			values[i] = contact.Email
+		case "phone":
+			values[i] = contact.Phone
+		case "created_at":
+			values[i] = contact.CreatedAt
+		case "updated_at":
+			values[i] = contact.UpdatedAt
+		}
+	}
+	return values
+}
+
+func orderSearch(request *model.SearchContactRequest) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		switch q := tsQuery(request.Query); {
+		case len(request.Sort) > 0:
+			return tx.Clauses(orderBy(request.Sort))
+		case request.Fuzzy && request.Query != "":
+			return tx.Order("score DESC, id")
+		case q != "":
+			return tx.Clauses(clause.OrderBy{Expression: clause.Expr{
+				SQL:  "ts_rank(search_vector, to_tsquery('simple', ?)) DESC, id",
+				Vars: []interface{}{q},
+			}})
+		default:
+			return tx.Clauses(orderBy([]string{"created_at"}))
+		}
+	}
+}
+
+func selectScore(request *model.SearchContactRequest) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		if !request.Fuzzy || request.Query == "" {
+			return tx
+		}
+		return tx.Select("*, GREATEST(similarity("+contactFullName+", ?), similarity(coalesce(email, ''), ?)) AS score", request.Query, request.Query)
+	}
+}
+
 func (r *ContactRepository) FilterContact(request *model.SearchContactRequest) func(tx *gorm.DB) *gorm.DB {
 	return func(tx *gorm.DB) *gorm.DB {
 		tx = tx.Where("user_id = ?", request.UserId)
 
+		if request.Fuzzy && request.Query != "" {
+			// % is the pg_trgm similarity operator, it uses pg_trgm.similarity_threshold
+			tx = tx.Where("("+contactFullName+" % ? OR email % ?)", request.Query, request.Query)
+		} else if q := tsQuery(request.Query); q != "" {
+			tx = tx.Where("search_vector @@ to_tsquery('simple', ?)", q)
+		}
+
 		if name := request.Name; name != "" {
 			name = "%" + name + "%"
 			tx = tx.Where("first_name LIKE ? OR last_name LIKE ?", name, name)
@@ -56,6 +289,28 @@ func (r *ContactRepository) FilterContact(request *model.SearchContactRequest) f
 			tx = tx.Where("email LIKE ?", email)
 		}
 
+		if group := request.Group; group != "" {
+			tx = tx.Where("id IN (SELECT contact_id FROM contact_groups WHERE group_id = ?)", group)
+		}
+
 		return tx
 	}
 }
+
+// tsQuery turns free text into a query matching contacts that have every word as a prefix,
+// it is empty when nothing searchable is left. Emails and phones are kept whole as the
+// search vector stores them that way
+func tsQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("@.+-_", r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		if strings.IndexFunc(word, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+			continue
+		}
+		terms = append(terms, "'"+word+"':*")
+	}
+	return strings.Join(terms, " & ")
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ContactRevisionRepository struct {
+	Repository[entity.ContactRevision]
+	Log *logrus.Logger
+}
+
+func NewContactRevisionRepository(log *logrus.Logger) *ContactRevisionRepository {
+	return &ContactRevisionRepository{
+		Log: log,
+	}
+}
+
+func (r *ContactRevisionRepository) FindByIdAndContactId(db *gorm.DB, revision *entity.ContactRevision, id string, contactId string) error {
+	return db.Where("id = ? AND contact_id = ?", id, contactId).Take(revision).Error
+}
+
+// Search returns the revisions of a contact, the latest first. Ids are time ordered so they break
+// ties between revisions made in the same millisecond
+func (r *ContactRevisionRepository) Search(db *gorm.DB, request *model.ListRevisionRequest) ([]entity.ContactRevision, int64, error) {
+	var revisions []entity.ContactRevision
+	if err := db.Where("contact_id = ?", request.ContactId).Order("created_at DESC, id DESC").Offset((request.Page - 1) * request.Size).Limit(request.Size).Find(&revisions).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var total int64 = 0
+	if err := db.Model(&entity.ContactRevision{}).Where("contact_id = ?", request.ContactId).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return revisions, total, nil
+}
+
+func (r *ContactRevisionRepository) MoveAllAddressRevisions(db *gorm.DB, contactIds []string, targetId string) error {
+	return db.Model(&entity.ContactRevision{}).Where("contact_id IN ? AND address_id <> ''", contactIds).Update("contact_id", targetId).Error
+}
+
+func (r *ContactRevisionRepository) DeleteAllByContactId(db *gorm.DB, contactId string) error {
+	return db.Where("contact_id = ?", contactId).Delete(&entity.ContactRevision{}).Error
+}
+
+func (r *ContactRevisionRepository) DeleteAllByUserId(db *gorm.DB, userId string) error {
+	return db.Where("user_id = ?", userId).Delete(&entity.ContactRevision{}).Error
+}
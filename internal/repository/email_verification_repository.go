@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type EmailVerificationRepository struct {
+	Repository[entity.EmailVerification]
+	Log *logrus.Logger
+}
+
+func NewEmailVerificationRepository(log *logrus.Logger) *EmailVerificationRepository {
+	return &EmailVerificationRepository{
+		Log: log,
+	}
+}
+
+func (r *EmailVerificationRepository) FindByToken(db *gorm.DB, emailVerification *entity.EmailVerification, token string) error {
+	return db.Where("token = ?", token).Take(emailVerification).Error
+}
+
+func (r *EmailVerificationRepository) DeleteAllByUserId(db *gorm.DB, userId string) error {
+	return db.Where("user_id = ?", userId).Delete(&entity.EmailVerification{}).Error
+}
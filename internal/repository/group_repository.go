@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GroupRepository struct {
+	Repository[entity.Group]
+	Log *logrus.Logger
+}
+
+func NewGroupRepository(log *logrus.Logger) *GroupRepository {
+	return &GroupRepository{
+		Log: log,
+	}
+}
+
+func (r *GroupRepository) FindByIdAndUserId(db *gorm.DB, group *entity.Group, id string, userId string) error {
+	return db.Where("id = ? AND user_id = ?", id, userId).Take(group).Error
+}
+
+func (r *GroupRepository) FindAllByUserId(db *gorm.DB, userId string) ([]entity.Group, error) {
+	var groups []entity.Group
+	if err := db.Where("user_id = ?", userId).Order("name ASC").Find(&groups).Error; err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+func (r *GroupRepository) CountByUserIdAndName(db *gorm.DB, userId string, name string, excludeId string) (int64, error) {
+	var total int64
+	err := db.Model(&entity.Group{}).Where("user_id = ? AND name = ? AND id <> ?", userId, name, excludeId).Count(&total).Error
+	return total, err
+}
+
+func (r *GroupRepository) DeleteAllByUserId(db *gorm.DB, userId string) error {
+	return db.Where("user_id = ?", userId).Delete(&entity.Group{}).Error
+}
+
+type ContactGroupRepository struct {
+	Repository[entity.ContactGroup]
+	Log *logrus.Logger
+}
+
+func NewContactGroupRepository(log *logrus.Logger) *ContactGroupRepository {
+	return &ContactGroupRepository{
+		Log: log,
+	}
+}
+
+func (r *ContactGroupRepository) AddAll(db *gorm.DB, groupId string, contactIds []string) error {
+	members := make([]entity.ContactGroup, len(contactIds))
+	for i, contactId := range contactIds {
+		members[i] = entity.ContactGroup{ContactId: contactId, GroupId: groupId}
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&members).Error
+}
+
+func (r *ContactGroupRepository) RemoveAll(db *gorm.DB, groupId string, contactIds []string) error {
+	return db.Where("group_id = ? AND contact_id IN ?", groupId, contactIds).Delete(&entity.ContactGroup{}).Error
+}
+
+func (r *ContactGroupRepository) MoveAll(db *gorm.DB, contactIds []string, targetId string) error {
+	var groupIds []string
+	if err := db.Model(&entity.ContactGroup{}).Where("contact_id IN ?", contactIds).Distinct().Pluck("group_id", &groupIds).Error; err != nil {
+		return err
+	}
+
+	if len(groupIds) > 0 {
+		members := make([]entity.ContactGroup, len(groupIds))
+		for i, groupId := range groupIds {
+			members[i] = entity.ContactGroup{ContactId: targetId, GroupId: groupId}
+		}
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&members).Error; err != nil {
+			return err
+		}
+	}
+
+	return db.Where("contact_id IN ?", contactIds).Delete(&entity.ContactGroup{}).Error
+}
+
+func (r *ContactGroupRepository) DeleteAllByGroupId(db *gorm.DB, groupId string) error {
+	return db.Where("group_id = ?", groupId).Delete(&entity.ContactGroup{}).Error
+}
+
+func (r *ContactGroupRepository) DeleteAllByContactId(db *gorm.DB, contactId string) error {
+	return db.Where("contact_id = ?", contactId).Delete(&entity.ContactGroup{}).Error
+}
+
+func (r *ContactGroupRepository) DeleteAllByUserId(db *gorm.DB, userId string) error {
+	groupIds := db.Model(&entity.Group{}).Select("id").Where("user_id = ?", userId)
+	return db.Where("group_id IN (?)", groupIds).Delete(&entity.ContactGroup{}).Error
+}
+
+// CountByGroupIds returns the number of contacts per group, empty groups are left out. Contacts in
+// the trash keep their groups for a restore but are not counted
+func (r *ContactGroupRepository) CountByGroupIds(db *gorm.DB, groupIds []string) (map[string]int64, error) {
+	var rows []struct {
+		GroupId string
+		Total   int64
+	}
+	if err := db.Model(&entity.ContactGroup{}).Select("group_id, COUNT(*) AS total").Joins("JOIN contacts ON contacts.id = contact_groups.contact_id AND contacts.deleted_at = 0").Where("group_id IN ?", groupIds).Group("group_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.GroupId] = row.Total
+	}
+	return counts, nil
+}
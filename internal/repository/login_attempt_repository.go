@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type LoginAttemptRepository struct {
+	Repository[entity.LoginAttempt]
+	Log *logrus.Logger
+}
+
+func NewLoginAttemptRepository(log *logrus.Logger) *LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		Log: log,
+	}
+}
+
+func (r *LoginAttemptRepository) FindOrNew(db *gorm.DB, attempt *entity.LoginAttempt, id string) error {
+	err := db.Where("id = ?", id).Limit(1).Find(attempt).Error
+	attempt.ID = id
+	return err
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MagicLinkRepository struct {
+	Repository[entity.MagicLink]
+	Log *logrus.Logger
+}
+
+func NewMagicLinkRepository(log *logrus.Logger) *MagicLinkRepository {
+	return &MagicLinkRepository{
+		Log: log,
+	}
+}
+
+// DeleteByToken redeems a link by the digest of its token id and loads what was deleted,
+// of two requests racing for the same link only one deletes a row
+func (r *MagicLinkRepository) DeleteByToken(db *gorm.DB, magicLink *entity.MagicLink, token string) (int64, error) {
+	result := db.Clauses(clause.Returning{}).Where("token = ?", token).Delete(magicLink)
+	return result.RowsAffected, result.Error
+}
+
+func (r *MagicLinkRepository) CountByEmailSince(db *gorm.DB, email string, since int64) (int64, error) {
+	var total int64
+	err := db.Model(&entity.MagicLink{}).Where("email = ? AND created_at >= ?", email, since).Count(&total).Error
+	return total, err
+}
+
+func (r *MagicLinkRepository) DeleteAllExpired(db *gorm.DB, before int64) error {
+	return db.Where("expires_at < ?", before).Delete(&entity.MagicLink{}).Error
+}
+
+func (r *MagicLinkRepository) DeleteAllByUserId(db *gorm.DB, userId string) error {
+	return db.Where("user_id = ?", userId).Delete(&entity.MagicLink{}).Error
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type OidcStateRepository struct {
+	Repository[entity.OidcState]
+	Log *logrus.Logger
+}
+
+func NewOidcStateRepository(log *logrus.Logger) *OidcStateRepository {
+	return &OidcStateRepository{
+		Log: log,
+	}
+}
+
+func (r *OidcStateRepository) FindByProviderAndState(db *gorm.DB, oidcState *entity.OidcState, provider string, state string) error {
+	return db.Where("provider = ? AND state = ?", provider, state).Take(oidcState).Error
+}
+
+func (r *OidcStateRepository) DeleteAllExpired(db *gorm.DB, before int64) error {
+	return db.Where("expires_at < ?", before).Delete(&entity.OidcState{}).Error
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type PasswordHistoryRepository struct {
+	Repository[entity.PasswordHistory]
+	Log *logrus.Logger
+}
+
+func NewPasswordHistoryRepository(log *logrus.Logger) *PasswordHistoryRepository {
+	return &PasswordHistoryRepository{
+		Log: log,
+	}
+}
+
+func (r *PasswordHistoryRepository) FindLatestByUserId(db *gorm.DB, userId string, limit int) ([]entity.PasswordHistory, error) {
+	var histories []entity.PasswordHistory
+	if err := db.Where("user_id = ?", userId).Order("created_at DESC, id DESC").Limit(limit).Find(&histories).Error; err != nil {
+		return nil, err
+	}
+	return histories, nil
+}
+
+func (r *PasswordHistoryRepository) DeleteAllExceptLatest(db *gorm.DB, userId string, keep int) error {
+	latest := db.Model(&entity.PasswordHistory{}).Select("id").Where("user_id = ?", userId).Order("created_at DESC, id DESC").Limit(keep)
+	return db.Where("user_id = ? AND id NOT IN (?)", userId, latest).Delete(&entity.PasswordHistory{}).Error
+}
+
+func (r *PasswordHistoryRepository) DeleteAllByUserId(db *gorm.DB, userId string) error {
+	return db.Where("user_id = ?", userId).Delete(&entity.PasswordHistory{}).Error
+}
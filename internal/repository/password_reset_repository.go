@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepository struct {
+	Repository[entity.PasswordReset]
+	Log *logrus.Logger
+}
+
+func NewPasswordResetRepository(log *logrus.Logger) *PasswordResetRepository {
+	return &PasswordResetRepository{
+		Log: log,
+	}
+}
+
+func (r *PasswordResetRepository) FindByToken(db *gorm.DB, passwordReset *entity.PasswordReset, token string) error {
+	return db.Where("token = ?", token).Take(passwordReset).Error
+}
+
+func (r *PasswordResetRepository) DeleteAllByUserId(db *gorm.DB, userId string) error {
+	return db.Where("user_id = ?", userId).Delete(&entity.PasswordReset{}).Error
+}
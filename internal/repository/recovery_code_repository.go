@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeRepository struct {
+	Repository[entity.RecoveryCode]
+	Log *logrus.Logger
+}
+
+func NewRecoveryCodeRepository(log *logrus.Logger) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{
+		Log: log,
+	}
+}
+
+// DeleteByUserIdAndCode uses a code up by its digest, nothing is deleted when it was already used
+func (r *RecoveryCodeRepository) DeleteByUserIdAndCode(db *gorm.DB, userId string, code string) (int64, error) {
+	result := db.Where("user_id = ? AND code = ?", userId, code).Delete(&entity.RecoveryCode{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *RecoveryCodeRepository) DeleteAllByUserId(db *gorm.DB, userId string) error {
+	return db.Where("user_id = ?", userId).Delete(&entity.RecoveryCode{}).Error
+}
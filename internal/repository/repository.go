@@ -1,6 +1,11 @@
 package repository
 
-import "gorm.io/gorm"
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
 
 type Repository[T any] struct {
 	DB *gorm.DB
@@ -27,3 +32,79 @@ func (r *Repository[T]) CountById(db *gorm.DB, id any) (int64, error) {
 func (r *Repository[T]) FindById(db *gorm.DB, entity *T, id any) error {
 	return db.Where("id = ?", id).Take(entity).Error
 }
+
+// Restore takes the entity out of the trash, T has to be soft deleted through a DeletedAt field
+func (r *Repository[T]) Restore(db *gorm.DB, entity *T) error {
+	return db.Unscoped().Model(entity).Update("deleted_at", 0).Error
+}
+
+func (r *Repository[T]) Purge(db *gorm.DB, entity *T) error {
+	return db.Unscoped().Delete(entity).Error
+}
+
+// orderBy sorts by the fields of a sort parameter, a leading - sorts descending. The fields
+// have to be whitelisted by the caller. id always comes last so rows with equal values keep
+// their order between pages
+func orderBy(sort []string) clause.OrderBy {
+	columns := make([]clause.OrderByColumn, 0, len(sort)+1)
+	for _, field := range sort {
+		name, desc := strings.CutPrefix(field, "-")
+		columns = append(columns, clause.OrderByColumn{Column: clause.Column{Name: name}, Desc: desc})
+	}
+	columns = append(columns, clause.OrderByColumn{Column: clause.Column{Name: "id"}})
+	return clause.OrderBy{Columns: columns}
+}
+
+func keysetOrder(columns map[string]string, sort []string, backward bool) string {
+	terms := make([]string, 0, len(sort)+1)
+	for _, field := range sort {
+		name, desc := strings.CutPrefix(field, "-")
+		terms = append(terms, columns[name]+direction(desc != backward))
+	}
+	terms = append(terms, "id"+direction(backward))
+	return strings.Join(terms, ", ")
+}
+
+// keyset selects the rows coming after values and id in the keysetOrder of sort, the row
+// itself is left out. values hold one entry per sort field
+func keyset(columns map[string]string, sort []string, values []any, id string, backward bool) clause.Expr {
+	expressions := make([]string, 0, len(sort)+1)
+	descending := make([]bool, 0, len(sort)+1)
+	for _, field := range sort {
+		name, desc := strings.CutPrefix(field, "-")
+		expressions = append(expressions, columns[name])
+		descending = append(descending, desc)
+	}
+	expressions = append(expressions, "id")
+	descending = append(descending, false)
+	values = append(values[:len(values):len(values)], id)
+
+	// (a > ?) OR (a = ? AND b > ?) OR ... since the directions may differ per column
+	var conditions []string
+	var vars []any
+	for i := range expressions {
+		terms := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			terms = append(terms, expressions[j]+" = ?")
+			vars = append(vars, values[j])
+		}
+
+		operator := " > ?"
+		if descending[i] != backward {
+			operator = " < ?"
+		}
+		terms = append(terms, expressions[i]+operator)
+		vars = append(vars, values[i])
+
+		conditions = append(conditions, "("+strings.Join(terms, " AND ")+")")
+	}
+
+	return clause.Expr{SQL: "(" + strings.Join(conditions, " OR ") + ")", Vars: vars}
+}
+
+func direction(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+	return " ASC"
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type SessionRepository struct {
+	Repository[entity.Session]
+	Log *logrus.Logger
+}
+
+func NewSessionRepository(log *logrus.Logger) *SessionRepository {
+	return &SessionRepository{
+		Log: log,
+	}
+}
+
+func (r *SessionRepository) FindByToken(db *gorm.DB, session *entity.Session, token string) error {
+	return db.Where("token = ?", token).Take(session).Error
+}
+
+func (r *SessionRepository) FindByIdAndUserId(db *gorm.DB, session *entity.Session, id string, userId string) error {
+	return db.Where("id = ? AND user_id = ?", id, userId).Take(session).Error
+}
+
+func (r *SessionRepository) FindWithUser(db *gorm.DB, session *entity.Session, id string, userId string) error {
+	return db.Joins("User").Where("sessions.id = ? AND sessions.user_id = ?", id, userId).Take(session).Error
+}
+
+func (r *SessionRepository) FindAllByUserId(db *gorm.DB, userId string) ([]entity.Session, error) {
+	var sessions []entity.Session
+	if err := db.Where("user_id = ?", userId).Order("last_seen_at DESC").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *SessionRepository) DeleteAllByUserId(db *gorm.DB, userId string) error {
+	return db.Where("user_id = ?", userId).Delete(&entity.Session{}).Error
+}
+
+func (r *SessionRepository) DeleteAllByImpersonatorId(db *gorm.DB, impersonatorId string) error {
+	return db.Where("impersonator_id = ?", impersonatorId).Delete(&entity.Session{}).Error
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type UserIdentityRepository struct {
+	Repository[entity.UserIdentity]
+	Log *logrus.Logger
+}
+
+func NewUserIdentityRepository(log *logrus.Logger) *UserIdentityRepository {
+	return &UserIdentityRepository{
+		Log: log,
+	}
+}
+
+func (r *UserIdentityRepository) FindByProviderAndSubject(db *gorm.DB, identity *entity.UserIdentity, provider string, subject string) error {
+	return db.Where("provider = ? AND subject = ?", provider, subject).Take(identity).Error
+}
+
+func (r *UserIdentityRepository) FindByIdAndUserId(db *gorm.DB, identity *entity.UserIdentity, id string, userId string) error {
+	return db.Where("id = ? AND user_id = ?", id, userId).Take(identity).Error
+}
+
+func (r *UserIdentityRepository) FindAllByUserId(db *gorm.DB, userId string) ([]entity.UserIdentity, error) {
+	var identities []entity.UserIdentity
+	if err := db.Where("user_id = ?", userId).Order("created_at").Find(&identities).Error; err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+func (r *UserIdentityRepository) DeleteAllByUserId(db *gorm.DB, userId string) error {
+	return db.Where("user_id = ?", userId).Delete(&entity.UserIdentity{}).Error
+}
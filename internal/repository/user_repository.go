@@ -2,8 +2,10 @@ package repository
 
 import (
 	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository struct {
@@ -17,6 +19,58 @@ func NewUserRepository(log *logrus.Logger) *UserRepository {
 	}
 }
 
-func (r *UserRepository) FindByToken(db *gorm.DB, user *entity.User, token string) error {
-	return db.Where("token = ?", token).First(user).Error
-}
\ No newline at end of file
+// FindByIdForUpdate locks the row until the transaction ends, for checks that must not run twice at once
+func (r *UserRepository) FindByIdForUpdate(db *gorm.DB, user *entity.User, id string) error {
+	return db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Take(user).Error
+}
+
+func (r *UserRepository) FindByVerifiedEmail(db *gorm.DB, user *entity.User, email string) error {
+	return db.Where("email = ? AND email_verified_at > 0", email).Take(user).Error
+}
+
+func (r *UserRepository) CountByVerifiedEmail(db *gorm.DB, email string) (int64, error) {
+	var total int64
+	err := db.Model(&entity.User{}).Where("email = ? AND email_verified_at > 0", email).Count(&total).Error
+	return total, err
+}
+
+func (r *UserRepository) ReleaseUnverifiedEmail(db *gorm.DB, email string, userId string) error {
+	return db.Model(&entity.User{}).Where("email = ? AND email_verified_at = 0 AND id <> ?", email, userId).Update("email", "").Error
+}
+
+func (r *UserRepository) FindAllDeletable(db *gorm.DB, before int64, limit int) ([]entity.User, error) {
+	var users []entity.User
+	if err := db.Where("delete_at > 0 AND delete_at <= ?", before).Order("delete_at").Limit(limit).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *UserRepository) Search(db *gorm.DB, request *model.SearchUserRequest) ([]entity.User, int64, error) {
+	var users []entity.User
+	if err := db.Scopes(r.FilterUser(request)).Order("created_at DESC").Offset((request.Page - 1) * request.Size).Limit(request.Size).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var total int64 = 0
+	if err := db.Model(&entity.User{}).Scopes(r.FilterUser(request)).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+func (r *UserRepository) FilterUser(request *model.SearchUserRequest) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		if key := request.Key; key != "" {
+			key = "%" + key + "%"
+			tx = tx.Where("id LIKE ? OR name LIKE ? OR email LIKE ?", key, key, key)
+		}
+
+		if role := request.Role; role != "" {
+			tx = tx.Where("role = ?", role)
+		}
+
+		return tx
+	}
+}
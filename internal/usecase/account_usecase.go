@@ -0,0 +1,264 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/model/converter"
+	"github.com/iyasz/golang-clean-architecture/internal/repository"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type AccountConfig struct {
+	// DeletionGrace is how long a deleted account can be restored, zero deletes at once
+	DeletionGrace time.Duration
+}
+
+type AccountUseCase struct {
+	DB                          *gorm.DB
+	Log                         *logrus.Logger
+	Validate                    *validator.Validate
+	Hasher                      helper.PasswordHasher
+	Config                      *AccountConfig
+	LoginThrottle               *LoginThrottle
+	UserRepository              *repository.UserRepository
+	SessionRepository           *repository.SessionRepository
+	ContactRepository           *repository.ContactRepository
+	AddressRepository           *repository.AddressRepository
+	GroupRepository             *repository.GroupRepository
+	ContactGroupRepository      *repository.ContactGroupRepository
+	ContactRevisionRepository   *repository.ContactRevisionRepository
+	ApiKeyRepository            *repository.ApiKeyRepository
+	RecoveryCodeRepository      *repository.RecoveryCodeRepository
+	PasswordResetRepository     *repository.PasswordResetRepository
+	EmailVerificationRepository *repository.EmailVerificationRepository
+	PasswordHistoryRepository   *repository.PasswordHistoryRepository
+	UserIdentityRepository      *repository.UserIdentityRepository
+	MagicLinkRepository         *repository.MagicLinkRepository
+}
+
+func NewAccountUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate, hasher helper.PasswordHasher, config *AccountConfig,
+	loginThrottle *LoginThrottle, userRepository *repository.UserRepository, sessionRepository *repository.SessionRepository,
+	contactRepository *repository.ContactRepository, addressRepository *repository.AddressRepository, groupRepository *repository.GroupRepository,
+	contactGroupRepository *repository.ContactGroupRepository, contactRevisionRepository *repository.ContactRevisionRepository,
+	apiKeyRepository *repository.ApiKeyRepository, recoveryCodeRepository *repository.RecoveryCodeRepository,
+	passwordResetRepository *repository.PasswordResetRepository, emailVerificationRepository *repository.EmailVerificationRepository,
+	passwordHistoryRepository *repository.PasswordHistoryRepository, userIdentityRepository *repository.UserIdentityRepository,
+	magicLinkRepository *repository.MagicLinkRepository) *AccountUseCase {
+	return &AccountUseCase{
+		DB:                          db,
+		Log:                         logger,
+		Validate:                    validate,
+		Hasher:                      hasher,
+		Config:                      config,
+		LoginThrottle:               loginThrottle,
+		UserRepository:              userRepository,
+		SessionRepository:           sessionRepository,
+		ContactRepository:           contactRepository,
+		AddressRepository:           addressRepository,
+		GroupRepository:             groupRepository,
+		ContactGroupRepository:      contactGroupRepository,
+		ContactRevisionRepository:   contactRevisionRepository,
+		ApiKeyRepository:            apiKeyRepository,
+		RecoveryCodeRepository:      recoveryCodeRepository,
+		PasswordResetRepository:     passwordResetRepository,
+		EmailVerificationRepository: emailVerificationRepository,
+		PasswordHistoryRepository:   passwordHistoryRepository,
+		UserIdentityRepository:      userIdentityRepository,
+		MagicLinkRepository:         magicLinkRepository,
+	}
+}
+
+func (c *AccountUseCase) Delete(ctx context.Context, request *model.DeleteUserRequest) (*model.UserResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, helper.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, helper.ErrNotFound
+	}
+
+	// accounts created through a provider or a magic link have to set a password first
+	if user.Password == "" {
+		c.Log.Warnf("User %s has no password to confirm the deletion with", user.ID)
+		return nil, helper.ErrPasswordNotSet
+	}
+
+	userAttempt, ipAttempt, err := c.LoginThrottle.Find(tx, request.ID, request.IpAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.LoginThrottle.Check(userAttempt, ipAttempt); err != nil {
+		c.Log.Warnf("Delete throttled for user %s from %s : %+v", request.ID, request.IpAddress, err)
+		return nil, err
+	}
+
+	if ok, err := c.Hasher.Verify(user.Password, request.Password); !ok {
+		c.Log.Warnf("Failed to verify user password : %+v", err)
+		return nil, c.LoginThrottle.Failed(tx, userAttempt, ipAttempt)
+	}
+
+	user.DeleteAt = time.Now().Add(c.Config.DeletionGrace).UnixMilli()
+
+	if c.Config.DeletionGrace > 0 {
+		if err := c.UserRepository.Update(tx, user); err != nil {
+			c.Log.Warnf("Failed save user : %+v", err)
+			return nil, helper.ErrInternalServerError
+		}
+
+		if err := c.SessionRepository.DeleteAllByUserId(tx, user.ID); err != nil {
+			c.Log.Warnf("Failed delete sessions : %+v", err)
+			return nil, helper.ErrInternalServerError
+		}
+
+		if err := c.SessionRepository.DeleteAllByImpersonatorId(tx, user.ID); err != nil {
+			c.Log.Warnf("Failed delete impersonation sessions : %+v", err)
+			return nil, helper.ErrInternalServerError
+		}
+	} else if err := c.purge(tx, user); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	return converter.UserToResponse(user), nil
+}
+
+func (c *AccountUseCase) Restore(ctx context.Context, request *model.RestoreUserRequest) (*model.UserResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, helper.ErrBadRequest
+	}
+
+	userAttempt, ipAttempt, err := c.LoginThrottle.Find(tx, request.ID, request.IpAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.LoginThrottle.Check(userAttempt, ipAttempt); err != nil {
+		c.Log.Warnf("Restore throttled for user %s from %s : %+v", request.ID, request.IpAddress, err)
+		return nil, err
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, c.LoginThrottle.Failed(tx, userAttempt, ipAttempt)
+	}
+
+	if ok, err := c.Hasher.Verify(user.Password, request.Password); !ok {
+		c.Log.Warnf("Failed to verify user password : %+v", err)
+		return nil, c.LoginThrottle.Failed(tx, userAttempt, ipAttempt)
+	}
+
+	if user.DeleteAt == 0 {
+		c.Log.Warnf("User %s is not pending deletion", user.ID)
+		return nil, helper.ErrBadRequest
+	}
+
+	// the purge job may not have run yet, the account is gone for the client anyway
+	if user.DeleteAt <= time.Now().UnixMilli() {
+		c.Log.Warnf("User %s grace period is over", user.ID)
+		return nil, helper.ErrGone
+	}
+
+	user.DeleteAt = 0
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	return converter.UserToResponse(user), nil
+}
+
+// PurgeDeleted removes a batch of accounts whose grace period is over, each in its own
+// transaction so one failure does not hold back the others, the failures are returned together
+func (c *AccountUseCase) PurgeDeleted(ctx context.Context) (int, error) {
+	users, err := c.UserRepository.FindAllDeletable(c.DB.WithContext(ctx), time.Now().UnixMilli(), 100)
+	if err != nil {
+		c.Log.Warnf("Failed find deletable users : %+v", err)
+		return 0, helper.ErrInternalServerError
+	}
+
+	purged := 0
+	var errs []error
+	for _, user := range users {
+		err := c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return c.purge(tx, &user)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("purge user %s : %w", user.ID, err))
+			continue
+		}
+		purged++
+	}
+
+	return purged, errors.Join(errs...)
+}
+
+// purge deletes the user and everything it owns, children first since the foreign keys do not cascade
+func (c *AccountUseCase) purge(tx *gorm.DB, user *entity.User) error {
+	steps := []struct {
+		name   string
+		delete func(db *gorm.DB, userId string) error
+	}{
+		{"addresses", c.AddressRepository.DeleteAllByUserId},
+		{"group members", c.ContactGroupRepository.DeleteAllByUserId},
+		{"groups", c.GroupRepository.DeleteAllByUserId},
+		{"contact revisions", c.ContactRevisionRepository.DeleteAllByUserId},
+		{"contacts", c.ContactRepository.DeleteAllByUserId},
+		{"sessions", c.SessionRepository.DeleteAllByUserId},
+		{"impersonation sessions", c.SessionRepository.DeleteAllByImpersonatorId},
+		{"api keys", c.ApiKeyRepository.DeleteAllByUserId},
+		{"recovery codes", c.RecoveryCodeRepository.DeleteAllByUserId},
+		{"password resets", c.PasswordResetRepository.DeleteAllByUserId},
+		{"email verifications", c.EmailVerificationRepository.DeleteAllByUserId},
+		{"password history", c.PasswordHistoryRepository.DeleteAllByUserId},
+		{"identities", c.UserIdentityRepository.DeleteAllByUserId},
+		{"magic links", c.MagicLinkRepository.DeleteAllByUserId},
+	}
+
+	for _, step := range steps {
+		if err := step.delete(tx, user.ID); err != nil {
+			c.Log.Warnf("Failed delete %s of user %s : %+v", step.name, user.ID, err)
+			return helper.ErrInternalServerError
+		}
+	}
+
+	if err := c.LoginThrottle.LoginAttemptRepository.Delete(tx, &entity.LoginAttempt{ID: "user:" + user.ID}); err != nil {
+		c.Log.Warnf("Failed delete login attempts of user %s : %+v", user.ID, err)
+		return helper.ErrInternalServerError
+	}
+
+	if err := c.UserRepository.Delete(tx, user); err != nil {
+		c.Log.Warnf("Failed delete user %s : %+v", user.ID, err)
+		return helper.ErrInternalServerError
+	}
+
+	return nil
+}
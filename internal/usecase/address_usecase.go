@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"maps"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
@@ -20,16 +21,18 @@ type AddressUseCase struct {
 	Validate          *validator.Validate
 	AddressRepository *repository.AddressRepository
 	ContactRepository *repository.ContactRepository
+	ContactRevisionRepository *repository.ContactRevisionRepository
 }
 
 func NewAddressUseCase(db *gorm.DB, log *logrus.Logger, validate *validator.Validate, addressRepository *repository.AddressRepository, 
-	contactRepository *repository.ContactRepository) *AddressUseCase { 
+	contactRepository *repository.ContactRepository, contactRevisionRepository *repository.ContactRevisionRepository) *AddressUseCase { 
 		return &AddressUseCase{
 			DB: db,
 			Log: log,
 			Validate: validate,
 			AddressRepository: addressRepository,
 			ContactRepository: contactRepository,
+			ContactRevisionRepository: contactRevisionRepository,
 		}
 }
 
@@ -63,6 +66,12 @@ func (c *AddressUseCase) Create(ctx context.Context, request *model.CreateAddres
 		return nil, helper.ErrInternalServerError
 	}
 
+	revision := newRevision(request.UserId, request.ImpersonatorId, model.RevisionCreate, contact.ID, address.ID, nil, addressFields(address))
+	if err := c.ContactRevisionRepository.Create(tx, revision); err != nil {
+		c.Log.WithError(err).Error("failed to create revision")
+		return nil, helper.ErrInternalServerError
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		c.Log.WithError(err).Error("failed to commit transaction")
 		return nil, helper.ErrInternalServerError
@@ -92,6 +101,7 @@ func (c *AddressUseCase) Update(ctx context.Context, request *model.UpdateAddres
 		return nil, helper.ErrNotFound
 	}
 
+	before := addressFields(address)
 	address.Street = request.Street
 	address.City = request.City
 	address.Province = request.Province
@@ -103,6 +113,14 @@ func (c *AddressUseCase) Update(ctx context.Context, request *model.UpdateAddres
 		return nil, helper.ErrInternalServerError
 	}
 
+	if after := addressFields(address); !maps.Equal(before, after) {
+		revision := newRevision(request.UserId, request.ImpersonatorId, model.RevisionUpdate, contact.ID, address.ID, before, after)
+		if err := c.ContactRevisionRepository.Create(tx, revision); err != nil {
+			c.Log.WithError(err).Error("failed to create revision")
+			return nil, helper.ErrInternalServerError
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		c.Log.WithError(err).Error("failed to commit transaction")
 		return nil, helper.ErrInternalServerError
@@ -156,6 +174,12 @@ func (c *AddressUseCase) Delete(ctx context.Context, request *model.DeleteAddres
 		return helper.ErrInternalServerError
 	}
 
+	revision := newRevision(request.UserId, request.ImpersonatorId, model.RevisionDelete, contact.ID, address.ID, addressFields(address), nil)
+	if err := c.ContactRevisionRepository.Create(tx, revision); err != nil {
+		c.Log.WithError(err).Error("failed to create revision")
+		return helper.ErrInternalServerError
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		c.Log.WithError(err).Error("failed to commit transaction")
 		return helper.ErrInternalServerError
@@ -174,7 +198,12 @@ func (c *AddressUseCase) List(ctx context.Context, request *model.ListAddressReq
 		return nil, helper.ErrNotFound
 	}
 
-	addresses, err := c.AddressRepository.FindAllByContactId(tx, contact.ID)
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Error("failed to validate request body")
+		return nil, helper.ErrBadRequest
+	}
+
+	addresses, err := c.AddressRepository.FindAllByContactId(tx, contact.ID, request.Sort)
 	if err != nil {
 		c.Log.WithError(err).Error("failed to find addresses")
 		return nil, helper.ErrInternalServerError
@@ -191,4 +220,79 @@ func (c *AddressUseCase) List(ctx context.Context, request *model.ListAddressReq
 	}
 
 	return responses, nil
-}
\ No newline at end of file
+}
+
+func (c *AddressUseCase) Trash(ctx context.Context, request *model.ListTrashAddressRequest) ([]model.AddressResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Error("failed to validate request body")
+		return nil, helper.ErrBadRequest
+	}
+
+	contact := new(entity.Contact)
+	if err := c.ContactRepository.FindByIdAndUserId(tx, contact, request.ContactId, request.UserId); err != nil {
+		c.Log.WithError(err).Error("failed to find contact")
+		return nil, helper.ErrNotFound
+	}
+
+	addresses, err := c.AddressRepository.FindAllTrashedByContactId(tx, contact.ID)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to find trashed addresses")
+		return nil, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).Error("failed to commit transaction")
+		return nil, helper.ErrInternalServerError
+	}
+
+	responses := make([]model.AddressResponse, len(addresses))
+	for i, address := range addresses {
+		responses[i] = *converter.AddressToResponse(&address)
+	}
+
+	return responses, nil
+}
+
+// Restore takes an address out of the trash, its contact has to be restored first when it is in the trash too
+func (c *AddressUseCase) Restore(ctx context.Context, request *model.RestoreAddressRequest) (*model.AddressResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Error("failed to validate request body")
+		return nil, helper.ErrBadRequest
+	}
+
+	contact := new(entity.Contact)
+	if err := c.ContactRepository.FindByIdAndUserId(tx, contact, request.ContactId, request.UserId); err != nil {
+		c.Log.WithError(err).Error("failed to find contact")
+		return nil, helper.ErrNotFound
+	}
+
+	address := new(entity.Address)
+	if err := c.AddressRepository.FindTrashedByIdAndContactId(tx, address, request.ID, contact.ID); err != nil {
+		c.Log.WithError(err).Error("failed to find trashed address")
+		return nil, helper.ErrNotFound
+	}
+
+	if err := c.AddressRepository.Restore(tx, address); err != nil {
+		c.Log.WithError(err).Error("failed to restore address")
+		return nil, helper.ErrInternalServerError
+	}
+
+	revision := newRevision(request.UserId, request.ImpersonatorId, model.RevisionRestore, contact.ID, address.ID, addressFields(address), addressFields(address))
+	if err := c.ContactRevisionRepository.Create(tx, revision); err != nil {
+		c.Log.WithError(err).Error("failed to create revision")
+		return nil, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).Error("failed to commit transaction")
+		return nil, helper.ErrInternalServerError
+	}
+
+	return converter.AddressToResponse(address), nil
+}
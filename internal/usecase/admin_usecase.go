@@ -0,0 +1,246 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/model/converter"
+	"github.com/iyasz/golang-clean-architecture/internal/repository"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type AdminUseCase struct {
+	DB                *gorm.DB
+	Log               *logrus.Logger
+	Validate          *validator.Validate
+	UserRepository    *repository.UserRepository
+	ContactRepository *repository.ContactRepository
+	SessionRepository *repository.SessionRepository
+}
+
+func NewAdminUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate, userRepository *repository.UserRepository,
+	contactRepository *repository.ContactRepository, sessionRepository *repository.SessionRepository) *AdminUseCase {
+	return &AdminUseCase{
+		DB:                db,
+		Log:               logger,
+		Validate:          validate,
+		UserRepository:    userRepository,
+		ContactRepository: contactRepository,
+		SessionRepository: sessionRepository,
+	}
+}
+
+func (c *AdminUseCase) Search(ctx context.Context, request *model.SearchUserRequest) ([]model.AdminUserResponse, int64, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, 0, helper.ErrBadRequest
+	}
+
+	users, total, err := c.UserRepository.Search(tx, request)
+	if err != nil {
+		c.Log.Warnf("Failed search users : %+v", err)
+		return nil, 0, helper.ErrInternalServerError
+	}
+
+	userIds := make([]string, len(users))
+	for i, user := range users {
+		userIds[i] = user.ID
+	}
+
+	counts := map[string]int64{}
+	if len(userIds) > 0 {
+		counts, err = c.ContactRepository.CountByUserIds(tx, userIds)
+		if err != nil {
+			c.Log.Warnf("Failed count contacts : %+v", err)
+			return nil, 0, helper.ErrInternalServerError
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, 0, helper.ErrInternalServerError
+	}
+
+	responses := make([]model.AdminUserResponse, len(users))
+	for i, user := range users {
+		responses[i] = *converter.UserToAdminResponse(&user, counts[user.ID])
+	}
+
+	return responses, total, nil
+}
+
+func (c *AdminUseCase) Get(ctx context.Context, request *model.GetAdminUserRequest) (*model.AdminUserResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, helper.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, helper.ErrNotFound
+	}
+
+	response, err := c.userResponse(tx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	return response, nil
+}
+
+// UpdateRole applies from the next request, admins cannot demote themselves so there is always one left
+func (c *AdminUseCase) UpdateRole(ctx context.Context, request *model.UpdateUserRoleRequest) (*model.AdminUserResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, helper.ErrBadRequest
+	}
+
+	if request.ID == request.AdminId {
+		c.Log.Warnf("Admin %s tried to change their own role", request.AdminId)
+		return nil, helper.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, helper.ErrNotFound
+	}
+
+	user.Role = request.Role
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	if user.Role != model.RoleAdmin {
+		if err := c.SessionRepository.DeleteAllByImpersonatorId(tx, user.ID); err != nil {
+			c.Log.Warnf("Failed delete impersonation sessions : %+v", err)
+			return nil, helper.ErrInternalServerError
+		}
+	}
+
+	response, err := c.userResponse(tx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	return response, nil
+}
+
+func (c *AdminUseCase) Disable(ctx context.Context, request *model.DisableUserRequest) (*model.AdminUserResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, helper.ErrBadRequest
+	}
+
+	if request.ID == request.AdminId {
+		c.Log.Warnf("Admin %s tried to disable their own account", request.AdminId)
+		return nil, helper.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, helper.ErrNotFound
+	}
+
+	user.Disabled = request.Disabled
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	if user.Disabled {
+		if err := c.SessionRepository.DeleteAllByUserId(tx, user.ID); err != nil {
+			c.Log.Warnf("Failed delete sessions : %+v", err)
+			return nil, helper.ErrInternalServerError
+		}
+
+		if err := c.SessionRepository.DeleteAllByImpersonatorId(tx, user.ID); err != nil {
+			c.Log.Warnf("Failed delete impersonation sessions : %+v", err)
+			return nil, helper.ErrInternalServerError
+		}
+	}
+
+	response, err := c.userResponse(tx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	return response, nil
+}
+
+func (c *AdminUseCase) Logout(ctx context.Context, request *model.LogoutUserSessionsRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return helper.ErrBadRequest
+	}
+
+	total, err := c.UserRepository.CountById(tx, request.ID)
+	if err != nil {
+		c.Log.Warnf("Failed count user from database : %+v", err)
+		return helper.ErrInternalServerError
+	}
+
+	if total == 0 {
+		c.Log.Warnf("User %s not found", request.ID)
+		return helper.ErrNotFound
+	}
+
+	if err := c.SessionRepository.DeleteAllByUserId(tx, request.ID); err != nil {
+		c.Log.Warnf("Failed delete sessions : %+v", err)
+		return helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return helper.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (c *AdminUseCase) userResponse(tx *gorm.DB, user *entity.User) (*model.AdminUserResponse, error) {
+	counts, err := c.ContactRepository.CountByUserIds(tx, []string{user.ID})
+	if err != nil {
+		c.Log.Warnf("Failed count contacts : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	return converter.UserToAdminResponse(user, counts[user.ID]), nil
+}
@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/model/converter"
+	"github.com/iyasz/golang-clean-architecture/internal/repository"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	apiKeyPrefixLength = 10
+	// apiKeyTouchInterval limits how often last_used_at is written for a busy key
+	apiKeyTouchInterval = time.Minute
+)
+
+type ApiKeyUseCase struct {
+	DB               *gorm.DB
+	Log              *logrus.Logger
+	Validate         *validator.Validate
+	UserRepository   *repository.UserRepository
+	ApiKeyRepository *repository.ApiKeyRepository
+}
+
+func NewApiKeyUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate, userRepository *repository.UserRepository,
+	apiKeyRepository *repository.ApiKeyRepository) *ApiKeyUseCase {
+	return &ApiKeyUseCase{
+		DB:               db,
+		Log:              logger,
+		Validate:         validate,
+		UserRepository:   userRepository,
+		ApiKeyRepository: apiKeyRepository,
+	}
+}
+
+func (c *ApiKeyUseCase) Verify(ctx context.Context, request *model.VerifyUserRequest) (*model.Auth, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, helper.ErrBadRequest
+	}
+
+	apiKey := new(entity.ApiKey)
+	if err := c.ApiKeyRepository.FindByToken(tx, apiKey, helper.HashToken(request.Token)); err != nil {
+		c.Log.Warnf("Failed find api key by token : %+v", err)
+		return nil, helper.ErrUnauthorized
+	}
+
+	// keys outlive sessions, so a disabled account has to be checked on every request
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, apiKey.UserId); err != nil || user.Disabled || user.DeleteAt != 0 {
+		c.Log.Warnf("Api key owner %s not found, disabled or pending deletion : %+v", apiKey.UserId, err)
+		return nil, helper.ErrUnauthorized
+	}
+
+	now := time.Now()
+	if now.Sub(time.UnixMilli(apiKey.LastUsedAt)) > apiKeyTouchInterval {
+		apiKey.LastUsedAt = now.UnixMilli()
+		if err := c.ApiKeyRepository.Update(tx, apiKey); err != nil {
+			c.Log.Warnf("Failed save api key : %+v", err)
+			return nil, helper.ErrInternalServerError
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	return &model.Auth{ID: apiKey.UserId, ApiKeyId: apiKey.ID, Scopes: strings.Fields(apiKey.Scopes)}, nil
+}
+
+// Create mints a key, the response is the only place the full key ever appears
+func (c *ApiKeyUseCase) Create(ctx context.Context, request *model.CreateApiKeyRequest) (*model.ApiKeyResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, helper.ErrBadRequest
+	}
+
+	key, err := helper.GenerateApiKey()
+	if err != nil {
+		c.Log.Warnf("Failed generate api key : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	apiKey := &entity.ApiKey{
+		ID:     uuid.New().String(),
+		UserId: request.UserId,
+		Name:   request.Name,
+		Prefix: key[:apiKeyPrefixLength],
+		Token:  helper.HashToken(key),
+		Scopes: strings.Join(request.Scopes, " "),
+	}
+
+	if err := c.ApiKeyRepository.Create(tx, apiKey); err != nil {
+		c.Log.Warnf("Failed create api key : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	response := converter.ApiKeyToResponse(apiKey)
+	response.Key = key
+	return response, nil
+}
+
+func (c *ApiKeyUseCase) List(ctx context.Context, request *model.ListApiKeyRequest) ([]model.ApiKeyResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, helper.ErrBadRequest
+	}
+
+	apiKeys, err := c.ApiKeyRepository.FindAllByUserId(tx, request.UserId)
+	if err != nil {
+		c.Log.Warnf("Failed find api keys : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	responses := make([]model.ApiKeyResponse, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		responses[i] = *converter.ApiKeyToResponse(&apiKey)
+	}
+
+	return responses, nil
+}
+
+func (c *ApiKeyUseCase) Delete(ctx context.Context, request *model.DeleteApiKeyRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return helper.ErrBadRequest
+	}
+
+	apiKey := new(entity.ApiKey)
+	if err := c.ApiKeyRepository.FindByIdAndUserId(tx, apiKey, request.ID, request.UserId); err != nil {
+		c.Log.Warnf("Failed find api key : %+v", err)
+		return helper.ErrNotFound
+	}
+
+	if err := c.ApiKeyRepository.Delete(tx, apiKey); err != nil {
+		c.Log.Warnf("Failed delete api key : %+v", err)
+		return helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return helper.ErrInternalServerError
+	}
+
+	return nil
+}
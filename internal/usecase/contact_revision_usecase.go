@@ -0,0 +1,234 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/model/converter"
+	"github.com/iyasz/golang-clean-architecture/internal/repository"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ContactRevisionUseCase struct {
+	DB                        *gorm.DB
+	Log                       *logrus.Logger
+	Validate                  *validator.Validate
+	ContactRepository         *repository.ContactRepository
+	AddressRepository         *repository.AddressRepository
+	ContactRevisionRepository *repository.ContactRevisionRepository
+}
+
+func NewContactRevisionUseCase(db *gorm.DB, log *logrus.Logger, validate *validator.Validate, contactRepository *repository.ContactRepository,
+	addressRepository *repository.AddressRepository, contactRevisionRepository *repository.ContactRevisionRepository) *ContactRevisionUseCase {
+	return &ContactRevisionUseCase{
+		DB:                        db,
+		Log:                       log,
+		Validate:                  validate,
+		ContactRepository:         contactRepository,
+		AddressRepository:         addressRepository,
+		ContactRevisionRepository: contactRevisionRepository,
+	}
+}
+
+func (c *ContactRevisionUseCase) List(ctx context.Context, request *model.ListRevisionRequest) ([]model.RevisionResponse, int64, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Error("failed to validate request body")
+		return nil, 0, helper.ErrBadRequest
+	}
+
+	contact := new(entity.Contact)
+	if err := c.ContactRepository.FindByIdAndUserId(tx, contact, request.ContactId, request.UserId); err != nil {
+		c.Log.WithError(err).Error("failed to find contact")
+		return nil, 0, helper.ErrNotFound
+	}
+
+	revisions, total, err := c.ContactRevisionRepository.Search(tx, request)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to find revisions")
+		return nil, 0, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).Error("failed to commit transaction")
+		return nil, 0, helper.ErrInternalServerError
+	}
+
+	responses := make([]model.RevisionResponse, len(revisions))
+	for i, revision := range revisions {
+		responses[i] = *converter.RevisionToResponse(&revision)
+	}
+
+	return responses, total, nil
+}
+
+func (c *ContactRevisionUseCase) Revert(ctx context.Context, request *model.RevertRevisionRequest) (*model.RevisionResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Error("failed to validate request body")
+		return nil, helper.ErrBadRequest
+	}
+
+	contact := new(entity.Contact)
+	if err := c.ContactRepository.FindByIdAndUserId(tx, contact, request.ContactId, request.UserId); err != nil {
+		c.Log.WithError(err).Error("failed to find contact")
+		return nil, helper.ErrNotFound
+	}
+
+	revision := new(entity.ContactRevision)
+	if err := c.ContactRevisionRepository.FindByIdAndContactId(tx, revision, request.ID, contact.ID); err != nil {
+		c.Log.WithError(err).Error("failed to find revision")
+		return nil, helper.ErrNotFound
+	}
+
+	snapshot := make(map[string]string)
+	if err := json.Unmarshal([]byte(revision.Snapshot), &snapshot); err != nil {
+		c.Log.WithError(err).Error("failed to decode revision snapshot")
+		return nil, helper.ErrInternalServerError
+	}
+
+	var reverted *entity.ContactRevision
+	if revision.AddressId == "" {
+		before := contactFields(contact)
+		contact.FirstName = snapshot["first_name"]
+		contact.LastName = snapshot["last_name"]
+		contact.Email = snapshot["email"]
+		contact.Phone = snapshot["phone"]
+
+		if err := c.ContactRepository.Update(tx, contact); err != nil {
+			c.Log.WithError(err).Error("failed to revert contact")
+			return nil, helper.ErrInternalServerError
+		}
+		reverted = newRevision(request.UserId, request.ImpersonatorId, model.RevisionRevert, contact.ID, "", before, contactFields(contact))
+	} else {
+		if !request.AllowAddresses {
+			c.Log.Warnf("Api key without the addresses scope reverting address %s", revision.AddressId)
+			return nil, helper.ErrForbidden
+		}
+
+		address, before, err := c.revertAddress(tx, contact, revision.AddressId, snapshot)
+		if err != nil {
+			return nil, err
+		}
+		reverted = newRevision(request.UserId, request.ImpersonatorId, model.RevisionRevert, contact.ID, address.ID, before, addressFields(address))
+	}
+
+	if err := c.ContactRevisionRepository.Create(tx, reverted); err != nil {
+		c.Log.WithError(err).Error("failed to create revision")
+		return nil, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).Error("failed to commit transaction")
+		return nil, helper.ErrInternalServerError
+	}
+
+	return converter.RevisionToResponse(reverted), nil
+}
+
+func (c *ContactRevisionUseCase) revertAddress(tx *gorm.DB, contact *entity.Contact, addressId string, snapshot map[string]string) (*entity.Address, map[string]string, error) {
+	address := new(entity.Address)
+	err := c.AddressRepository.FindAnyByIdAndContactId(tx, address, addressId, contact.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.Log.WithError(err).Error("failed to find address")
+		return nil, nil, helper.ErrInternalServerError
+	}
+
+	exists := err == nil
+	var before map[string]string
+	if exists && address.DeletedAt == 0 {
+		before = addressFields(address)
+	}
+
+	if exists && address.DeletedAt != 0 {
+		if err := c.AddressRepository.Restore(tx, address); err != nil {
+			c.Log.WithError(err).Error("failed to restore address")
+			return nil, nil, helper.ErrInternalServerError
+		}
+		address.DeletedAt = 0
+	}
+
+	address.ID = addressId
+	address.ContactId = contact.ID
+	address.Street = snapshot["street"]
+	address.City = snapshot["city"]
+	address.Province = snapshot["province"]
+	address.PostalCode = snapshot["postal_code"]
+	address.Country = snapshot["country"]
+
+	save := c.AddressRepository.Update
+	if !exists {
+		save = c.AddressRepository.Create
+	}
+	if err := save(tx, address); err != nil {
+		c.Log.WithError(err).Error("failed to revert address")
+		return nil, nil, helper.ErrInternalServerError
+	}
+
+	return address, before, nil
+}
+
+func contactFields(contact *entity.Contact) map[string]string {
+	return map[string]string{
+		"first_name": contact.FirstName,
+		"last_name":  contact.LastName,
+		"email":      contact.Email,
+		"phone":      contact.Phone,
+	}
+}
+
+func addressFields(address *entity.Address) map[string]string {
+	return map[string]string{
+		"street":      address.Street,
+		"city":        address.City,
+		"province":    address.Province,
+		"postal_code": address.PostalCode,
+		"country":     address.Country,
+	}
+}
+
+// newRevision records the fields that differ between before and after, before is nil for a
+// create and after for a delete. The snapshot is after, or before when nothing is left
+func newRevision(userId string, impersonatorId string, action string, contactId string, addressId string, before map[string]string,
+	after map[string]string) *entity.ContactRevision {
+	changes := make(map[string]model.RevisionChange)
+	for field, value := range after {
+		if before[field] != value {
+			changes[field] = model.RevisionChange{Old: before[field], New: value}
+		}
+	}
+	for field, value := range before {
+		if _, ok := after[field]; !ok && value != "" {
+			changes[field] = model.RevisionChange{Old: value}
+		}
+	}
+
+	snapshot := after
+	if snapshot == nil {
+		snapshot = before
+	}
+
+	changesJson, _ := json.Marshal(changes)
+	snapshotJson, _ := json.Marshal(snapshot)
+	return &entity.ContactRevision{
+		ID:             uuid.Must(uuid.NewV7()).String(),
+		ContactId:      contactId,
+		AddressId:      addressId,
+		UserId:         userId,
+		ImpersonatorId: impersonatorId,
+		Action:         action,
+		Changes:        string(changesJson),
+		Snapshot:       string(snapshotJson),
+	}
+}
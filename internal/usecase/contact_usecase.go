@@ -1,8 +1,19 @@
 package usecase
 
 import (
+	"cmp"
 	"context"
-
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-vcard"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/iyasz/golang-clean-architecture/internal/entity"
@@ -14,19 +25,39 @@ import (
 	"gorm.io/gorm"
 )
 
+const exportBatchSize = 500
+
+// duplicatePairLimit bounds the pairs clustered in memory on every page of duplicates, a user with
+// more look alike contacts sees the rest once the first ones are merged
+const duplicatePairLimit = 1000
+
+type ContactConfig struct {
+	TrashRetention time.Duration
+}
+
 type ContactUseCase struct {
 	DB                *gorm.DB
 	Log               *logrus.Logger
 	Validate          *validator.Validate
+	Config            *ContactConfig
 	ContactRepository *repository.ContactRepository
+	AddressRepository *repository.AddressRepository
+	ContactGroupRepository *repository.ContactGroupRepository
+	ContactRevisionRepository *repository.ContactRevisionRepository
 }
 
-func NewContactUseCase(db *gorm.DB, log *logrus.Logger, validate *validator.Validate, contactRepository *repository.ContactRepository) *ContactUseCase{
+func NewContactUseCase(db *gorm.DB, log *logrus.Logger, validate *validator.Validate, config *ContactConfig, contactRepository *repository.ContactRepository,
+	addressRepository *repository.AddressRepository, contactGroupRepository *repository.ContactGroupRepository,
+	contactRevisionRepository *repository.ContactRevisionRepository) *ContactUseCase{
 	return &ContactUseCase{
 		DB: db,
 		Log: log,
 		Validate: validate,
+		Config: config,
 		ContactRepository: contactRepository,
+		AddressRepository: addressRepository,
+		ContactGroupRepository: contactGroupRepository,
+		ContactRevisionRepository: contactRevisionRepository,
 	}
 }
 
@@ -53,6 +84,12 @@ func (c *ContactUseCase) Create(ctx context.Context, request *model.CreateContac
 		return nil, helper.ErrInternalServerError
 	}
 
+	revision := newRevision(request.UserId, request.ImpersonatorId, model.RevisionCreate, contact.ID, "", nil, contactFields(contact))
+	if err := c.ContactRevisionRepository.Create(tx, revision); err != nil {
+		c.Log.WithError(err).Error("error creating revision")
+		return nil, helper.ErrInternalServerError
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		c.Log.WithError(err).Error("error creating contact")
 		return nil, helper.ErrInternalServerError
@@ -76,6 +113,7 @@ func (c *ContactUseCase) Update(ctx context.Context, request *model.UpdateContac
 		return nil, helper.ErrBadRequest
 	}
 
+	before := contactFields(contact)
 	contact.FirstName = request.FirstName
 	contact.LastName = request.LastName
 	contact.Email = request.Email
@@ -86,6 +124,15 @@ func (c *ContactUseCase) Update(ctx context.Context, request *model.UpdateContac
 		return nil, helper.ErrInternalServerError
 	}
 
+	// saving the same values again is not worth a revision
+	if after := contactFields(contact); !maps.Equal(before, after) {
+		revision := newRevision(request.UserId, request.ImpersonatorId, model.RevisionUpdate, contact.ID, "", before, after)
+		if err := c.ContactRevisionRepository.Create(tx, revision); err != nil {
+			c.Log.WithError(err).Error("error creating revision")
+			return nil, helper.ErrInternalServerError
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		c.Log.WithError(err).Error("error updating contact")
 		return nil, helper.ErrInternalServerError
@@ -132,11 +179,18 @@ func (c *ContactUseCase) Delete(ctx context.Context, request *model.DeleteContac
 		return helper.ErrNotFound
 	}
 
+	// the contact only goes to the trash, its addresses and groups stay for a restore
 	if err := c.ContactRepository.Delete(tx, contact); err != nil {
 		c.Log.WithError(err).Error("error deleting contact")
 		return helper.ErrInternalServerError
 	}
 
+	revision := newRevision(request.UserId, request.ImpersonatorId, model.RevisionDelete, contact.ID, "", contactFields(contact), nil)
+	if err := c.ContactRevisionRepository.Create(tx, revision); err != nil {
+		c.Log.WithError(err).Error("error creating revision")
+		return helper.ErrInternalServerError
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		c.Log.WithError(err).Error("error deleting contact")
 		return helper.ErrInternalServerError
@@ -165,6 +219,117 @@ func (c *ContactUseCase) Search(ctx context.Context, request *model.SearchContac
 		return nil, 0, helper.ErrInternalServerError
 	}
 
+	responses := make([]model.ContactResponse, len(contacts))
+	for i, contact := range contacts {
+		responses[i] = *converter.ContactToResponse(&contact.Contact)
+		responses[i].Score = contact.Score
+	}
+
+	return responses, total, nil
+}
+
+// SearchCursor pages through contacts with cursors instead of page numbers. Pages stay stable
+// while contacts change, but matches are never ordered by relevance here
+func (c *ContactUseCase) SearchCursor(ctx context.Context, request *model.SearchContactRequest) ([]model.ContactResponse, *model.PageMetadata, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Error("error validating request body")
+		return nil, nil, helper.ErrBadRequest
+	}
+
+	sort := request.Sort
+	if len(sort) == 0 {
+		sort = []string{"created_at"}
+	}
+
+	var cursor *model.PageCursor
+	if request.Cursor != "" {
+		var err error
+		cursor, err = model.DecodePageCursor(request.Cursor)
+		if err != nil || !cursor.Fits(strings.Join(sort, ","), c.ContactRepository.SortValues(new(entity.Contact), sort)) {
+			c.Log.WithError(err).Error("error decoding cursor")
+			return nil, nil, helper.ErrBadRequest
+		}
+	}
+
+	contacts, err := c.ContactRepository.SearchCursor(tx, request, sort, cursor)
+	if err != nil {
+		c.Log.WithError(err).Error("error getting contacts")
+		return nil, nil, helper.ErrInternalServerError
+	}
+
+	paging := &model.PageMetadata{Size: request.Size, TotalItem: -1, TotalPage: -1}
+	if !request.SkipCount {
+		total, err := c.ContactRepository.Count(tx, request)
+		if err != nil {
+			c.Log.WithError(err).Error("error counting contacts")
+			return nil, nil, helper.ErrInternalServerError
+		}
+		paging.TotalItem = total
+		paging.TotalPage = int64(math.Ceil(float64(total) / float64(request.Size)))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).Error("error getting contacts")
+		return nil, nil, helper.ErrInternalServerError
+	}
+
+	more := len(contacts) > request.Size
+	if more {
+		contacts = contacts[:request.Size]
+	}
+
+	// a page reached by going back has the page it came from after it
+	backward := cursor != nil && cursor.Backward
+	hasNext, hasPrev := more, cursor != nil
+	if backward {
+		slices.Reverse(contacts)
+		hasNext, hasPrev = true, more
+	}
+
+	if len(contacts) > 0 {
+		key := strings.Join(sort, ",")
+		if hasNext {
+			last := &contacts[len(contacts)-1]
+			paging.NextCursor = (&model.PageCursor{Sort: key, Values: c.ContactRepository.SortValues(&last.Contact, sort), ID: last.ID}).Encode()
+		}
+		if hasPrev {
+			first := &contacts[0]
+			paging.PrevCursor = (&model.PageCursor{Sort: key, Values: c.ContactRepository.SortValues(&first.Contact, sort), ID: first.ID, Backward: true}).Encode()
+		}
+	}
+
+	responses := make([]model.ContactResponse, len(contacts))
+	for i, contact := range contacts {
+		responses[i] = *converter.ContactToResponse(&contact.Contact)
+		responses[i].Score = contact.Score
+	}
+
+	return responses, paging, nil
+}
+
+func (c *ContactUseCase) Trash(ctx context.Context, request *model.ListTrashContactRequest) ([]model.ContactResponse, int64, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Error("error validating request body")
+		return nil, 0, helper.ErrBadRequest
+	}
+
+	contacts, total, err := c.ContactRepository.SearchTrash(tx, request)
+	if err != nil {
+		c.Log.WithError(err).Error("error getting trashed contacts")
+		return nil, 0, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).Error("error getting trashed contacts")
+		return nil, 0, helper.ErrInternalServerError
+	}
+
 	responses := make([]model.ContactResponse, len(contacts))
 	for i, contact := range contacts {
 		responses[i] = *converter.ContactToResponse(&contact)
@@ -172,3 +337,509 @@ func (c *ContactUseCase) Search(ctx context.Context, request *model.SearchContac
 
 	return responses, total, nil
 }
+
+func (c *ContactUseCase) Restore(ctx context.Context, request *model.RestoreContactRequest) (*model.ContactResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Error("error validating request body")
+		return nil, helper.ErrBadRequest
+	}
+
+	contact := new(entity.Contact)
+	if err := c.ContactRepository.FindTrashedByIdAndUserId(tx, contact, request.ID, request.UserId); err != nil {
+		c.Log.WithError(err).Error("error getting trashed contact")
+		return nil, helper.ErrNotFound
+	}
+
+	if err := c.ContactRepository.Restore(tx, contact); err != nil {
+		c.Log.WithError(err).Error("error restoring contact")
+		return nil, helper.ErrInternalServerError
+	}
+
+	revision := newRevision(request.UserId, request.ImpersonatorId, model.RevisionRestore, contact.ID, "", contactFields(contact), contactFields(contact))
+	if err := c.ContactRevisionRepository.Create(tx, revision); err != nil {
+		c.Log.WithError(err).Error("error creating revision")
+		return nil, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).Error("error restoring contact")
+		return nil, helper.ErrInternalServerError
+	}
+
+	return converter.ContactToResponse(contact), nil
+}
+
+// Duplicates clusters the contacts of the user that look like the same person, a contact matching
+// two others puts all three in one cluster. Clusters come oldest first by their oldest contact
+func (c *ContactUseCase) Duplicates(ctx context.Context, request *model.ListDuplicateRequest) ([]model.DuplicateResponse, int64, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Error("error validating request body")
+		return nil, 0, helper.ErrBadRequest
+	}
+
+	pairs, err := c.ContactRepository.FindDuplicatePairs(tx, request.UserId, duplicatePairLimit)
+	if err != nil {
+		c.Log.WithError(err).Error("error finding duplicate contacts")
+		return nil, 0, helper.ErrInternalServerError
+	}
+
+	clusterOf, reasons := clusterDuplicates(pairs)
+	contacts, err := c.ContactRepository.FindAllByIdsAndUserId(tx, slices.Collect(maps.Keys(clusterOf)), request.UserId)
+	if err != nil {
+		c.Log.WithError(err).Error("error getting duplicate contacts")
+		return nil, 0, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).Error("error getting duplicate contacts")
+		return nil, 0, helper.ErrInternalServerError
+	}
+
+	// contacts come oldest first, so a cluster is placed by the first of its contacts to show up
+	var clusters []*model.DuplicateResponse
+	byRoot := make(map[string]*model.DuplicateResponse)
+	for _, contact := range contacts {
+		root := clusterOf[contact.ID]
+		cluster, ok := byRoot[root]
+		if !ok {
+			cluster = &model.DuplicateResponse{Reasons: reasons[root]}
+			byRoot[root] = cluster
+			clusters = append(clusters, cluster)
+		}
+		cluster.Contacts = append(cluster.Contacts, *converter.ContactToResponse(&contact))
+	}
+
+	total := int64(len(clusters))
+	start := min((request.Page-1)*request.Size, len(clusters))
+	end := min(start+request.Size, len(clusters))
+
+	responses := make([]model.DuplicateResponse, 0, end-start)
+	for _, cluster := range clusters[start:end] {
+		responses = append(responses, *cluster)
+	}
+
+	return responses, total, nil
+}
+
+func (c *ContactUseCase) Merge(ctx context.Context, request *model.MergeContactRequest) (*model.ContactResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Error("error validating request body")
+		return nil, helper.ErrBadRequest
+	}
+
+	if slices.Contains(request.SourceIds, request.TargetId) {
+		c.Log.Warnf("Contact %s merged into itself", request.TargetId)
+		return nil, helper.ErrBadRequest
+	}
+
+	target := new(entity.Contact)
+	if err := c.ContactRepository.FindByIdAndUserId(tx, target, request.TargetId, request.UserId); err != nil {
+		c.Log.WithError(err).Error("error getting contact")
+		return nil, helper.ErrNotFound
+	}
+
+	sources, err := c.ContactRepository.FindAllByIdsAndUserId(tx, request.SourceIds, request.UserId)
+	if err != nil {
+		c.Log.WithError(err).Error("error getting contacts")
+		return nil, helper.ErrInternalServerError
+	}
+	if len(sources) != len(request.SourceIds) {
+		c.Log.Warnf("Merging contacts not found for user %s", request.UserId)
+		return nil, helper.ErrNotFound
+	}
+
+	merged, ok := mergeFields(target, sources, request.Strategy, request.Fields)
+	if !ok {
+		c.Log.Warnf("Merge picks fields from contacts it does not merge")
+		return nil, helper.ErrBadRequest
+	}
+
+	before := contactFields(target)
+	target.FirstName = merged["first_name"]
+	target.LastName = merged["last_name"]
+	target.Email = merged["email"]
+	target.Phone = merged["phone"]
+
+	if err := c.ContactRepository.Update(tx, target); err != nil {
+		c.Log.WithError(err).Error("error updating contact")
+		return nil, helper.ErrInternalServerError
+	}
+
+	// address revisions follow their addresses so they can still be reverted
+	if err := c.AddressRepository.MoveAll(tx, request.SourceIds, target.ID); err != nil {
+		c.Log.WithError(err).Error("error moving addresses")
+		return nil, helper.ErrInternalServerError
+	}
+
+	if err := c.ContactRevisionRepository.MoveAllAddressRevisions(tx, request.SourceIds, target.ID); err != nil {
+		c.Log.WithError(err).Error("error moving address revisions")
+		return nil, helper.ErrInternalServerError
+	}
+
+	if err := c.ContactGroupRepository.MoveAll(tx, request.SourceIds, target.ID); err != nil {
+		c.Log.WithError(err).Error("error moving groups")
+		return nil, helper.ErrInternalServerError
+	}
+
+	revisions := []*entity.ContactRevision{
+		newRevision(request.UserId, request.ImpersonatorId, model.RevisionMerge, target.ID, "", before, contactFields(target)),
+	}
+	for _, source := range sources {
+		if err := c.ContactRepository.Delete(tx, &source); err != nil {
+			c.Log.WithError(err).Error("error deleting contact")
+			return nil, helper.ErrInternalServerError
+		}
+		revisions = append(revisions, newRevision(request.UserId, request.ImpersonatorId, model.RevisionMerge, source.ID, "", contactFields(&source), nil))
+	}
+
+	for _, revision := range revisions {
+		if err := c.ContactRevisionRepository.Create(tx, revision); err != nil {
+			c.Log.WithError(err).Error("error creating revision")
+			return nil, helper.ErrInternalServerError
+		}
+	}
+
+	addresses, err := c.AddressRepository.FindAllByContactId(tx, target.ID, nil)
+	if err != nil {
+		c.Log.WithError(err).Error("error getting addresses")
+		return nil, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).Error("error merging contacts")
+		return nil, helper.ErrInternalServerError
+	}
+
+	response := converter.ContactToResponse(target)
+	response.Addresses = make([]model.AddressResponse, len(addresses))
+	for i, address := range addresses {
+		response.Addresses[i] = *converter.AddressToResponse(&address)
+	}
+
+	return response, nil
+}
+
+func (c *ContactUseCase) Export(ctx context.Context, request *model.ExportContactRequest, write func(card vcard.Card) error) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	// paging does not apply, every match is exported
+	if err := c.Validate.StructExcept(request, "SearchContactRequest.Page", "SearchContactRequest.Size"); err != nil {
+		c.Log.WithError(err).Error("error validating request body")
+		return helper.ErrBadRequest
+	}
+
+	writeAll := func(contacts []entity.Contact) error {
+		for _, contact := range contacts {
+			if err := write(converter.ContactToVCard(&contact, request.Version)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if request.ID != "" {
+		contact := new(entity.Contact)
+		if err := c.ContactRepository.FindByIdAndUserId(tx, contact, request.ID, request.UserId); err != nil {
+			c.Log.WithError(err).Error("error getting contact")
+			return helper.ErrNotFound
+		}
+
+		if request.WithAddresses {
+			addresses, err := c.AddressRepository.FindAllByContactId(tx, contact.ID, nil)
+			if err != nil {
+				c.Log.WithError(err).Error("error getting addresses")
+				return helper.ErrInternalServerError
+			}
+			contact.Addresses = addresses
+		}
+
+		if err := writeAll([]entity.Contact{*contact}); err != nil {
+			c.Log.WithError(err).Error("error writing contacts")
+			return helper.ErrInternalServerError
+		}
+	} else if err := c.ContactRepository.FindInBatches(tx, &request.SearchContactRequest, request.WithAddresses, exportBatchSize, writeAll); err != nil {
+		c.Log.WithError(err).Error("error exporting contacts")
+		return helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).Error("error getting contacts")
+		return helper.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (c *ContactUseCase) Import(ctx context.Context, request *model.ImportContactRequest) (*model.ImportContactResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Error("error validating request body")
+		return nil, helper.ErrBadRequest
+	}
+
+	response := &model.ImportContactResponse{Results: []model.ImportContactResult{}}
+	decoder := vcard.NewDecoder(strings.NewReader(request.VCard))
+	for index := 0; ; index++ {
+		card, err := decoder.Decode()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		result := model.ImportContactResult{Index: index}
+		if err != nil {
+			c.Log.WithError(err).Warnf("Unreadable card %d imported by user %s", index, request.UserId)
+			result.Errors = []string{"card"}
+		} else {
+			result.Name = card.PreferredValue(vcard.FieldFormattedName)
+			c.importCard(ctx, request, card, &result)
+		}
+
+		if len(result.Errors) > 0 {
+			response.Failed++
+		} else {
+			response.Imported++
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	if len(response.Results) == 0 {
+		c.Log.Warnf("Import without cards by user %s", request.UserId)
+		return nil, helper.ErrBadRequest
+	}
+
+	return response, nil
+}
+
+func (c *ContactUseCase) importCard(ctx context.Context, request *model.ImportContactRequest, card vcard.Card, result *model.ImportContactResult) {
+	contactRequest, addressRequests := converter.VCardToContact(card)
+	if !request.WithAddresses {
+		addressRequests = nil
+	}
+
+	contactRequest.UserId = request.UserId
+	contactRequest.ImpersonatorId = request.ImpersonatorId
+
+	// EMAIL is optional in a card
+	validate := c.Validate.Struct
+	if contactRequest.Email == "" {
+		validate = func(s interface{}) error {
+			return c.Validate.StructExcept(s, "Email")
+		}
+	}
+	if err := validate(contactRequest); err != nil {
+		result.Errors = append(result.Errors, invalidFields(contactRequest, err, "")...)
+	}
+
+	// the contact id is settled up front so the addresses validate before anything is written
+	contactId := uuid.NewString()
+	for i := range addressRequests {
+		addressRequests[i].UserId = request.UserId
+		addressRequests[i].ContactId = contactId
+		if err := c.Validate.Struct(&addressRequests[i]); err != nil {
+			result.Errors = append(result.Errors, invalidFields(&addressRequests[i], err, fmt.Sprintf("addresses[%d].", i))...)
+		}
+	}
+	if len(result.Errors) > 0 {
+		return
+	}
+
+	err := c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		contact := &entity.Contact{
+			ID:        contactId,
+			FirstName: contactRequest.FirstName,
+			LastName:  contactRequest.LastName,
+			Email:     contactRequest.Email,
+			Phone:     contactRequest.Phone,
+			UserId:    request.UserId,
+		}
+		if err := c.ContactRepository.Create(tx, contact); err != nil {
+			return err
+		}
+
+		revisions := []*entity.ContactRevision{
+			newRevision(request.UserId, request.ImpersonatorId, model.RevisionCreate, contact.ID, "", nil, contactFields(contact)),
+		}
+		for _, addressRequest := range addressRequests {
+			address := &entity.Address{
+				ID:         uuid.NewString(),
+				ContactId:  contact.ID,
+				Street:     addressRequest.Street,
+				City:       addressRequest.City,
+				Province:   addressRequest.Province,
+				PostalCode: addressRequest.PostalCode,
+				Country:    addressRequest.Country,
+			}
+			if err := c.AddressRepository.Create(tx, address); err != nil {
+				return err
+			}
+			revisions = append(revisions, newRevision(request.UserId, request.ImpersonatorId, model.RevisionCreate, contact.ID, address.ID, nil, addressFields(address)))
+		}
+
+		for _, revision := range revisions {
+			if err := c.ContactRevisionRepository.Create(tx, revision); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.Log.WithError(err).Errorf("error importing card %d", result.Index)
+		result.Errors = []string{"card"}
+		return
+	}
+
+	result.ContactId = contactId
+	result.Addresses = len(addressRequests)
+}
+
+func (c *ContactUseCase) PurgeTrash(ctx context.Context) (int, error) {
+	before := time.Now().Add(-c.Config.TrashRetention).UnixMilli()
+
+	contacts, err := c.ContactRepository.FindAllTrashedBefore(c.DB.WithContext(ctx), before, 100)
+	if err != nil {
+		c.Log.WithError(err).Error("error getting trashed contacts")
+		return 0, helper.ErrInternalServerError
+	}
+
+	purged := 0
+	var errs []error
+	for _, contact := range contacts {
+		err := c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return c.purge(tx, &contact)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("purge contact %s of user %s : %w", contact.ID, contact.UserId, err))
+			continue
+		}
+		purged++
+	}
+
+	addresses, err := c.AddressRepository.DeleteAllTrashedBefore(c.DB.WithContext(ctx), before)
+	if err != nil {
+		c.Log.WithError(err).Error("error purging trashed addresses")
+		errs = append(errs, helper.ErrInternalServerError)
+	}
+
+	return purged + int(addresses), errors.Join(errs...)
+}
+
+// purge deletes the contact and what hangs off it, children first since the foreign keys do not cascade
+func (c *ContactUseCase) purge(tx *gorm.DB, contact *entity.Contact) error {
+	if err := c.AddressRepository.DeleteAllByContactId(tx, contact.ID); err != nil {
+		c.Log.WithError(err).Errorf("error purging addresses of contact %s", contact.ID)
+		return helper.ErrInternalServerError
+	}
+
+	if err := c.ContactGroupRepository.DeleteAllByContactId(tx, contact.ID); err != nil {
+		c.Log.WithError(err).Errorf("error purging groups of contact %s", contact.ID)
+		return helper.ErrInternalServerError
+	}
+
+	if err := c.ContactRevisionRepository.DeleteAllByContactId(tx, contact.ID); err != nil {
+		c.Log.WithError(err).Errorf("error purging revisions of contact %s", contact.ID)
+		return helper.ErrInternalServerError
+	}
+
+	if err := c.ContactRepository.Purge(tx, contact); err != nil {
+		c.Log.WithError(err).Errorf("error purging contact %s", contact.ID)
+		return helper.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func clusterDuplicates(pairs []repository.DuplicatePair) (map[string]string, map[string][]string) {
+	parent := make(map[string]string)
+	find := func(id string) string {
+		if _, ok := parent[id]; !ok {
+			parent[id] = id
+		}
+		for parent[id] != id {
+			parent[id] = parent[parent[id]]
+			id = parent[id]
+		}
+		return id
+	}
+	for _, pair := range pairs {
+		parent[find(pair.ContactId)] = find(pair.OtherId)
+	}
+
+	clusterOf := make(map[string]string, len(parent))
+	for id := range parent {
+		clusterOf[id] = find(id)
+	}
+
+	reasons := make(map[string][]string)
+	for _, pair := range pairs {
+		root := clusterOf[pair.ContactId]
+		if !slices.Contains(reasons[root], pair.Reason) {
+			reasons[root] = append(reasons[root], pair.Reason)
+		}
+	}
+	for _, reason := range reasons {
+		slices.Sort(reason)
+	}
+
+	return clusterOf, reasons
+}
+
+// mergeFields picks the value of every tracked field from the target or a source, ok is false when
+// fields names a contact that is not merged. Sources are tried oldest first
+func mergeFields(target *entity.Contact, sources []entity.Contact, strategy string, fields map[string]string) (map[string]string, bool) {
+	contacts := []*entity.Contact{target}
+	for i := range sources {
+		contacts = append(contacts, &sources[i])
+	}
+	if strategy == model.MergeNewest {
+		// stable so the target wins a tie
+		slices.SortStableFunc(contacts, func(a, b *entity.Contact) int {
+			return cmp.Compare(b.UpdatedAt, a.UpdatedAt)
+		})
+	}
+
+	merged := make(map[string]string)
+	for _, contact := range contacts {
+		for field, value := range contactFields(contact) {
+			if merged[field] == "" {
+				merged[field] = value
+			}
+		}
+	}
+
+	for field, id := range fields {
+		i := slices.IndexFunc(contacts, func(contact *entity.Contact) bool { return contact.ID == id })
+		if i < 0 {
+			return nil, false
+		}
+		merged[field] = contactFields(contacts[i])[field]
+	}
+
+	return merged, true
+}
+
+func invalidFields(request any, err error, prefix string) []string {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return []string{"card"}
+	}
+
+	fields := make([]string, 0, len(errs))
+	requestType := reflect.TypeOf(request).Elem()
+	for _, fieldError := range errs {
+		name := fieldError.StructField()
+		if field, ok := requestType.FieldByName(name); ok {
+			name, _, _ = strings.Cut(field.Tag.Get("json"), ",")
+		}
+		fields = append(fields, prefix+name)
+	}
+	return fields
+}
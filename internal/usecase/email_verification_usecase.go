@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/gateway/mail"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/model/converter"
+	"github.com/iyasz/golang-clean-architecture/internal/repository"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type EmailVerificationConfig struct {
+	TokenTTL time.Duration
+	AppUrl   string
+}
+
+type EmailVerificationUseCase struct {
+	DB                          *gorm.DB
+	Log                         *logrus.Logger
+	Validate                    *validator.Validate
+	Mailer                      mail.Mailer
+	Config                      *EmailVerificationConfig
+	UserRepository              *repository.UserRepository
+	EmailVerificationRepository *repository.EmailVerificationRepository
+}
+
+func NewEmailVerificationUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate, mailer mail.Mailer, config *EmailVerificationConfig,
+	userRepository *repository.UserRepository, emailVerificationRepository *repository.EmailVerificationRepository) *EmailVerificationUseCase {
+	return &EmailVerificationUseCase{
+		DB:                          db,
+		Log:                         logger,
+		Validate:                    validate,
+		Mailer:                      mailer,
+		Config:                      config,
+		UserRepository:              userRepository,
+		EmailVerificationRepository: emailVerificationRepository,
+	}
+}
+
+// Send mails a verification link to the current address of the user. The token is bound
+// to that address so a link sent before an email change cannot verify the new one
+func (c *EmailVerificationUseCase) Send(ctx context.Context, request *model.SendEmailVerificationRequest) (bool, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return false, helper.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return false, helper.ErrNotFound
+	}
+
+	if user.Email == "" {
+		c.Log.Warnf("User %s has no email", user.ID)
+		return false, helper.ErrBadRequest
+	}
+
+	if user.EmailVerifiedAt > 0 {
+		c.Log.Warnf("User %s email is already verified", user.ID)
+		return false, helper.ErrConflict
+	}
+
+	token := uuid.New().String()
+	emailVerification := &entity.EmailVerification{
+		ID:        uuid.New().String(),
+		UserId:    user.ID,
+		Email:     user.Email,
+		Token:     helper.HashToken(token),
+		ExpiresAt: time.Now().Add(c.Config.TokenTTL).UnixMilli(),
+	}
+
+	if err := c.EmailVerificationRepository.Create(tx, emailVerification); err != nil {
+		c.Log.Warnf("Failed create email verification : %+v", err)
+		return false, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return false, helper.ErrInternalServerError
+	}
+
+	message := &mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Hi %s,\r\n\r\nUse the link below to verify your email address, it expires in %s.\r\n\r\n%s/verify-email?token=%s\r\n\r\nIf you did not create an account you can ignore this mail.\r\n",
+			user.Name, c.Config.TokenTTL, c.Config.AppUrl, url.QueryEscape(token)),
+	}
+
+	if err := c.Mailer.Send(ctx, message); err != nil {
+		c.Log.Warnf("Failed send email verification mail : %+v", err)
+		return false, helper.ErrInternalServerError
+	}
+
+	return true, nil
+}
+
+func (c *EmailVerificationUseCase) Verify(ctx context.Context, request *model.VerifyEmailRequest) (*model.UserResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, helper.ErrBadRequest
+	}
+
+	emailVerification := new(entity.EmailVerification)
+	if err := c.EmailVerificationRepository.FindByToken(tx, emailVerification, helper.HashToken(request.Token)); err != nil {
+		c.Log.Warnf("Failed find email verification by token : %+v", err)
+		return nil, helper.ErrBadRequest
+	}
+
+	if time.Now().UnixMilli() > emailVerification.ExpiresAt {
+		c.Log.Warnf("Email verification token expired")
+		return nil, helper.ErrGone
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, emailVerification.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, helper.ErrNotFound
+	}
+
+	if user.Email != emailVerification.Email {
+		c.Log.Warnf("User %s email changed since the verification was sent", user.ID)
+		return nil, helper.ErrGone
+	}
+
+	// the first account to verify the address owns it, other accounts only claimed it
+	if err := c.UserRepository.ReleaseUnverifiedEmail(tx, user.Email, user.ID); err != nil {
+		c.Log.Warnf("Failed release email of other users : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	user.EmailVerifiedAt = time.Now().UnixMilli()
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	if err := c.EmailVerificationRepository.DeleteAllByUserId(tx, user.ID); err != nil {
+		c.Log.Warnf("Failed delete email verifications : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	return converter.UserToResponse(user), nil
+}
+
+// normalizeEmail is applied to every address before it is stored or looked up, the unique index is on the normalized value
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,286 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/model/converter"
+	"github.com/iyasz/golang-clean-architecture/internal/repository"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type GroupUseCase struct {
+	DB                     *gorm.DB
+	Log                    *logrus.Logger
+	Validate               *validator.Validate
+	GroupRepository        *repository.GroupRepository
+	ContactGroupRepository *repository.ContactGroupRepository
+	ContactRepository      *repository.ContactRepository
+}
+
+func NewGroupUseCase(db *gorm.DB, log *logrus.Logger, validate *validator.Validate, groupRepository *repository.GroupRepository,
+	contactGroupRepository *repository.ContactGroupRepository, contactRepository *repository.ContactRepository) *GroupUseCase {
+	return &GroupUseCase{
+		DB:                     db,
+		Log:                    log,
+		Validate:               validate,
+		GroupRepository:        groupRepository,
+		ContactGroupRepository: contactGroupRepository,
+		ContactRepository:      contactRepository,
+	}
+}
+
+func (c *GroupUseCase) Create(ctx context.Context, request *model.CreateGroupRequest) (*model.GroupResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Error("failed to validate request body")
+		return nil, helper.ErrBadRequest
+	}
+
+	if err := c.checkName(tx, request.UserId, request.Name, ""); err != nil {
+		return nil, err
+	}
+
+	group := &entity.Group{
+		ID:     uuid.NewString(),
+		UserId: request.UserId,
+		Name:   request.Name,
+	}
+
+	if err := c.GroupRepository.Create(tx, group); err != nil {
+		c.Log.WithError(err).Error("failed to create group")
+		return nil, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).Error("failed to commit transaction")
+		return nil, helper.ErrInternalServerError
+	}
+
+	return converter.GroupToResponse(group, 0), nil
+}
+
+func (c *GroupUseCase) Update(ctx context.Context, request *model.UpdateGroupRequest) (*model.GroupResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Error("failed to validate request body")
+		return nil, helper.ErrBadRequest
+	}
+
+	group := new(entity.Group)
+	if err := c.GroupRepository.FindByIdAndUserId(tx, group, request.ID, request.UserId); err != nil {
+		c.Log.WithError(err).Error("failed to find group")
+		return nil, helper.ErrNotFound
+	}
+
+	if err := c.checkName(tx, request.UserId, request.Name, group.ID); err != nil {
+		return nil, err
+	}
+
+	group.Name = request.Name
+	if err := c.GroupRepository.Update(tx, group); err != nil {
+		c.Log.WithError(err).Error("failed to update group")
+		return nil, helper.ErrInternalServerError
+	}
+
+	response, err := c.toResponse(tx, group)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).Error("failed to commit transaction")
+		return nil, helper.ErrInternalServerError
+	}
+
+	return response, nil
+}
+
+func (c *GroupUseCase) Get(ctx context.Context, request *model.GetGroupRequest) (*model.GroupResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Error("failed to validate request body")
+		return nil, helper.ErrBadRequest
+	}
+
+	group := new(entity.Group)
+	if err := c.GroupRepository.FindByIdAndUserId(tx, group, request.ID, request.UserId); err != nil {
+		c.Log.WithError(err).Error("failed to find group")
+		return nil, helper.ErrNotFound
+	}
+
+	response, err := c.toResponse(tx, group)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).Error("failed to commit transaction")
+		return nil, helper.ErrInternalServerError
+	}
+
+	return response, nil
+}
+
+func (c *GroupUseCase) List(ctx context.Context, request *model.ListGroupRequest) ([]model.GroupResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Error("failed to validate request body")
+		return nil, helper.ErrBadRequest
+	}
+
+	groups, err := c.GroupRepository.FindAllByUserId(tx, request.UserId)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to find groups")
+		return nil, helper.ErrInternalServerError
+	}
+
+	groupIds := make([]string, len(groups))
+	for i, group := range groups {
+		groupIds[i] = group.ID
+	}
+
+	counts, err := c.ContactGroupRepository.CountByGroupIds(tx, groupIds)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to count group members")
+		return nil, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).Error("failed to commit transaction")
+		return nil, helper.ErrInternalServerError
+	}
+
+	responses := make([]model.GroupResponse, len(groups))
+	for i, group := range groups {
+		responses[i] = *converter.GroupToResponse(&group, counts[group.ID])
+	}
+
+	return responses, nil
+}
+
+func (c *GroupUseCase) Delete(ctx context.Context, request *model.DeleteGroupRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Error("failed to validate request body")
+		return helper.ErrBadRequest
+	}
+
+	group := new(entity.Group)
+	if err := c.GroupRepository.FindByIdAndUserId(tx, group, request.ID, request.UserId); err != nil {
+		c.Log.WithError(err).Error("failed to find group")
+		return helper.ErrNotFound
+	}
+
+	if err := c.ContactGroupRepository.DeleteAllByGroupId(tx, group.ID); err != nil {
+		c.Log.WithError(err).Error("failed to delete group members")
+		return helper.ErrInternalServerError
+	}
+
+	if err := c.GroupRepository.Delete(tx, group); err != nil {
+		c.Log.WithError(err).Error("failed to delete group")
+		return helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).Error("failed to commit transaction")
+		return helper.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (c *GroupUseCase) AddMembers(ctx context.Context, request *model.GroupMembersRequest) (*model.GroupResponse, error) {
+	return c.updateMembers(ctx, request, c.ContactGroupRepository.AddAll)
+}
+
+func (c *GroupUseCase) RemoveMembers(ctx context.Context, request *model.GroupMembersRequest) (*model.GroupResponse, error) {
+	return c.updateMembers(ctx, request, c.ContactGroupRepository.RemoveAll)
+}
+
+func (c *GroupUseCase) updateMembers(ctx context.Context, request *model.GroupMembersRequest,
+	apply func(db *gorm.DB, groupId string, contactIds []string) error) (*model.GroupResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Error("failed to validate request body")
+		return nil, helper.ErrBadRequest
+	}
+
+	group := new(entity.Group)
+	if err := c.GroupRepository.FindByIdAndUserId(tx, group, request.ID, request.UserId); err != nil {
+		c.Log.WithError(err).Error("failed to find group")
+		return nil, helper.ErrNotFound
+	}
+
+	// the whole batch is refused if one contact is missing or owned by someone else
+	total, err := c.ContactRepository.CountByIdsAndUserId(tx, request.ContactIds, request.UserId)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to count contacts")
+		return nil, helper.ErrInternalServerError
+	}
+
+	if total != int64(len(request.ContactIds)) {
+		c.Log.Warnf("Only %d of %d contacts found for group %s", total, len(request.ContactIds), group.ID)
+		return nil, helper.ErrNotFound
+	}
+
+	if err := apply(tx, group.ID, request.ContactIds); err != nil {
+		c.Log.WithError(err).Error("failed to update group members")
+		return nil, helper.ErrInternalServerError
+	}
+
+	response, err := c.toResponse(tx, group)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).Error("failed to commit transaction")
+		return nil, helper.ErrInternalServerError
+	}
+
+	return response, nil
+}
+
+// checkName keeps group names unique per user, excludeId is the group being renamed
+func (c *GroupUseCase) checkName(tx *gorm.DB, userId string, name string, excludeId string) error {
+	total, err := c.GroupRepository.CountByUserIdAndName(tx, userId, name, excludeId)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to count groups")
+		return helper.ErrInternalServerError
+	}
+
+	if total > 0 {
+		c.Log.Warnf("Group %s already exists for user %s", name, userId)
+		return helper.ErrConflict
+	}
+
+	return nil
+}
+
+func (c *GroupUseCase) toResponse(tx *gorm.DB, group *entity.Group) (*model.GroupResponse, error) {
+	counts, err := c.ContactGroupRepository.CountByGroupIds(tx, []string{group.ID})
+	if err != nil {
+		c.Log.WithError(err).Error("failed to count group members")
+		return nil, helper.ErrInternalServerError
+	}
+
+	return converter.GroupToResponse(group, counts[group.ID]), nil
+}
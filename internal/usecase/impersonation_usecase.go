@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/model/converter"
+	"github.com/iyasz/golang-clean-architecture/internal/repository"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ImpersonationUseCase struct {
+	DB                 *gorm.DB
+	Log                *logrus.Logger
+	Validate           *validator.Validate
+	TokenUseCase       *TokenUseCase
+	UserRepository     *repository.UserRepository
+	SessionRepository  *repository.SessionRepository
+	AuditLogRepository *repository.AuditLogRepository
+}
+
+func NewImpersonationUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate, tokenUseCase *TokenUseCase,
+	userRepository *repository.UserRepository, sessionRepository *repository.SessionRepository,
+	auditLogRepository *repository.AuditLogRepository) *ImpersonationUseCase {
+	return &ImpersonationUseCase{
+		DB:                 db,
+		Log:                logger,
+		Validate:           validate,
+		TokenUseCase:       tokenUseCase,
+		UserRepository:     userRepository,
+		SessionRepository:  sessionRepository,
+		AuditLogRepository: auditLogRepository,
+	}
+}
+
+// Start opens a session of the user on behalf of the admin. Its tokens carry both ids, it ends
+// with a logout, when the user revokes it or after ImpersonationMaxAge whatever the refreshes
+func (c *ImpersonationUseCase) Start(ctx context.Context, request *model.ImpersonateUserRequest) (*model.TokenResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, helper.ErrBadRequest
+	}
+
+	if request.ID == request.AdminId {
+		c.Log.Warnf("Admin %s tried to impersonate themselves", request.AdminId)
+		return nil, helper.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, helper.ErrNotFound
+	}
+
+	// acting as another admin would hand out their privileges
+	if user.Role == model.RoleAdmin {
+		c.Log.Warnf("Admin %s tried to impersonate admin %s", request.AdminId, user.ID)
+		return nil, helper.ErrForbidden
+	}
+
+	if err := c.TokenUseCase.CheckActive(user); err != nil {
+		return nil, err
+	}
+
+	session := &entity.Session{
+		ID:             uuid.New().String(),
+		UserId:         user.ID,
+		UserAgent:      request.UserAgent,
+		IpAddress:      request.IpAddress,
+		ImpersonatorId: request.AdminId,
+		ReadOnly:       !request.AllowWrites,
+	}
+
+	if err := c.SessionRepository.Create(tx, session); err != nil {
+		c.Log.Warnf("Failed create session : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	auditLog := &entity.AuditLog{
+		ID:             uuid.New().String(),
+		Action:         model.AuditActionImpersonate,
+		SessionId:      session.ID,
+		ImpersonatorId: request.AdminId,
+		UserId:         user.ID,
+		IpAddress:      request.IpAddress,
+	}
+
+	if err := c.AuditLogRepository.Create(tx, auditLog); err != nil {
+		c.Log.Warnf("Failed create audit log : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	response, err := c.TokenUseCase.Issue(tx, session, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	return response, nil
+}
+
+func (c *ImpersonationUseCase) Record(ctx context.Context, request *model.CreateAuditLogRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return helper.ErrBadRequest
+	}
+
+	auditLog := &entity.AuditLog{
+		ID:             uuid.New().String(),
+		Action:         model.AuditActionRequest,
+		SessionId:      request.SessionId,
+		ImpersonatorId: request.ImpersonatorId,
+		UserId:         request.UserId,
+		Method:         request.Method,
+		Path:           request.Path,
+		Status:         request.Status,
+		IpAddress:      request.IpAddress,
+	}
+
+	if err := c.AuditLogRepository.Create(tx, auditLog); err != nil {
+		c.Log.Warnf("Failed create audit log : %+v", err)
+		return helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return helper.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (c *ImpersonationUseCase) Search(ctx context.Context, request *model.SearchAuditLogRequest) ([]model.AuditLogResponse, int64, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, 0, helper.ErrBadRequest
+	}
+
+	auditLogs, total, err := c.AuditLogRepository.Search(tx, request)
+	if err != nil {
+		c.Log.Warnf("Failed search audit logs : %+v", err)
+		return nil, 0, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, 0, helper.ErrInternalServerError
+	}
+
+	responses := make([]model.AuditLogResponse, len(auditLogs))
+	for i, auditLog := range auditLogs {
+		responses[i] = *converter.AuditLogToResponse(&auditLog)
+	}
+
+	return responses, total, nil
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/repository"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type LoginThrottleConfig struct {
+	MaxAttempts      int
+	MaxAttemptsPerIp int
+	// Delay is the wait after the first failure, doubled on every following one
+	Delay time.Duration
+	// Lockout is both the lock duration and the window after which failures are forgotten
+	Lockout time.Duration
+}
+
+type LoginThrottle struct {
+	Log                    *logrus.Logger
+	Config                 *LoginThrottleConfig
+	LoginAttemptRepository *repository.LoginAttemptRepository
+}
+
+func NewLoginThrottle(logger *logrus.Logger, config *LoginThrottleConfig, loginAttemptRepository *repository.LoginAttemptRepository) *LoginThrottle {
+	return &LoginThrottle{
+		Log:                    logger,
+		Config:                 config,
+		LoginAttemptRepository: loginAttemptRepository,
+	}
+}
+
+func (t *LoginThrottle) Find(tx *gorm.DB, userId string, ipAddress string) (*entity.LoginAttempt, *entity.LoginAttempt, error) {
+	userAttempt := new(entity.LoginAttempt)
+	if err := t.LoginAttemptRepository.FindOrNew(tx, userAttempt, "user:"+userId); err != nil {
+		t.Log.Warnf("Failed find login attempts : %+v", err)
+		return nil, nil, helper.ErrInternalServerError
+	}
+
+	ipAttempt := new(entity.LoginAttempt)
+	if err := t.LoginAttemptRepository.FindOrNew(tx, ipAttempt, "ip:"+ipAddress); err != nil {
+		t.Log.Warnf("Failed find login attempts : %+v", err)
+		return nil, nil, helper.ErrInternalServerError
+	}
+
+	return userAttempt, ipAttempt, nil
+}
+
+func (t *LoginThrottle) Check(userAttempt *entity.LoginAttempt, ipAttempt *entity.LoginAttempt) error {
+	now := time.Now().UnixMilli()
+
+	if userAttempt.LockedUntil > now {
+		return helper.ErrLocked
+	}
+
+	if ipAttempt.LockedUntil > now || userAttempt.RetryAt > now || ipAttempt.RetryAt > now {
+		return helper.ErrTooManyRequests
+	}
+
+	return nil
+}
+
+// Failed records the failure for both keys and commits it, the caller's
+// transaction is done afterwards so the error is returned for the caller to pass on
+func (t *LoginThrottle) Failed(tx *gorm.DB, userAttempt *entity.LoginAttempt, ipAttempt *entity.LoginAttempt) error {
+	t.recordFailure(userAttempt, t.Config.MaxAttempts)
+	t.recordFailure(ipAttempt, t.Config.MaxAttemptsPerIp)
+
+	for _, attempt := range []*entity.LoginAttempt{userAttempt, ipAttempt} {
+		if err := t.LoginAttemptRepository.Update(tx, attempt); err != nil {
+			t.Log.Warnf("Failed save login attempts : %+v", err)
+			return helper.ErrInternalServerError
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		t.Log.Warnf("Failed commit transaction : %+v", err)
+		return helper.ErrInternalServerError
+	}
+
+	return helper.ErrUnauthorized
+}
+
+// Succeeded clears the user failure counter. The ip counter only decays so a valid
+// account cannot be used to reset it
+func (t *LoginThrottle) Succeeded(tx *gorm.DB, userAttempt *entity.LoginAttempt) error {
+	if userAttempt.Failures == 0 {
+		return nil
+	}
+
+	if err := t.LoginAttemptRepository.Delete(tx, userAttempt); err != nil {
+		t.Log.Warnf("Failed reset login attempts : %+v", err)
+		return helper.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (t *LoginThrottle) recordFailure(attempt *entity.LoginAttempt, maxAttempts int) {
+	now := time.Now()
+
+	if now.Sub(time.UnixMilli(attempt.LastFailedAt)) > t.Config.Lockout {
+		attempt.Failures = 0
+	}
+
+	attempt.Failures++
+	attempt.LastFailedAt = now.UnixMilli()
+
+	delay := t.Config.Delay
+	for i := 1; i < attempt.Failures && delay < t.Config.Lockout; i++ {
+		delay *= 2
+	}
+	attempt.RetryAt = now.Add(min(delay, t.Config.Lockout)).UnixMilli()
+
+	if attempt.Failures >= maxAttempts {
+		attempt.LockedUntil = now.Add(t.Config.Lockout).UnixMilli()
+	}
+}
@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/gateway/mail"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/repository"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type MagicLinkConfig struct {
+	TokenTTL time.Duration
+	// MaxRequests links can be sent to one address per Window, zero turns the limit off
+	MaxRequests int
+	Window      time.Duration
+	AppUrl      string
+}
+
+type MagicLinkUseCase struct {
+	DB                  *gorm.DB
+	Log                 *logrus.Logger
+	Validate            *validator.Validate
+	Mailer              mail.Mailer
+	Config              *MagicLinkConfig
+	TokenUseCase        *TokenUseCase
+	UserRepository      *repository.UserRepository
+	MagicLinkRepository *repository.MagicLinkRepository
+}
+
+func NewMagicLinkUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate, mailer mail.Mailer, config *MagicLinkConfig,
+	tokenUseCase *TokenUseCase, userRepository *repository.UserRepository, magicLinkRepository *repository.MagicLinkRepository) *MagicLinkUseCase {
+	return &MagicLinkUseCase{
+		DB:                  db,
+		Log:                 logger,
+		Validate:            validate,
+		Mailer:              mailer,
+		Config:              config,
+		TokenUseCase:        tokenUseCase,
+		UserRepository:      userRepository,
+		MagicLinkRepository: magicLinkRepository,
+	}
+}
+
+// Send mails a login link to a verified address. Like Forgot it answers the same way whether
+// the address is known, limited or not, so it cannot be used to find out who has an account
+func (c *MagicLinkUseCase) Send(ctx context.Context, request *model.MagicLinkRequest) (bool, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return false, helper.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindByVerifiedEmail(tx, user, normalizeEmail(request.Email)); err != nil {
+		c.Log.Warnf("Failed find user by verified email : %+v", err)
+		return true, nil
+	}
+
+	// concurrent requests for the same user wait here, otherwise they all count below the limit
+	if err := c.UserRepository.FindByIdForUpdate(tx, user, user.ID); err != nil {
+		c.Log.Warnf("Failed lock user : %+v", err)
+		return false, helper.ErrInternalServerError
+	}
+
+	now := time.Now()
+
+	if c.Config.MaxRequests > 0 {
+		total, err := c.MagicLinkRepository.CountByEmailSince(tx, user.Email, now.Add(-c.Config.Window).UnixMilli())
+		if err != nil {
+			c.Log.Warnf("Failed count magic links : %+v", err)
+			return false, helper.ErrInternalServerError
+		}
+
+		if total >= int64(c.Config.MaxRequests) {
+			c.Log.Warnf("Too many magic links requested for user %s", user.ID)
+			return true, nil
+		}
+	}
+
+	// expired links are kept for the window so they still count against the limit
+	if err := c.MagicLinkRepository.DeleteAllExpired(tx, now.Add(-c.Config.Window).UnixMilli()); err != nil {
+		c.Log.Warnf("Failed delete expired magic links : %+v", err)
+		return false, helper.ErrInternalServerError
+	}
+
+	tokenId := uuid.New().String()
+	token, err := c.TokenUseCase.Jwt.GenerateMagicLinkToken(user.ID, tokenId, c.Config.TokenTTL)
+	if err != nil {
+		c.Log.Warnf("Failed sign magic link token : %+v", err)
+		return false, helper.ErrInternalServerError
+	}
+
+	magicLink := &entity.MagicLink{
+		ID:        uuid.New().String(),
+		UserId:    user.ID,
+		Email:     user.Email,
+		Token:     helper.HashToken(tokenId),
+		ExpiresAt: now.Add(c.Config.TokenTTL).UnixMilli(),
+	}
+
+	if err := c.MagicLinkRepository.Create(tx, magicLink); err != nil {
+		c.Log.Warnf("Failed create magic link : %+v", err)
+		return false, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return false, helper.ErrInternalServerError
+	}
+
+	message := &mail.Message{
+		To:      user.Email,
+		Subject: "Your login link",
+		Body: fmt.Sprintf("Hi %s,\r\n\r\nUse the link below to log in, it works once and expires in %s.\r\n\r\n%s/magic-link?token=%s\r\n\r\nIf you did not ask for it you can ignore this mail.\r\n",
+			user.Name, c.Config.TokenTTL, c.Config.AppUrl, url.QueryEscape(token)),
+	}
+
+	if err := c.Mailer.Send(ctx, message); err != nil {
+		c.Log.Warnf("Failed send magic link mail : %+v", err)
+	}
+
+	return true, nil
+}
+
+func (c *MagicLinkUseCase) Login(ctx context.Context, request *model.LoginMagicLinkRequest) (*model.TokenResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, helper.ErrBadRequest
+	}
+
+	claims, err := c.TokenUseCase.Jwt.ParseMagicLinkToken(request.Token)
+	if err == helper.ErrTokenExpired {
+		c.Log.Warnf("Magic link token expired : %+v", err)
+		return nil, helper.ErrTokenExpired
+	}
+
+	if err != nil {
+		c.Log.Warnf("Failed parse magic link token : %+v", err)
+		return nil, helper.ErrUnauthorized
+	}
+
+	// a failed login below rolls the deletion back, the link then still works
+	magicLink := new(entity.MagicLink)
+	deleted, err := c.MagicLinkRepository.DeleteByToken(tx, magicLink, helper.HashToken(claims.ID))
+	if err != nil {
+		c.Log.Warnf("Failed delete magic link : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	if deleted != 1 || magicLink.UserId != claims.Subject {
+		c.Log.Warnf("Magic link of user %s not found or already used", claims.Subject)
+		return nil, helper.ErrUnauthorized
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, magicLink.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, helper.ErrUnauthorized
+	}
+
+	// the link proves access to the inbox it was sent to, not to a later address
+	if user.Email != magicLink.Email || user.EmailVerifiedAt == 0 {
+		c.Log.Warnf("Email of user %s changed since the magic link was sent", user.ID)
+		return nil, helper.ErrUnauthorized
+	}
+
+	if err := c.TokenUseCase.CheckActive(user); err != nil {
+		return nil, err
+	}
+
+	response, err := c.TokenUseCase.Login(tx, user, request.UserAgent, request.IpAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	return response, nil
+}
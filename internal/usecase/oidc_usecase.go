@@ -0,0 +1,393 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/gateway/oidc"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/model/converter"
+	"github.com/iyasz/golang-clean-architecture/internal/repository"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const oidcStateTTL = 10 * time.Minute
+
+type OidcUseCase struct {
+	DB                     *gorm.DB
+	Log                    *logrus.Logger
+	Validate               *validator.Validate
+	Providers              map[string]*oidc.Provider
+	TokenUseCase           *TokenUseCase
+	UserRepository         *repository.UserRepository
+	UserIdentityRepository *repository.UserIdentityRepository
+	OidcStateRepository    *repository.OidcStateRepository
+}
+
+func NewOidcUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate, providers map[string]*oidc.Provider, tokenUseCase *TokenUseCase,
+	userRepository *repository.UserRepository, userIdentityRepository *repository.UserIdentityRepository,
+	oidcStateRepository *repository.OidcStateRepository) *OidcUseCase {
+	return &OidcUseCase{
+		DB:                     db,
+		Log:                    logger,
+		Validate:               validate,
+		Providers:              providers,
+		TokenUseCase:           tokenUseCase,
+		UserRepository:         userRepository,
+		UserIdentityRepository: userIdentityRepository,
+		OidcStateRepository:    oidcStateRepository,
+	}
+}
+
+// Start returns the provider url the user agent is sent to. The state, nonce and PKCE verifier
+// are kept until the callback, the state is the only one of them that leaves this service as is
+func (c *OidcUseCase) Start(ctx context.Context, request *model.OidcStartRequest) (*model.OidcAuthorizationResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, helper.ErrBadRequest
+	}
+
+	provider, ok := c.Providers[request.Provider]
+	if !ok {
+		c.Log.Warnf("Unknown oidc provider %s", request.Provider)
+		return nil, helper.ErrNotFound
+	}
+
+	state := uuid.New().String()
+	oidcState := &entity.OidcState{
+		ID:           uuid.New().String(),
+		Provider:     provider.Name,
+		State:        helper.HashToken(state),
+		Nonce:        uuid.New().String(),
+		CodeVerifier: oidc.GenerateVerifier(),
+		UserId:       request.UserId,
+		ExpiresAt:    time.Now().Add(oidcStateTTL).UnixMilli(),
+	}
+
+	authorizationUrl, err := provider.AuthCodeURL(ctx, state, oidcState.Nonce, oidcState.CodeVerifier)
+	if err != nil {
+		c.Log.Warnf("Failed build authorization url : %+v", err)
+		return nil, helper.ErrBadGateway
+	}
+
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.OidcStateRepository.DeleteAllExpired(tx, time.Now().UnixMilli()); err != nil {
+		c.Log.Warnf("Failed delete expired oidc states : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	if err := c.OidcStateRepository.Create(tx, oidcState); err != nil {
+		c.Log.Warnf("Failed create oidc state : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	return &model.OidcAuthorizationResponse{AuthorizationUrl: authorizationUrl, Binding: oidcState.State}, nil
+}
+
+// Callback redeems the state once, exchanges the code and logs the owner of the external
+// identity in. An unknown identity is linked to the account with the same verified email,
+// or to a new account provisioned from the id token claims. The state has to come back to the
+// user agent that started the flow, a link also to a request of the same user
+func (c *OidcUseCase) Callback(ctx context.Context, request *model.OidcCallbackRequest) (*model.TokenResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, helper.ErrBadRequest
+	}
+
+	provider, ok := c.Providers[request.Provider]
+	if !ok {
+		c.Log.Warnf("Unknown oidc provider %s", request.Provider)
+		return nil, helper.ErrNotFound
+	}
+
+	if subtle.ConstantTimeCompare([]byte(helper.HashToken(request.State)), []byte(request.Binding)) != 1 {
+		c.Log.Warnf("Oidc state is not bound to this user agent")
+		return nil, helper.ErrBadRequest
+	}
+
+	oidcState, err := c.redeemState(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if oidcState.UserId != "" && oidcState.UserId != request.UserId {
+		c.Log.Warnf("Identity link of user %s completed without its access token", oidcState.UserId)
+		return nil, helper.ErrUnauthorized
+	}
+
+	claims, err := provider.Exchange(ctx, request.Code, oidcState.Nonce, oidcState.CodeVerifier)
+	if err != nil {
+		c.Log.Warnf("Failed exchange oidc code : %+v", err)
+		return nil, helper.ErrUnauthorized
+	}
+
+	if claims.Subject == "" {
+		c.Log.Warnf("Id token of %s has no subject", provider.Name)
+		return nil, helper.ErrUnauthorized
+	}
+
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	var user *entity.User
+	if oidcState.UserId != "" {
+		user, err = c.link(tx, provider, claims, oidcState.UserId)
+	} else {
+		user, err = c.findOrProvision(tx, provider, claims)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.TokenUseCase.CheckActive(user); err != nil {
+		return nil, err
+	}
+
+	// the provider stands in for the password, the second factor is still asked for
+	response, err := c.TokenUseCase.Login(tx, user, request.UserAgent, request.IpAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	return response, nil
+}
+
+// redeemState deletes the pending request in its own transaction, so a state cannot be
+// replayed even when the code exchange that follows fails
+func (c *OidcUseCase) redeemState(ctx context.Context, request *model.OidcCallbackRequest) (*entity.OidcState, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	oidcState := new(entity.OidcState)
+	if err := c.OidcStateRepository.FindByProviderAndState(tx, oidcState, request.Provider, helper.HashToken(request.State)); err != nil {
+		c.Log.Warnf("Failed find oidc state : %+v", err)
+		return nil, helper.ErrBadRequest
+	}
+
+	if err := c.OidcStateRepository.Delete(tx, oidcState); err != nil {
+		c.Log.Warnf("Failed delete oidc state : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	if time.Now().UnixMilli() > oidcState.ExpiresAt {
+		c.Log.Warnf("Oidc state expired")
+		return nil, helper.ErrGone
+	}
+
+	return oidcState, nil
+}
+
+func (c *OidcUseCase) link(tx *gorm.DB, provider *oidc.Provider, claims *oidc.Claims, userId string) (*entity.User, error) {
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, userId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, helper.ErrNotFound
+	}
+
+	identity := new(entity.UserIdentity)
+	err := c.UserIdentityRepository.FindByProviderAndSubject(tx, identity, provider.Name, claims.Subject)
+	if err == nil {
+		if identity.UserId != user.ID {
+			c.Log.Warnf("Identity %s of %s is linked to another user", claims.Subject, provider.Name)
+			return nil, helper.ErrConflict
+		}
+		return user, nil
+	}
+
+	if err := c.createIdentity(tx, provider, claims, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (c *OidcUseCase) findOrProvision(tx *gorm.DB, provider *oidc.Provider, claims *oidc.Claims) (*entity.User, error) {
+	user := new(entity.User)
+
+	identity := new(entity.UserIdentity)
+	if err := c.UserIdentityRepository.FindByProviderAndSubject(tx, identity, provider.Name, claims.Subject); err == nil {
+		if err := c.UserRepository.FindById(tx, user, identity.UserId); err != nil {
+			c.Log.Warnf("Failed find user by id : %+v", err)
+			return nil, helper.ErrInternalServerError
+		}
+		return user, nil
+	}
+
+	// an address only proves ownership of an account when both sides verified it
+	email := normalizeEmail(claims.Email)
+	if email != "" && claims.EmailVerified {
+		if err := c.UserRepository.FindByVerifiedEmail(tx, user, email); err == nil {
+			if err := c.createIdentity(tx, provider, claims, user); err != nil {
+				return nil, err
+			}
+			return user, nil
+		}
+	}
+
+	user = &entity.User{
+		ID:   uuid.New().String(),
+		Name: provisionedName(claims),
+		Role: model.RoleUser,
+	}
+
+	// the address is left out rather than failing the login when another account verified it
+	if email != "" {
+		total, err := c.UserRepository.CountByVerifiedEmail(tx, email)
+		if err != nil {
+			c.Log.Warnf("Failed count user by email : %+v", err)
+			return nil, helper.ErrInternalServerError
+		}
+		if total == 0 {
+			user.Email = email
+		}
+		if total == 0 && claims.EmailVerified {
+			user.EmailVerifiedAt = time.Now().UnixMilli()
+			if err := c.UserRepository.ReleaseUnverifiedEmail(tx, email, user.ID); err != nil {
+				c.Log.Warnf("Failed release email of other users : %+v", err)
+				return nil, helper.ErrInternalServerError
+			}
+		}
+	}
+
+	if err := c.UserRepository.Create(tx, user); err != nil {
+		c.Log.Warnf("Failed create user : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	if err := c.createIdentity(tx, provider, claims, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (c *OidcUseCase) createIdentity(tx *gorm.DB, provider *oidc.Provider, claims *oidc.Claims, user *entity.User) error {
+	identity := &entity.UserIdentity{
+		ID:       uuid.New().String(),
+		UserId:   user.ID,
+		Provider: provider.Name,
+		Subject:  claims.Subject,
+		Email:    normalizeEmail(claims.Email),
+	}
+
+	if err := c.UserIdentityRepository.Create(tx, identity); err != nil {
+		c.Log.Warnf("Failed create user identity : %+v", err)
+		return helper.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// provisionedName falls back to the email and then the subject, users.name is limited to 100 characters
+func provisionedName(claims *oidc.Claims) string {
+	name := claims.Name
+	if name == "" {
+		name = claims.Email
+	}
+	if name == "" {
+		name = claims.Subject
+	}
+
+	if runes := []rune(name); len(runes) > 100 {
+		name = string(runes[:100])
+	}
+	return name
+}
+
+func (c *OidcUseCase) List(ctx context.Context, request *model.ListUserIdentityRequest) ([]model.UserIdentityResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, helper.ErrBadRequest
+	}
+
+	identities, err := c.UserIdentityRepository.FindAllByUserId(tx, request.UserId)
+	if err != nil {
+		c.Log.Warnf("Failed find user identities : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	responses := make([]model.UserIdentityResponse, len(identities))
+	for i, identity := range identities {
+		responses[i] = *converter.UserIdentityToResponse(&identity)
+	}
+
+	return responses, nil
+}
+
+// Delete unlinks an identity, the last one is kept when the account has no password to log in with
+func (c *OidcUseCase) Delete(ctx context.Context, request *model.DeleteUserIdentityRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return helper.ErrBadRequest
+	}
+
+	identity := new(entity.UserIdentity)
+	if err := c.UserIdentityRepository.FindByIdAndUserId(tx, identity, request.ID, request.UserId); err != nil {
+		c.Log.Warnf("Failed find user identity : %+v", err)
+		return helper.ErrNotFound
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return helper.ErrNotFound
+	}
+
+	if user.Password == "" {
+		identities, err := c.UserIdentityRepository.FindAllByUserId(tx, user.ID)
+		if err != nil {
+			c.Log.Warnf("Failed find user identities : %+v", err)
+			return helper.ErrInternalServerError
+		}
+		if len(identities) <= 1 {
+			c.Log.Warnf("User %s has no password and a single identity", user.ID)
+			return helper.ErrConflict
+		}
+	}
+
+	if err := c.UserIdentityRepository.Delete(tx, identity); err != nil {
+		c.Log.Warnf("Failed delete user identity : %+v", err)
+		return helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return helper.ErrInternalServerError
+	}
+
+	return nil
+}
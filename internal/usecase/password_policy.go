@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/repository"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// PasswordPolicyConfig holds the password rules, a zero value turns a rule off
+type PasswordPolicyConfig struct {
+	MinLength int
+	// MinCharacterClasses is how many of lowercase, uppercase, digit and symbol must be used
+	MinCharacterClasses int
+	History             int
+}
+
+type PasswordPolicy struct {
+	Log                       *logrus.Logger
+	Hasher                    helper.PasswordHasher
+	Config                    *PasswordPolicyConfig
+	PasswordHistoryRepository *repository.PasswordHistoryRepository
+}
+
+func NewPasswordPolicy(logger *logrus.Logger, hasher helper.PasswordHasher, config *PasswordPolicyConfig,
+	passwordHistoryRepository *repository.PasswordHistoryRepository) *PasswordPolicy {
+	return &PasswordPolicy{
+		Log:                       logger,
+		Hasher:                    hasher,
+		Config:                    config,
+		PasswordHistoryRepository: passwordHistoryRepository,
+	}
+}
+
+// Check returns a field error listing every rule the password breaks. The user is nil
+// on registration, otherwise its current and previous passwords are refused too
+func (p *PasswordPolicy) Check(tx *gorm.DB, user *entity.User, password string) error {
+	var reasons []string
+
+	if len([]rune(password)) < p.Config.MinLength {
+		reasons = append(reasons, fmt.Sprintf("must be at least %d characters long", p.Config.MinLength))
+	}
+
+	if helper.PasswordCharacterClasses(password) < p.Config.MinCharacterClasses {
+		reasons = append(reasons, fmt.Sprintf("must use at least %d of lowercase, uppercase, digit and symbol", p.Config.MinCharacterClasses))
+	}
+
+	if helper.IsCommonPassword(password) {
+		reasons = append(reasons, "is too common")
+	}
+
+	if user != nil && p.Config.History > 0 {
+		reused, err := p.isReused(tx, user, password)
+		if err != nil {
+			return err
+		}
+		if reused {
+			reasons = append(reasons, fmt.Sprintf("must not be one of the last %d passwords", p.Config.History))
+		}
+	}
+
+	if len(reasons) > 0 {
+		return helper.NewFieldError(map[string][]string{"password": reasons})
+	}
+
+	return nil
+}
+
+func (p *PasswordPolicy) isReused(tx *gorm.DB, user *entity.User, password string) (bool, error) {
+	histories, err := p.PasswordHistoryRepository.FindLatestByUserId(tx, user.ID, p.Config.History)
+	if err != nil {
+		p.Log.Warnf("Failed find password history : %+v", err)
+		return false, helper.ErrInternalServerError
+	}
+
+	// the current password is not in the history of accounts created before it was kept
+	hashes := []string{user.Password}
+	for _, history := range histories {
+		hashes = append(hashes, history.Password)
+	}
+
+	for _, hash := range hashes {
+		if ok, _ := p.Hasher.Verify(hash, password); ok {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (p *PasswordPolicy) Remember(tx *gorm.DB, user *entity.User) error {
+	if p.Config.History <= 0 {
+		return nil
+	}
+
+	history := &entity.PasswordHistory{
+		ID:       uuid.New().String(),
+		UserId:   user.ID,
+		Password: user.Password,
+	}
+
+	if err := p.PasswordHistoryRepository.Create(tx, history); err != nil {
+		p.Log.Warnf("Failed create password history : %+v", err)
+		return helper.ErrInternalServerError
+	}
+
+	if err := p.PasswordHistoryRepository.DeleteAllExceptLatest(tx, user.ID, p.Config.History); err != nil {
+		p.Log.Warnf("Failed delete password history : %+v", err)
+		return helper.ErrInternalServerError
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/gateway/mail"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/repository"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type PasswordResetConfig struct {
+	TokenTTL time.Duration
+	AppUrl   string
+}
+
+type PasswordResetUseCase struct {
+	DB                      *gorm.DB
+	Log                     *logrus.Logger
+	Validate                *validator.Validate
+	Mailer                  mail.Mailer
+	Hasher                  helper.PasswordHasher
+	Config                  *PasswordResetConfig
+	UserRepository          *repository.UserRepository
+	SessionRepository       *repository.SessionRepository
+	PasswordResetRepository *repository.PasswordResetRepository
+	PasswordPolicy          *PasswordPolicy
+}
+
+func NewPasswordResetUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate, mailer mail.Mailer, hasher helper.PasswordHasher, config *PasswordResetConfig,
+	userRepository *repository.UserRepository, sessionRepository *repository.SessionRepository,
+	passwordResetRepository *repository.PasswordResetRepository, passwordPolicy *PasswordPolicy) *PasswordResetUseCase {
+	return &PasswordResetUseCase{
+		DB:                      db,
+		Log:                     logger,
+		Validate:                validate,
+		Mailer:                  mailer,
+		Hasher:                  hasher,
+		Config:                  config,
+		UserRepository:          userRepository,
+		SessionRepository:       sessionRepository,
+		PasswordResetRepository: passwordResetRepository,
+		PasswordPolicy:          passwordPolicy,
+	}
+}
+
+// Forgot mails a reset token to the address. It answers the same way whether the
+// address is known or not so it cannot be used to find out who has an account
+func (c *PasswordResetUseCase) Forgot(ctx context.Context, request *model.ForgotPasswordRequest) (bool, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return false, helper.ErrBadRequest
+	}
+
+	// an unverified address may belong to someone else, it cannot take over the account
+	user := new(entity.User)
+	if err := c.UserRepository.FindByVerifiedEmail(tx, user, normalizeEmail(request.Email)); err != nil {
+		c.Log.Warnf("Failed find user by email : %+v", err)
+		return true, nil
+	}
+
+	token := uuid.New().String()
+	passwordReset := &entity.PasswordReset{
+		ID:        uuid.New().String(),
+		UserId:    user.ID,
+		Token:     helper.HashToken(token),
+		ExpiresAt: time.Now().Add(c.Config.TokenTTL).UnixMilli(),
+	}
+
+	if err := c.PasswordResetRepository.Create(tx, passwordReset); err != nil {
+		c.Log.Warnf("Failed create password reset : %+v", err)
+		return false, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return false, helper.ErrInternalServerError
+	}
+
+	message := &mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\r\n\r\nUse the link below to choose a new password, it expires in %s.\r\n\r\n%s/reset-password?token=%s\r\n\r\nIf you did not ask for it you can ignore this mail.\r\n",
+			user.Name, c.Config.TokenTTL, c.Config.AppUrl, url.QueryEscape(token)),
+	}
+
+	if err := c.Mailer.Send(ctx, message); err != nil {
+		c.Log.Warnf("Failed send password reset mail : %+v", err)
+	}
+
+	return true, nil
+}
+
+func (c *PasswordResetUseCase) Reset(ctx context.Context, request *model.ResetPasswordRequest) (bool, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return false, helper.ErrBadRequest
+	}
+
+	passwordReset := new(entity.PasswordReset)
+	if err := c.PasswordResetRepository.FindByToken(tx, passwordReset, helper.HashToken(request.Token)); err != nil {
+		c.Log.Warnf("Failed find password reset by token : %+v", err)
+		return false, helper.ErrBadRequest
+	}
+
+	if time.Now().UnixMilli() > passwordReset.ExpiresAt {
+		c.Log.Warnf("Password reset token expired")
+		return false, helper.ErrGone
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, passwordReset.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return false, helper.ErrNotFound
+	}
+
+	if err := c.PasswordPolicy.Check(tx, user, request.Password); err != nil {
+		c.Log.Warnf("Password rejected by policy : %+v", err)
+		return false, err
+	}
+
+	password, err := c.Hasher.Hash(request.Password)
+	if err != nil {
+		c.Log.Warnf("Failed to hash password : %+v", err)
+		return false, helper.ErrInternalServerError
+	}
+	user.Password = password
+
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return false, helper.ErrInternalServerError
+	}
+
+	if err := c.PasswordPolicy.Remember(tx, user); err != nil {
+		return false, err
+	}
+
+	if err := c.PasswordResetRepository.DeleteAllByUserId(tx, user.ID); err != nil {
+		c.Log.Warnf("Failed delete password resets : %+v", err)
+		return false, helper.ErrInternalServerError
+	}
+
+	if err := c.SessionRepository.DeleteAllByUserId(tx, user.ID); err != nil {
+		c.Log.Warnf("Failed delete sessions : %+v", err)
+		return false, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return false, helper.ErrInternalServerError
+	}
+
+	return true, nil
+}
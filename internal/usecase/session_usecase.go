@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/model/converter"
+	"github.com/iyasz/golang-clean-architecture/internal/repository"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type SessionUseCase struct {
+	DB                *gorm.DB
+	Log               *logrus.Logger
+	Validate          *validator.Validate
+	SessionRepository *repository.SessionRepository
+}
+
+func NewSessionUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate, sessionRepository *repository.SessionRepository) *SessionUseCase {
+	return &SessionUseCase{
+		DB:                db,
+		Log:               logger,
+		Validate:          validate,
+		SessionRepository: sessionRepository,
+	}
+}
+
+func (c *SessionUseCase) List(ctx context.Context, request *model.ListSessionRequest) ([]model.SessionResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, helper.ErrBadRequest
+	}
+
+	sessions, err := c.SessionRepository.FindAllByUserId(tx, request.UserId)
+	if err != nil {
+		c.Log.Warnf("Failed find sessions : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	responses := make([]model.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = *converter.SessionToResponse(&session, request.CurrentSessionId)
+	}
+
+	return responses, nil
+}
+
+// Revoke deletes one session, both its refresh and access tokens stop working immediately
+func (c *SessionUseCase) Revoke(ctx context.Context, request *model.RevokeSessionRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return helper.ErrBadRequest
+	}
+
+	session := new(entity.Session)
+	if err := c.SessionRepository.FindByIdAndUserId(tx, session, request.ID, request.UserId); err != nil {
+		c.Log.Warnf("Failed find session by id : %+v", err)
+		return helper.ErrNotFound
+	}
+
+	if err := c.SessionRepository.Delete(tx, session); err != nil {
+		c.Log.Warnf("Failed delete session : %+v", err)
+		return helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return helper.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (c *SessionUseCase) RevokeAll(ctx context.Context, request *model.RevokeAllSessionRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return helper.ErrBadRequest
+	}
+
+	if err := c.SessionRepository.DeleteAllByUserId(tx, request.UserId); err != nil {
+		c.Log.Warnf("Failed delete sessions : %+v", err)
+		return helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return helper.ErrInternalServerError
+	}
+
+	return nil
+}
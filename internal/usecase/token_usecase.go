@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/model/converter"
+	"github.com/iyasz/golang-clean-architecture/internal/repository"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const totpChallengeTTL = 5 * time.Minute
+
+type TokenConfig struct {
+	// SessionMaxAge is how long a session lives after login, whatever the activity
+	SessionMaxAge      time.Duration
+	SessionIdleTimeout time.Duration
+	// ImpersonationMaxAge replaces SessionMaxAge for the sessions an admin opens as a user
+	ImpersonationMaxAge time.Duration
+}
+
+// TokenUseCase opens sessions and signs their tokens, it is shared by every way to log in
+// and only runs inside the caller's transaction once the credentials were checked
+type TokenUseCase struct {
+	Log               *logrus.Logger
+	Jwt               *helper.Jwt
+	Config            *TokenConfig
+	SessionRepository *repository.SessionRepository
+}
+
+func NewTokenUseCase(logger *logrus.Logger, jwt *helper.Jwt, config *TokenConfig, sessionRepository *repository.SessionRepository) *TokenUseCase {
+	return &TokenUseCase{
+		Log:               logger,
+		Jwt:               jwt,
+		Config:            config,
+		SessionRepository: sessionRepository,
+	}
+}
+
+func (c *TokenUseCase) Login(tx *gorm.DB, user *entity.User, userAgent string, ipAddress string) (*model.TokenResponse, error) {
+	if user.TotpEnabled {
+		challengeToken, err := c.Jwt.GenerateChallengeToken(user.ID, totpChallengeTTL)
+		if err != nil {
+			c.Log.Warnf("Failed sign challenge token : %+v", err)
+			return nil, helper.ErrInternalServerError
+		}
+
+		return &model.TokenResponse{ChallengeToken: challengeToken}, nil
+	}
+
+	return c.Open(tx, user, userAgent, ipAddress)
+}
+
+func (c *TokenUseCase) Open(tx *gorm.DB, user *entity.User, userAgent string, ipAddress string) (*model.TokenResponse, error) {
+	session := &entity.Session{
+		ID:        uuid.New().String(),
+		UserId:    user.ID,
+		UserAgent: userAgent,
+		IpAddress: ipAddress,
+	}
+
+	if err := c.SessionRepository.Create(tx, session); err != nil {
+		c.Log.Warnf("Failed create session : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	return c.Issue(tx, session, user)
+}
+
+// Issue rotates the session refresh token id and signs a new access/refresh token pair,
+// only the digest of the token id is stored
+func (c *TokenUseCase) Issue(tx *gorm.DB, session *entity.Session, user *entity.User) (*model.TokenResponse, error) {
+	tokenId := uuid.New().String()
+	session.Token = helper.HashToken(tokenId)
+	session.LastSeenAt = time.Now().UnixMilli()
+	if err := c.SessionRepository.Update(tx, session); err != nil {
+		c.Log.Warnf("Failed save session : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	// the access token cannot outlive the session nor the idle window
+	sessionExpiresAt := time.UnixMilli(session.CreatedAt).Add(c.maxAge(session))
+	idleExpiresAt := time.UnixMilli(session.LastSeenAt).Add(c.Config.SessionIdleTimeout)
+
+	accessToken, expiresAt, err := c.Jwt.GenerateAccessToken(&helper.AccessToken{
+		UserId:       session.UserId,
+		SessionId:    session.ID,
+		Role:         user.Role,
+		Impersonator: session.ImpersonatorId,
+		ReadOnly:     session.ReadOnly,
+		NotAfter:     helper.Earliest(sessionExpiresAt, idleExpiresAt),
+	})
+	if err != nil {
+		c.Log.Warnf("Failed sign access token : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	refreshToken, err := c.Jwt.GenerateRefreshToken(session.UserId, session.ID, tokenId, sessionExpiresAt)
+	if err != nil {
+		c.Log.Warnf("Failed sign refresh token : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	return converter.TokenToResponse(accessToken, refreshToken, expiresAt), nil
+}
+
+func (c *TokenUseCase) IsExpired(session *entity.Session) bool {
+	now := time.Now()
+	if now.After(time.UnixMilli(session.CreatedAt).Add(c.maxAge(session))) {
+		return true
+	}
+	return now.After(time.UnixMilli(session.LastSeenAt).Add(c.Config.SessionIdleTimeout))
+}
+
+func (c *TokenUseCase) maxAge(session *entity.Session) time.Duration {
+	if session.ImpersonatorId != "" {
+		return c.Config.ImpersonationMaxAge
+	}
+	return c.Config.SessionMaxAge
+}
+
+func (c *TokenUseCase) CheckActive(user *entity.User) error {
+	if user.Disabled {
+		c.Log.Warnf("User %s is disabled", user.ID)
+		return helper.ErrForbidden
+	}
+
+	if user.DeleteAt != 0 {
+		c.Log.Warnf("User %s is pending deletion", user.ID)
+		return helper.ErrAccountPendingDeletion
+	}
+
+	return nil
+}
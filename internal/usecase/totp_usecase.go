@@ -0,0 +1,228 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/repository"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const recoveryCodeCount = 10
+
+type TotpConfig struct {
+	Issuer string
+}
+
+type TotpUseCase struct {
+	DB                     *gorm.DB
+	Log                    *logrus.Logger
+	Validate               *validator.Validate
+	Hasher                 helper.PasswordHasher
+	Config                 *TotpConfig
+	LoginThrottle          *LoginThrottle
+	UserRepository         *repository.UserRepository
+	RecoveryCodeRepository *repository.RecoveryCodeRepository
+}
+
+func NewTotpUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate, hasher helper.PasswordHasher, config *TotpConfig,
+	loginThrottle *LoginThrottle, userRepository *repository.UserRepository, recoveryCodeRepository *repository.RecoveryCodeRepository) *TotpUseCase {
+	return &TotpUseCase{
+		DB:                     db,
+		Log:                    logger,
+		Validate:               validate,
+		Hasher:                 hasher,
+		Config:                 config,
+		LoginThrottle:          loginThrottle,
+		UserRepository:         userRepository,
+		RecoveryCodeRepository: recoveryCodeRepository,
+	}
+}
+
+// Enroll stores a fresh secret for the user, it only takes effect once confirmed with a code.
+// Enrolling again before confirming replaces the pending secret
+func (c *TotpUseCase) Enroll(ctx context.Context, request *model.EnrollTotpRequest) (*model.TotpResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, helper.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, helper.ErrNotFound
+	}
+
+	if user.TotpEnabled {
+		c.Log.Warnf("Totp already enabled for user %s", user.ID)
+		return nil, helper.ErrConflict
+	}
+
+	secret, err := helper.GenerateTotpSecret()
+	if err != nil {
+		c.Log.Warnf("Failed generate totp secret : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	user.TotpSecret = secret
+	user.TotpLastStep = 0
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	return &model.TotpResponse{
+		Secret: secret,
+		Uri:    helper.TotpUri(c.Config.Issuer, user.ID, secret),
+	}, nil
+}
+
+// Confirm enables totp after the user proves the authenticator works and returns
+// the recovery codes, they are stored hashed and cannot be shown again
+func (c *TotpUseCase) Confirm(ctx context.Context, request *model.ConfirmTotpRequest) (*model.RecoveryCodesResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, helper.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, helper.ErrNotFound
+	}
+
+	if user.TotpEnabled {
+		c.Log.Warnf("Totp already enabled for user %s", user.ID)
+		return nil, helper.ErrConflict
+	}
+
+	if user.TotpSecret == "" {
+		c.Log.Warnf("Totp not enrolled for user %s", user.ID)
+		return nil, helper.ErrBadRequest
+	}
+
+	step, ok := helper.ValidateTotp(user.TotpSecret, request.Code, time.Now(), user.TotpLastStep)
+	if !ok {
+		c.Log.Warnf("Invalid totp code for user %s", user.ID)
+		return nil, helper.ErrBadRequest
+	}
+
+	user.TotpEnabled = true
+	user.TotpLastStep = step
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	if err := c.RecoveryCodeRepository.DeleteAllByUserId(tx, user.ID); err != nil {
+		c.Log.Warnf("Failed delete recovery codes : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := helper.GenerateRecoveryCode()
+		if err != nil {
+			c.Log.Warnf("Failed generate recovery code : %+v", err)
+			return nil, helper.ErrInternalServerError
+		}
+
+		recoveryCode := &entity.RecoveryCode{
+			ID:     uuid.New().String(),
+			UserId: user.ID,
+			Code:   helper.HashToken(code),
+		}
+		if err := c.RecoveryCodeRepository.Create(tx, recoveryCode); err != nil {
+			c.Log.Warnf("Failed create recovery code : %+v", err)
+			return nil, helper.ErrInternalServerError
+		}
+
+		codes = append(codes, code)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	return &model.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable turns totp off, the password is asked again through the login throttle so a stolen
+// access token is not enough
+func (c *TotpUseCase) Disable(ctx context.Context, request *model.DisableTotpRequest) (bool, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return false, helper.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return false, helper.ErrNotFound
+	}
+
+	if user.Password == "" {
+		c.Log.Warnf("User %s has no password to confirm disabling totp with", user.ID)
+		return false, helper.ErrPasswordNotSet
+	}
+
+	userAttempt, ipAttempt, err := c.LoginThrottle.Find(tx, request.UserId, request.IpAddress)
+	if err != nil {
+		return false, err
+	}
+
+	if err := c.LoginThrottle.Check(userAttempt, ipAttempt); err != nil {
+		c.Log.Warnf("Disable totp throttled for user %s from %s : %+v", request.UserId, request.IpAddress, err)
+		return false, err
+	}
+
+	if ok, err := c.Hasher.Verify(user.Password, request.Password); !ok {
+		c.Log.Warnf("Failed to verify user password : %+v", err)
+		return false, c.LoginThrottle.Failed(tx, userAttempt, ipAttempt)
+	}
+
+	if err := c.LoginThrottle.Succeeded(tx, userAttempt); err != nil {
+		return false, err
+	}
+
+	user.TotpSecret = ""
+	user.TotpEnabled = false
+	user.TotpLastStep = 0
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return false, helper.ErrInternalServerError
+	}
+
+	if err := c.RecoveryCodeRepository.DeleteAllByUserId(tx, user.ID); err != nil {
+		c.Log.Warnf("Failed delete recovery codes : %+v", err)
+		return false, helper.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return false, helper.ErrInternalServerError
+	}
+
+	return true, nil
+}
@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
@@ -11,26 +13,46 @@ import (
 	"github.com/iyasz/golang-clean-architecture/internal/model/converter"
 	"github.com/iyasz/golang-clean-architecture/internal/repository"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 type UserUseCase struct {
-	DB             *gorm.DB
-	Log            *logrus.Logger
-	Validate       *validator.Validate
-	UserRepository repository.UserRepository
+	DB                       *gorm.DB
+	Log                      *logrus.Logger
+	Validate                 *validator.Validate
+	Hasher                   helper.PasswordHasher
+	UserRepository           repository.UserRepository
+	RecoveryCodeRepository   *repository.RecoveryCodeRepository
+	SessionRepository        *repository.SessionRepository
+	AuditLogRepository       *repository.AuditLogRepository
+	EmailVerificationUseCase *EmailVerificationUseCase
+	PasswordPolicy           *PasswordPolicy
+	LoginThrottle            *LoginThrottle
+	TokenUseCase             *TokenUseCase
 }
 
-func NewUserUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate, userRepository *repository.UserRepository) *UserUseCase {
+func NewUserUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate, hasher helper.PasswordHasher,
+	userRepository *repository.UserRepository, recoveryCodeRepository *repository.RecoveryCodeRepository,
+	sessionRepository *repository.SessionRepository, auditLogRepository *repository.AuditLogRepository, emailVerificationUseCase *EmailVerificationUseCase, passwordPolicy *PasswordPolicy,
+	loginThrottle *LoginThrottle, tokenUseCase *TokenUseCase) *UserUseCase {
 	return &UserUseCase{
 		DB: db,
 		Log: logger,
 		Validate: validate,
+		Hasher: hasher,
 		UserRepository: *userRepository,
+		RecoveryCodeRepository: recoveryCodeRepository,
+		SessionRepository: sessionRepository,
+		AuditLogRepository: auditLogRepository,
+		EmailVerificationUseCase: emailVerificationUseCase,
+		PasswordPolicy: passwordPolicy,
+		LoginThrottle: loginThrottle,
+		TokenUseCase: tokenUseCase,
 	}
 }
 
+// Verify checks the access token and that its session still exists, so a revoked session,
+// a disabled or deleted account and a role change all take effect on the next request
 func (c *UserUseCase) Verify(ctx context.Context, request *model.VerifyUserRequest) (*model.Auth, error) {
 	tx := c.DB.WithContext(ctx).Begin()
 	defer tx.Rollback()
@@ -41,10 +63,30 @@ func (c *UserUseCase) Verify(ctx context.Context, request *model.VerifyUserReque
 		return nil, helper.ErrBadRequest
 	}
 
-	user := new(entity.User)
-	if err := c.UserRepository.FindByToken(tx, user, request.Token); err != nil {
-		c.Log.Warnf("Failed find user by token : %+v", err)
-		return nil, helper.ErrNotFound
+	claims, err := c.TokenUseCase.Jwt.ParseAccessToken(request.Token)
+	if err == helper.ErrTokenExpired {
+		c.Log.Warnf("Access token expired : %+v", err)
+		return nil, helper.ErrTokenExpired
+	}
+
+	if err != nil {
+		c.Log.Warnf("Failed parse access token : %+v", err)
+		return nil, helper.ErrUnauthorized
+	}
+
+	session := new(entity.Session)
+	if err := c.SessionRepository.FindWithUser(tx, session, claims.SessionId, claims.Subject); err != nil {
+		c.Log.Warnf("Failed find session of access token : %+v", err)
+		return nil, helper.ErrUnauthorized
+	}
+
+	if session.User.Disabled || session.User.DeleteAt != 0 {
+		c.Log.Warnf("User %s is disabled or pending deletion", session.UserId)
+		return nil, helper.ErrUnauthorized
+	}
+
+	if err := c.checkImpersonator(tx, session); err != nil {
+		return nil, err
 	}
 
 	if err := tx.Commit().Error; err != nil {
@@ -52,7 +94,13 @@ func (c *UserUseCase) Verify(ctx context.Context, request *model.VerifyUserReque
 		return nil, helper.ErrInternalServerError
 	}
 
-	return &model.Auth{ID: user.ID}, nil
+	return &model.Auth{
+		ID:             session.UserId,
+		SessionId:      session.ID,
+		Role:           session.User.Role,
+		ImpersonatorId: session.ImpersonatorId,
+		ReadOnly:       session.ReadOnly,
+	}, nil
 }
 
 func (c *UserUseCase) Create(ctx context.Context, request *model.RegisterUserRequest) (*model.UserResponse, error) {
@@ -76,16 +124,30 @@ func (c *UserUseCase) Create(ctx context.Context, request *model.RegisterUserReq
 		return nil, helper.ErrConflict
 	}
 
-	password, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	email := normalizeEmail(request.Email)
+	if email != "" {
+		if err := c.checkEmailAvailable(tx, email); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.PasswordPolicy.Check(tx, nil, request.Password); err != nil {
+		c.Log.Warnf("Password rejected by policy : %+v", err)
+		return nil, err
+	}
+
+	password, err := c.Hasher.Hash(request.Password)
 	if err != nil {
-		c.Log.Warnf("Failed to generate bcrype hash : %+v", err)
+		c.Log.Warnf("Failed to hash password : %+v", err)
 		return nil, helper.ErrInternalServerError
 	}
 
 	user := &entity.User{
 		ID:       request.ID,
-		Password: string(password),
+		Password: password,
 		Name:     request.Name,
+		Email:    email,
+		Role:     model.RoleUser,
 	}
 
 	if err := c.UserRepository.Create(tx, user); err != nil {
@@ -93,15 +155,24 @@ func (c *UserUseCase) Create(ctx context.Context, request *model.RegisterUserReq
 		return nil, helper.ErrInternalServerError
 	}
 
+	if err := c.PasswordPolicy.Remember(tx, user); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Warnf("Failed commit transaction : %+v", err)
 		return nil, helper.ErrInternalServerError
 	}
 
+	// the account exists either way, the user can ask for another mail
+	if user.Email != "" {
+		c.sendEmailVerification(ctx, user)
+	}
+
 	return converter.UserToResponse(user), nil
 }
 
-func (c *UserUseCase) Login(ctx context.Context, request *model.LoginUserRequest) (*model.UserResponse, error) {
+func (c *UserUseCase) Login(ctx context.Context, request *model.LoginUserRequest) (*model.TokenResponse, error) {
 	tx := c.DB.WithContext(ctx).Begin()
 	defer tx.Rollback()
 
@@ -110,21 +181,251 @@ func (c *UserUseCase) Login(ctx context.Context, request *model.LoginUserRequest
 		return nil, helper.ErrBadRequest
 	}
 
+	// an address only identifies the account once it is verified, the attempts are
+	// then counted against the user id so both ways to log in share the lock
+	userId := request.ID
+	if strings.Contains(userId, "@") {
+		user := new(entity.User)
+		if err := c.UserRepository.FindByVerifiedEmail(tx, user, normalizeEmail(userId)); err == nil {
+			userId = user.ID
+		}
+	}
+
+	userAttempt, ipAttempt, err := c.LoginThrottle.Find(tx, userId, request.IpAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.LoginThrottle.Check(userAttempt, ipAttempt); err != nil {
+		c.Log.Warnf("Login throttled for user %s from %s : %+v", userId, request.IpAddress, err)
+		return nil, err
+	}
+
 	user := new(entity.User)
-	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
+	if err := c.UserRepository.FindById(tx, user, userId); err != nil {
 		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, c.LoginThrottle.Failed(tx, userAttempt, ipAttempt)
+	}
+
+	if ok, err := c.Hasher.Verify(user.Password, request.Password); !ok {
+		c.Log.Warnf("Failed to verify user password : %+v", err)
+		return nil, c.LoginThrottle.Failed(tx, userAttempt, ipAttempt)
+	}
+
+	if err := c.TokenUseCase.CheckActive(user); err != nil {
+		return nil, err
+	}
+
+	if err := c.rehash(tx, user, request.Password); err != nil {
+		return nil, err
+	}
+
+	// the failure counter is kept until the second factor passes too, otherwise
+	// a known password would reset it between code guesses
+	if !user.TotpEnabled {
+		if err := c.LoginThrottle.Succeeded(tx, userAttempt); err != nil {
+			return nil, err
+		}
+	}
+
+	response, err := c.TokenUseCase.Login(tx, user, request.UserAgent, request.IpAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	return response, nil
+}
+
+func (c *UserUseCase) LoginTotp(ctx context.Context, request *model.LoginTotpRequest) (*model.TokenResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body  : %+v", err)
+		return nil, helper.ErrBadRequest
+	}
+
+	claims, err := c.TokenUseCase.Jwt.ParseChallengeToken(request.ChallengeToken)
+	if err == helper.ErrTokenExpired {
+		c.Log.Warnf("Challenge token expired : %+v", err)
+		return nil, helper.ErrTokenExpired
+	}
+
+	if err != nil {
+		c.Log.Warnf("Failed parse challenge token : %+v", err)
 		return nil, helper.ErrUnauthorized
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password)); err != nil {
-		c.Log.Warnf("Failed to compare user password with bcrype hash : %+v", err)
+	// the user row stays locked until the session is open, so a code cannot be used by two requests at once
+	user := new(entity.User)
+	if err := c.UserRepository.FindByIdForUpdate(tx, user, claims.Subject); err != nil || !user.TotpEnabled {
+		c.Log.Warnf("Failed find user with totp by id : %+v", err)
 		return nil, helper.ErrUnauthorized
 	}
 
-	user.Token = uuid.New().String()
+	userAttempt, ipAttempt, err := c.LoginThrottle.Find(tx, user.ID, request.IpAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.LoginThrottle.Check(userAttempt, ipAttempt); err != nil {
+		c.Log.Warnf("Login throttled for user %s from %s : %+v", user.ID, request.IpAddress, err)
+		return nil, err
+	}
+
+	if err := c.TokenUseCase.CheckActive(user); err != nil {
+		return nil, err
+	}
+
+	if request.Code != "" {
+		step, ok := helper.ValidateTotp(user.TotpSecret, request.Code, time.Now(), user.TotpLastStep)
+		if !ok {
+			c.Log.Warnf("Invalid totp code for user %s", user.ID)
+			return nil, c.LoginThrottle.Failed(tx, userAttempt, ipAttempt)
+		}
+
+		user.TotpLastStep = step
+		if err := c.UserRepository.Update(tx, user); err != nil {
+			c.Log.Warnf("Failed save user : %+v", err)
+			return nil, helper.ErrInternalServerError
+		}
+	} else {
+		code := helper.HashToken(strings.ToLower(strings.TrimSpace(request.RecoveryCode)))
+		deleted, err := c.RecoveryCodeRepository.DeleteByUserIdAndCode(tx, user.ID, code)
+		if err != nil {
+			c.Log.Warnf("Failed delete recovery code : %+v", err)
+			return nil, helper.ErrInternalServerError
+		}
+
+		if deleted != 1 {
+			c.Log.Warnf("Invalid recovery code for user %s", user.ID)
+			return nil, c.LoginThrottle.Failed(tx, userAttempt, ipAttempt)
+		}
+	}
+
+	if err := c.LoginThrottle.Succeeded(tx, userAttempt); err != nil {
+		return nil, err
+	}
+
+	response, err := c.TokenUseCase.Open(tx, user, request.UserAgent, request.IpAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, helper.ErrInternalServerError
+	}
+
+	return response, nil
+}
+
+// rehash replaces a hash made by an older algorithm or with older parameters, the
+// password is only known in clear at login so that is where accounts are migrated
+func (c *UserUseCase) rehash(tx *gorm.DB, user *entity.User, password string) error {
+	if !c.Hasher.NeedsRehash(user.Password) {
+		return nil
+	}
+
+	hash, err := c.Hasher.Hash(password)
+	if err != nil {
+		c.Log.Warnf("Failed to hash password : %+v", err)
+		return helper.ErrInternalServerError
+	}
+
+	user.Password = hash
 	if err := c.UserRepository.Update(tx, user); err != nil {
 		c.Log.Warnf("Failed save user : %+v", err)
-		return nil, helper.ErrInternalServerError
+		return helper.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (c *UserUseCase) Refresh(ctx context.Context, request *model.RefreshTokenRequest) (*model.TokenResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, helper.ErrBadRequest
+	}
+
+	claims, err := c.TokenUseCase.Jwt.ParseRefreshToken(request.RefreshToken)
+	if err == helper.ErrTokenExpired {
+		c.Log.Warnf("Refresh token expired : %+v", err)
+		return nil, helper.ErrTokenExpired
+	}
+
+	if err != nil {
+		c.Log.Warnf("Failed parse refresh token : %+v", err)
+		return nil, helper.ErrUnauthorized
+	}
+
+	// a refresh token is only accepted once, an already rotated token id is not found anymore
+	session := new(entity.Session)
+	if err := c.SessionRepository.FindByToken(tx, session, helper.HashToken(claims.ID)); err != nil || session.ID != claims.SessionId {
+		c.Log.Warnf("Failed find session by refresh token : %+v", err)
+		return nil, helper.ErrUnauthorized
+	}
+
+	if c.TokenUseCase.IsExpired(session) {
+		c.Log.Warnf("Session %s expired", session.ID)
+		if err := c.SessionRepository.Delete(tx, session); err != nil {
+			c.Log.Warnf("Failed delete session : %+v", err)
+			return nil, helper.ErrInternalServerError
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			c.Log.Warnf("Failed commit transaction : %+v", err)
+			return nil, helper.ErrInternalServerError
+		}
+
+		return nil, helper.ErrTokenExpired
+	}
+
+	session.UserAgent = request.UserAgent
+	session.IpAddress = request.IpAddress
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, session.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, helper.ErrUnauthorized
+	}
+
+	if err := c.TokenUseCase.CheckActive(user); err != nil {
+		return nil, err
+	}
+
+	if err := c.checkImpersonator(tx, session); err != nil {
+		return nil, err
+	}
+
+	// refreshing is outside the audited routes, the admin keeping the session alive is recorded here
+	if session.ImpersonatorId != "" {
+		auditLog := &entity.AuditLog{
+			ID:             uuid.New().String(),
+			Action:         model.AuditActionRefresh,
+			SessionId:      session.ID,
+			ImpersonatorId: session.ImpersonatorId,
+			UserId:         user.ID,
+			IpAddress:      request.IpAddress,
+		}
+
+		if err := c.AuditLogRepository.Create(tx, auditLog); err != nil {
+			c.Log.Warnf("Failed create audit log : %+v", err)
+			return nil, helper.ErrInternalServerError
+		}
+	}
+
+	response, err := c.TokenUseCase.Issue(tx, session, user)
+	if err != nil {
+		return nil, err
 	}
 
 	if err := tx.Commit().Error; err != nil {
@@ -132,7 +433,7 @@ func (c *UserUseCase) Login(ctx context.Context, request *model.LoginUserRequest
 		return nil, helper.ErrInternalServerError
 	}
 
-	return converter.UserToTokenResponse(user), nil
+	return response, nil
 }
 
 func (c *UserUseCase) Current(ctx context.Context, request *model.GetUserRequest) (*model.UserResponse, error) {
@@ -167,16 +468,14 @@ func (c *UserUseCase) Logout(ctx context.Context, request *model.LogoutUserReque
 		return false, helper.ErrBadRequest
 	}
 
-	user := new(entity.User)
-	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
-		c.Log.Warnf("Failed find user by id : %+v", err)
+	session := new(entity.Session)
+	if err := c.SessionRepository.FindByIdAndUserId(tx, session, request.SessionId, request.ID); err != nil {
+		c.Log.Warnf("Failed find session by id : %+v", err)
 		return false, helper.ErrNotFound
 	}
 
-	user.Token = ""
-
-	if err := c.UserRepository.Update(tx, user); err != nil {
-		c.Log.Warnf("Failed save user : %+v", err)
+	if err := c.SessionRepository.Delete(tx, session); err != nil {
+		c.Log.Warnf("Failed delete session : %+v", err)
 		return false, helper.ErrInternalServerError
 	}
 
@@ -207,13 +506,32 @@ func (c *UserUseCase) Update(ctx context.Context, request *model.UpdateUserReque
 		user.Name = request.Name
 	}
 
+	emailChanged := false
+	if email := normalizeEmail(request.Email); email != "" && email != user.Email {
+		if err := c.checkEmailAvailable(tx, email); err != nil {
+			return nil, err
+		}
+		user.Email = email
+		user.EmailVerifiedAt = 0
+		emailChanged = true
+	}
+
 	if request.Password != "" {
-		password, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+		if err := c.PasswordPolicy.Check(tx, user, request.Password); err != nil {
+			c.Log.Warnf("Password rejected by policy : %+v", err)
+			return nil, err
+		}
+
+		password, err := c.Hasher.Hash(request.Password)
 		if err != nil {
-			c.Log.Warnf("Failed to generate bcrype hash : %+v", err)
+			c.Log.Warnf("Failed to hash password : %+v", err)
 			return nil, helper.ErrInternalServerError
 		}
-		user.Password = string(password)
+		user.Password = password
+
+		if err := c.PasswordPolicy.Remember(tx, user); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := c.UserRepository.Update(tx, user); err != nil {
@@ -226,5 +544,53 @@ func (c *UserUseCase) Update(ctx context.Context, request *model.UpdateUserReque
 		return nil, helper.ErrInternalServerError
 	}
 
+	if emailChanged {
+		c.sendEmailVerification(ctx, user)
+	}
+
 	return converter.UserToResponse(user), nil
 }
+
+// checkEmailAvailable only refuses addresses another account verified, a claim alone does not hold one
+func (c *UserUseCase) checkEmailAvailable(tx *gorm.DB, email string) error {
+	total, err := c.UserRepository.CountByVerifiedEmail(tx, email)
+	if err != nil {
+		c.Log.Warnf("Failed count user by email : %+v", err)
+		return helper.ErrInternalServerError
+	}
+
+	if total > 0 {
+		c.Log.Warnf("Email %s is already used", email)
+		return helper.ErrConflict
+	}
+
+	return nil
+}
+
+// sendEmailVerification only logs a failure, the change it follows is already committed
+func (c *UserUseCase) sendEmailVerification(ctx context.Context, user *entity.User) {
+	request := &model.SendEmailVerificationRequest{UserId: user.ID}
+	if _, err := c.EmailVerificationUseCase.Send(ctx, request); err != nil {
+		c.Log.Warnf("Failed send email verification to user %s : %+v", user.ID, err)
+	}
+}
+
+// checkImpersonator ends an impersonation as soon as the admin behind it lost the role or the account
+func (c *UserUseCase) checkImpersonator(tx *gorm.DB, session *entity.Session) error {
+	if session.ImpersonatorId == "" {
+		return nil
+	}
+
+	admin := new(entity.User)
+	if err := c.UserRepository.FindById(tx, admin, session.ImpersonatorId); err != nil {
+		c.Log.Warnf("Failed find impersonator by id : %+v", err)
+		return helper.ErrUnauthorized
+	}
+
+	if admin.Role != model.RoleAdmin || admin.Disabled || admin.DeleteAt != 0 {
+		c.Log.Warnf("Impersonator %s of session %s is no longer an active admin", admin.ID, session.ID)
+		return helper.ErrUnauthorized
+	}
+
+	return nil
+}
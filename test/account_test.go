@@ -0,0 +1,192 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/repository"
+	"github.com/iyasz/golang-clean-architecture/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteAccountWrongPassword(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	assert.Equal(t, http.StatusUnauthorized, deleteAccountStatus(t, user, "salah"))
+
+	var count int64
+	err := db.Model(&entity.User{}).Where("id = ? AND delete_at = 0", user.ID).Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestDeleteAccountLocked(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	// a stolen access token does not give unlimited password guesses
+	for i := 0; i < conf.Login.MaxAttempts; i++ {
+		assert.Equal(t, http.StatusUnauthorized, deleteAccountStatus(t, user, "salah"))
+		ResetLoginDelay(t)
+	}
+
+	assert.Equal(t, http.StatusLocked, deleteAccountStatus(t, user, "rahasia"))
+	assert.Equal(t, http.StatusLocked, LoginStatus(t, user.ID, "rahasia"))
+}
+
+func TestDeleteAccountWithoutPassword(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	err := db.Model(user).Update("password", "").Error
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusConflict, deleteAccountStatus(t, user, "rahasia"))
+	GetUser(t, user.ID)
+}
+
+func TestDeleteAccountEndpoint(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	assert.Equal(t, http.StatusOK, deleteAccountStatus(t, user, "rahasia"))
+
+	// removed at once or pending deletion depending on the grace period, either way it cannot login
+	assert.NotEqual(t, http.StatusOK, LoginStatus(t, user.ID, "rahasia"))
+}
+
+func TestDeleteAccount(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	CreateContacts(user, 2)
+	CreateAddresses(t, GetFirstContact(t, user), 2)
+	CreateApiKey(t, user, model.ScopeContactsRead)
+	CreateSession(t, user)
+
+	response, err := newAccountUseCase(0).Delete(context.Background(), &model.DeleteUserRequest{ID: user.ID, Password: "rahasia"})
+	assert.Nil(t, err)
+	assert.NotZero(t, response.DeleteAt)
+
+	for _, table := range []any{&entity.User{}, &entity.Contact{}, &entity.Address{}, &entity.ApiKey{}, &entity.Session{}} {
+		var count int64
+		err := db.Model(table).Count(&count).Error
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), count)
+	}
+}
+
+func TestDeleteAccountGracePeriod(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	CreateContacts(user, 2)
+	CreateSession(t, user)
+
+	response, err := newAccountUseCase(time.Hour).Delete(context.Background(), &model.DeleteUserRequest{ID: user.ID, Password: "rahasia"})
+	assert.Nil(t, err)
+	assert.Greater(t, response.DeleteAt, time.Now().UnixMilli())
+
+	// logged out everywhere but the data is kept until the purge
+	var count int64
+	err = db.Model(&entity.Session{}).Where("user_id = ?", user.ID).Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+
+	err = db.Model(&entity.Contact{}).Where("user_id = ?", user.ID).Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), count)
+
+	assert.Equal(t, http.StatusForbidden, LoginStatus(t, user.ID, "rahasia"))
+
+	status, restored := restoreAccount(t, user.ID, "rahasia")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Zero(t, restored.DeleteAt)
+
+	assert.Equal(t, http.StatusOK, LoginStatus(t, user.ID, "rahasia"))
+}
+
+func TestRestoreAccountNotDeleted(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	status, _ := restoreAccount(t, user.ID, "rahasia")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestRestoreAccountAfterGracePeriod(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	err := db.Model(user).Update("delete_at", time.Now().Add(-time.Minute).UnixMilli()).Error
+	assert.Nil(t, err)
+
+	status, _ := restoreAccount(t, user.ID, "rahasia")
+	assert.Equal(t, http.StatusGone, status)
+}
+
+func TestPurgeDeletedAccounts(t *testing.T) {
+	ClearAll()
+	expired := CreateUser(t, "khannedy", model.RoleUser)
+	pending := CreateUser(t, "joko", model.RoleUser)
+	CreateContacts(expired, 2)
+	CreateAddresses(t, GetFirstContact(t, expired), 1)
+	CreateContacts(pending, 1)
+
+	err := db.Model(expired).Update("delete_at", time.Now().Add(-time.Minute).UnixMilli()).Error
+	assert.Nil(t, err)
+	err = db.Model(pending).Update("delete_at", time.Now().Add(time.Hour).UnixMilli()).Error
+	assert.Nil(t, err)
+
+	total, err := newAccountUseCase(time.Hour).PurgeDeleted(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+
+	var count int64
+	err = db.Model(&entity.User{}).Where("id = ?", expired.ID).Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+
+	err = db.Model(&entity.Contact{}).Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+
+	err = db.Model(&entity.Address{}).Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+// newAccountUseCase builds a use case with its own deletion grace period, the one behind app
+// uses whatever the test environment configures
+func newAccountUseCase(grace time.Duration) *usecase.AccountUseCase {
+	return usecase.NewAccountUseCase(db, log, validate, hasher, &usecase.AccountConfig{DeletionGrace: grace}, NewLoginThrottle(),
+		repository.NewUserRepository(log), repository.NewSessionRepository(log), repository.NewContactRepository(log),
+		repository.NewAddressRepository(log), repository.NewGroupRepository(log), repository.NewContactGroupRepository(log),
+		repository.NewContactRevisionRepository(log), repository.NewApiKeyRepository(log), repository.NewRecoveryCodeRepository(log),
+		repository.NewPasswordResetRepository(log), repository.NewEmailVerificationRepository(log), repository.NewPasswordHistoryRepository(log),
+		repository.NewUserIdentityRepository(log), repository.NewMagicLinkRepository(log))
+}
+
+func deleteAccountStatus(t *testing.T, user *entity.User, password string) int {
+	bodyJson, err := json.Marshal(model.DeleteUserRequest{Password: password})
+	assert.Nil(t, err)
+
+	resp, _ := DoRequest(t, GetAccessToken(t, user), http.MethodDelete, BaseUsersAPIURL+"/_current", string(bodyJson))
+	return resp.StatusCode
+}
+
+func restoreAccount(t *testing.T, id string, password string) (int, *model.UserResponse) {
+	bodyJson, err := json.Marshal(model.RestoreUserRequest{ID: id, Password: password})
+	assert.Nil(t, err)
+
+	resp, bytes := DoRequest(t, "", http.MethodPost, BaseUsersAPIURL+"/_restore", string(bodyJson))
+
+	responseBody := new(model.WebResponse[model.UserResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	return resp.StatusCode, &responseBody.Data
+}
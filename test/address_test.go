@@ -8,6 +8,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/google/uuid"
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
 	"github.com/iyasz/golang-clean-architecture/internal/model"
 	"github.com/stretchr/testify/assert"
 )
@@ -34,7 +36,7 @@ func TestCreateAddress(t *testing.T) {
 	req, err := http.NewRequest(http.MethodPost, server.URL+BaseContactsAPIURL+"/"+contact.ID+"/addresses", strings.NewReader(string(bodyJson)))
 	assert.Nil(t, err)
 	SetupHeader(req)
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -81,7 +83,7 @@ func TestCreateAddressFailed(t *testing.T) {
 	req, err := http.NewRequest(http.MethodPost, server.URL+BaseContactsAPIURL+"/"+contact.ID+"/addresses", strings.NewReader(string(bodyJson)))
 	assert.Nil(t, err)
 	SetupHeader(req)
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -112,7 +114,7 @@ func TestListAddresses(t *testing.T) {
 	req, err := http.NewRequest(http.MethodGet, server.URL+BaseContactsAPIURL+"/"+contact.ID+"/addresses", nil)
 	assert.Nil(t, err)
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -144,7 +146,7 @@ func TestListAddressesFailed(t *testing.T) {
 	req, err := http.NewRequest(http.MethodGet, server.URL+BaseContactsAPIURL+"/"+"wrong"+"/addresses", nil)
 	assert.Nil(t, err)
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -174,7 +176,7 @@ func TestGetAddress(t *testing.T) {
 	req, err := http.NewRequest(http.MethodGet, server.URL+BaseContactsAPIURL+"/"+contact.ID+"/addresses/"+address.ID, nil)
 	assert.Nil(t, err)
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -211,7 +213,7 @@ func TestGetAddressFailed(t *testing.T) {
 	req, err := http.NewRequest(http.MethodGet, server.URL+BaseContactsAPIURL+"/"+contact.ID+"/addresses/"+"wrong", nil)
 	assert.Nil(t, err)
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -251,7 +253,7 @@ func TestUpdateAddress(t *testing.T) {
 	req, err := http.NewRequest(http.MethodPut, server.URL+BaseContactsAPIURL+"/"+contact.ID+"/addresses/"+address.ID, strings.NewReader(string(bodyJson)))
 	assert.Nil(t, err)
 	SetupHeader(req)
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -299,7 +301,7 @@ func TestUpdateAddressFailed(t *testing.T) {
 	req, err := http.NewRequest(http.MethodPut, server.URL+BaseContactsAPIURL+"/"+contact.ID+"/addresses/"+address.ID, strings.NewReader(string(bodyJson)))
 	assert.Nil(t, err)
 	SetupHeader(req)
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -329,7 +331,7 @@ func TestDeleteAddress(t *testing.T) {
 	req, err := http.NewRequest(http.MethodDelete, server.URL+BaseContactsAPIURL+"/"+contact.ID+"/addresses/"+address.ID, nil)
 	assert.Nil(t, err)
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -360,7 +362,7 @@ func TestDeleteAddressFailed(t *testing.T) {
 	req, err := http.NewRequest(http.MethodDelete, server.URL+BaseContactsAPIURL+"/"+contact.ID+"/addresses/"+"wrong", nil)
 	assert.Nil(t, err)
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -375,4 +377,61 @@ func TestDeleteAddressFailed(t *testing.T) {
 	assert.Nil(t, err)
 
 	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
-}
\ No newline at end of file
+}
+
+func TestListAddressesSort(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	contact := CreateContact(t, user, "Eko", "Khannedy", "", "")
+	for _, city := range []string{"Bandung", "Surabaya", "Jakarta"} {
+		err := db.Create(&entity.Address{ID: uuid.NewString(), ContactId: contact.ID, City: city}).Error
+		assert.Nil(t, err)
+	}
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+BaseContactsAPIURL+"/"+contact.ID+"/addresses?sort=-city", nil)
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[[]model.AddressResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 3, len(responseBody.Data))
+	assert.Equal(t, "Surabaya", responseBody.Data[0].City)
+	assert.Equal(t, "Jakarta", responseBody.Data[1].City)
+	assert.Equal(t, "Bandung", responseBody.Data[2].City)
+}
+
+func TestListAddressesSortInvalid(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	contact := CreateContact(t, user, "Eko", "Khannedy", "", "")
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+BaseContactsAPIURL+"/"+contact.ID+"/addresses?sort=contact_id", nil)
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,176 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const BaseAdminAPIURL = "/api/admin"
+
+func TestAdminForbiddenForUser(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+BaseAdminAPIURL+"/users", nil)
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestAdminListUsers(t *testing.T) {
+	ClearAll()
+	admin := CreateUser(t, "admin", model.RoleAdmin)
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	CreateContacts(user, 3)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+BaseAdminAPIURL+"/users?key=khan", nil)
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, admin))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[[]model.AdminUserResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, len(responseBody.Data))
+	assert.Equal(t, int64(1), responseBody.Paging.TotalItem)
+	assert.Equal(t, user.ID, responseBody.Data[0].ID)
+	assert.Equal(t, model.RoleUser, responseBody.Data[0].Role)
+	assert.Equal(t, int64(3), responseBody.Data[0].ContactCount)
+}
+
+func TestAdminGetUser(t *testing.T) {
+	ClearAll()
+	admin := CreateUser(t, "admin", model.RoleAdmin)
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	CreateContacts(user, 2)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+BaseAdminAPIURL+"/users/"+user.ID, nil)
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, admin))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.AdminUserResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, user.ID, responseBody.Data.ID)
+	assert.Equal(t, int64(2), responseBody.Data.ContactCount)
+	assert.False(t, responseBody.Data.Disabled)
+}
+
+func TestAdminDisableUser(t *testing.T) {
+	ClearAll()
+	admin := CreateUser(t, "admin", model.RoleAdmin)
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	Login(t, user.ID, "rahasia")
+
+	resp, _ := DoRequest(t, GetAccessToken(t, admin), http.MethodPost, BaseAdminAPIURL+"/users/"+user.ID+"/_disable", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var count int64
+	err := db.Model(&entity.Session{}).Where("user_id = ?", user.ID).Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+
+	assert.Equal(t, http.StatusForbidden, LoginStatus(t, user.ID, "rahasia"))
+
+	resp, _ = DoRequest(t, GetAccessToken(t, admin), http.MethodPost, BaseAdminAPIURL+"/users/"+user.ID+"/_enable", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, http.StatusOK, LoginStatus(t, user.ID, "rahasia"))
+}
+
+func TestAdminDisableSelf(t *testing.T) {
+	ClearAll()
+	admin := CreateUser(t, "admin", model.RoleAdmin)
+
+	resp, _ := DoRequest(t, GetAccessToken(t, admin), http.MethodPost, BaseAdminAPIURL+"/users/"+admin.ID+"/_disable", "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestAdminUpdateRole(t *testing.T) {
+	ClearAll()
+	admin := CreateUser(t, "admin", model.RoleAdmin)
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	resp, _ := DoRequest(t, GetAccessToken(t, admin), http.MethodPut, BaseAdminAPIURL+"/users/"+user.ID+"/role", `{"role":"root"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp, _ = DoRequest(t, GetAccessToken(t, admin), http.MethodPut, BaseAdminAPIURL+"/users/"+user.ID+"/role", `{"role":"admin"}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// the role is carried by tokens issued from now on
+	token := Login(t, user.ID, "rahasia")
+	claims, err := jwt.ParseAccessToken(token.AccessToken)
+	assert.Nil(t, err)
+	assert.Equal(t, model.RoleAdmin, claims.Role)
+
+	user = GetUser(t, user.ID)
+	resp, _ = DoRequest(t, GetAccessToken(t, user), http.MethodGet, BaseAdminAPIURL+"/users", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// a demotion does not wait for the access token to expire
+	resp, _ = DoRequest(t, GetAccessToken(t, admin), http.MethodPut, BaseAdminAPIURL+"/users/"+user.ID+"/role", `{"role":"user"}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, _ = DoRequest(t, token.AccessToken, http.MethodGet, BaseAdminAPIURL+"/users", "")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestAdminLogoutUser(t *testing.T) {
+	ClearAll()
+	admin := CreateUser(t, "admin", model.RoleAdmin)
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	Login(t, user.ID, "rahasia")
+	Login(t, user.ID, "rahasia")
+
+	resp, _ := DoRequest(t, GetAccessToken(t, admin), http.MethodDelete, BaseAdminAPIURL+"/users/"+user.ID+"/sessions", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, _ = DoRequest(t, GetAccessToken(t, admin), http.MethodDelete, BaseAdminAPIURL+"/users/unknown/sessions", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	var count int64
+	err := db.Model(&entity.Session{}).Where("user_id = ?", user.ID).Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+}
@@ -0,0 +1,191 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateApiKey(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	user := GetFirstUser(t)
+
+	requestBody := model.CreateApiKeyRequest{
+		Name:   "backup script",
+		Scopes: []string{model.ScopeContactsRead, model.ScopeAddressesRead},
+	}
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+BaseUsersAPIURL+"/_current/api-keys", strings.NewReader(string(bodyJson)))
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ApiKeyResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, requestBody.Name, responseBody.Data.Name)
+	assert.Equal(t, requestBody.Scopes, responseBody.Data.Scopes)
+	assert.True(t, strings.HasPrefix(responseBody.Data.Key, "ak_"))
+	assert.True(t, strings.HasPrefix(responseBody.Data.Key, responseBody.Data.Prefix))
+
+	// only the digest is stored
+	apiKey := new(entity.ApiKey)
+	err = db.Where("id = ?", responseBody.Data.ID).Take(apiKey).Error
+	assert.Nil(t, err)
+	assert.Equal(t, helper.HashToken(responseBody.Data.Key), apiKey.Token)
+}
+
+func TestCreateApiKeyUnknownScope(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	user := GetFirstUser(t)
+
+	requestBody := model.CreateApiKeyRequest{
+		Name:   "backup script",
+		Scopes: []string{"users:write"},
+	}
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+BaseUsersAPIURL+"/_current/api-keys", strings.NewReader(string(bodyJson)))
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestListApiKeys(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	user := GetFirstUser(t)
+	CreateApiKey(t, user, model.ScopeContactsRead)
+	CreateApiKey(t, user, model.ScopeContactsWrite)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+BaseUsersAPIURL+"/_current/api-keys", nil)
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[[]model.ApiKeyResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, len(responseBody.Data))
+	for _, apiKey := range responseBody.Data {
+		assert.Empty(t, apiKey.Key)
+		assert.NotEmpty(t, apiKey.Prefix)
+	}
+}
+
+func TestDeleteApiKey(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	user := GetFirstUser(t)
+	key := CreateApiKey(t, user, model.ScopeContactsRead)
+
+	apiKey := new(entity.ApiKey)
+	err := db.Where("user_id = ?", user.ID).Take(apiKey).Error
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodDelete, server.URL+BaseUsersAPIURL+"/_current/api-keys/"+apiKey.ID, nil)
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, apiKeyStatus(t, http.MethodGet, BaseContactsAPIURL, key))
+}
+
+func TestApiKeyScopes(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	user := GetFirstUser(t)
+	key := CreateApiKey(t, user, model.ScopeContactsRead)
+
+	assert.Equal(t, http.StatusOK, apiKeyStatus(t, http.MethodGet, BaseContactsAPIURL, key))
+	assert.Equal(t, http.StatusForbidden, apiKeyStatus(t, http.MethodPost, BaseContactsAPIURL, key))
+
+	apiKey := new(entity.ApiKey)
+	err := db.Where("user_id = ?", user.ID).Take(apiKey).Error
+	assert.Nil(t, err)
+	assert.NotZero(t, apiKey.LastUsedAt)
+}
+
+func TestApiKeyCannotManageAccount(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	key := CreateApiKey(t, GetFirstUser(t), model.ScopeContactsRead, model.ScopeContactsWrite)
+
+	assert.Equal(t, http.StatusForbidden, apiKeyStatus(t, http.MethodGet, BaseUsersAPIURL+"/_current", key))
+	assert.Equal(t, http.StatusForbidden, apiKeyStatus(t, http.MethodGet, BaseUsersAPIURL+"/_current/api-keys", key))
+}
+
+func TestApiKeyInvalid(t *testing.T) {
+	ClearAll()
+
+	assert.Equal(t, http.StatusUnauthorized, apiKeyStatus(t, http.MethodGet, BaseContactsAPIURL, "ak_wrong"))
+}
+
+func apiKeyStatus(t *testing.T, method string, path string, key string) int {
+	resp, _ := DoRequest(t, "Bearer "+key, method, path, "{}")
+	return resp.StatusCode
+}
@@ -0,0 +1,165 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContactHistory(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	resp, bytes := DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseContactsAPIURL,
+		`{"first_name": "Eko", "last_name": "Khannedy", "email": "eko@example.com", "phone": "08000000000"}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	contact := new(model.WebResponse[model.ContactResponse])
+	assert.Nil(t, json.Unmarshal(bytes, contact))
+	contactURL := BaseContactsAPIURL + "/" + contact.Data.ID
+
+	resp, _ = DoRequest(t, GetAccessToken(t, user), http.MethodPut, contactURL,
+		`{"first_name": "Eko", "last_name": "Kurniawan", "email": "eko@example.com", "phone": "08000000000"}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// saving the same values again changes nothing and records nothing
+	resp, _ = DoRequest(t, GetAccessToken(t, user), http.MethodPut, contactURL,
+		`{"first_name": "Eko", "last_name": "Kurniawan", "email": "eko@example.com", "phone": "08000000000"}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, bytes = DoRequest(t, GetAccessToken(t, user), http.MethodGet, contactURL+"/history", "")
+
+	history := new(model.WebResponse[[]model.RevisionResponse])
+	assert.Nil(t, json.Unmarshal(bytes, history))
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int64(2), history.Paging.TotalItem)
+	assert.Equal(t, model.RevisionUpdate, history.Data[0].Action)
+	assert.Equal(t, user.ID, history.Data[0].UserId)
+	assert.Equal(t, map[string]model.RevisionChange{"last_name": {Old: "Khannedy", New: "Kurniawan"}}, history.Data[0].Changes)
+	assert.Equal(t, model.RevisionCreate, history.Data[1].Action)
+	assert.Equal(t, model.RevisionChange{New: "Eko"}, history.Data[1].Changes["first_name"])
+}
+
+func TestContactHistoryOfAnotherUser(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	other := CreateUser(t, "joko", model.RoleUser)
+	CreateContacts(other, 1)
+	contact := GetFirstContact(t, other)
+
+	resp, _ := DoRequest(t, GetAccessToken(t, user), http.MethodGet, BaseContactsAPIURL+"/"+contact.ID+"/history", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestRevertContactRevision(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	CreateContacts(user, 1)
+	contact := GetFirstContact(t, user)
+	contactURL := BaseContactsAPIURL + "/" + contact.ID
+
+	resp, _ := DoRequest(t, GetAccessToken(t, user), http.MethodPut, contactURL,
+		`{"first_name": "Eko", "last_name": "Kurniawan", "email": "eko@example.com", "phone": "08000000000"}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, _ = DoRequest(t, GetAccessToken(t, user), http.MethodPut, contactURL,
+		`{"first_name": "Budi", "last_name": "Nugraha", "email": "budi@example.com", "phone": "08111111111"}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	history := contactHistory(t, user, contact.ID)
+	assert.Equal(t, 2, len(history))
+
+	// reverting the first update puts the contact back the way that update left it
+	resp, bytes := DoRequest(t, GetAccessToken(t, user), http.MethodPost, contactURL+"/history/"+history[1].ID+"/_revert", "")
+
+	reverted := new(model.WebResponse[model.RevisionResponse])
+	assert.Nil(t, json.Unmarshal(bytes, reverted))
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, model.RevisionRevert, reverted.Data.Action)
+	assert.Equal(t, model.RevisionChange{Old: "Budi", New: "Eko"}, reverted.Data.Changes["first_name"])
+
+	resp, bytes = DoRequest(t, GetAccessToken(t, user), http.MethodGet, contactURL, "")
+
+	got := new(model.WebResponse[model.ContactResponse])
+	assert.Nil(t, json.Unmarshal(bytes, got))
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "Eko", got.Data.FirstName)
+	assert.Equal(t, "Kurniawan", got.Data.LastName)
+	assert.Equal(t, "eko@example.com", got.Data.Email)
+	assert.Equal(t, "08000000000", got.Data.Phone)
+
+	history = contactHistory(t, user, contact.ID)
+	assert.Equal(t, 3, len(history))
+	assert.Equal(t, model.RevisionRevert, history[0].Action)
+}
+
+func TestRevertDeletedAddress(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	CreateContacts(user, 1)
+	contact := GetFirstContact(t, user)
+	addressesURL := BaseContactsAPIURL + "/" + contact.ID + "/addresses"
+
+	resp, bytes := DoRequest(t, GetAccessToken(t, user), http.MethodPost, addressesURL,
+		`{"street": "Jalan Belum Ada", "city": "Jakarta", "province": "DKI Jakarta", "postal_code": "343443", "country": "Indonesia"}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	address := new(model.WebResponse[model.AddressResponse])
+	assert.Nil(t, json.Unmarshal(bytes, address))
+
+	resp, _ = DoRequest(t, GetAccessToken(t, user), http.MethodDelete, addressesURL+"/"+address.Data.ID, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	history := contactHistory(t, user, contact.ID)
+	assert.Equal(t, 2, len(history))
+	assert.Equal(t, model.RevisionDelete, history[0].Action)
+	assert.Equal(t, address.Data.ID, history[0].AddressId)
+	assert.Equal(t, model.RevisionChange{Old: "Jakarta"}, history[0].Changes["city"])
+
+	resp, _ = DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseContactsAPIURL+"/"+contact.ID+"/history/"+history[1].ID+"/_revert", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, bytes = DoRequest(t, GetAccessToken(t, user), http.MethodGet, addressesURL+"/"+address.Data.ID, "")
+
+	got := new(model.WebResponse[model.AddressResponse])
+	assert.Nil(t, json.Unmarshal(bytes, got))
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "Jakarta", got.Data.City)
+}
+
+func TestRevertRevisionNotFound(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	CreateContacts(user, 2)
+	contacts := ContactIds(t, user)
+
+	resp, _ := DoRequest(t, GetAccessToken(t, user), http.MethodPut, BaseContactsAPIURL+"/"+contacts[0],
+		`{"first_name": "Eko", "last_name": "Kurniawan", "email": "eko@example.com", "phone": "08000000000"}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	revision := contactHistory(t, user, contacts[0])[0]
+
+	// a revision only reverts through the contact it belongs to
+	resp, _ = DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseContactsAPIURL+"/"+contacts[1]+"/history/"+revision.ID+"/_revert", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseContactsAPIURL+"/"+contacts[0]+"/history/"+uuid.NewString()+"/_revert", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func contactHistory(t *testing.T, user *entity.User, contactId string) []model.RevisionResponse {
+	resp, bytes := DoRequest(t, GetAccessToken(t, user), http.MethodGet, BaseContactsAPIURL+"/"+contactId+"/history", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	history := new(model.WebResponse[[]model.RevisionResponse])
+	assert.Nil(t, json.Unmarshal(bytes, history))
+	return history.Data
+}
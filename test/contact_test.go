@@ -36,7 +36,7 @@ func TestCreateContact(t *testing.T) {
 	req, err := http.NewRequest(http.MethodPost, server.URL+BaseContactsAPIURL, strings.NewReader(string(bodyJson)))
 	assert.Nil(t, err)
 	SetupHeader(req)
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -82,7 +82,7 @@ func TestCreateContactFailed(t *testing.T) {
 	req, err := http.NewRequest(http.MethodPost, server.URL+BaseContactsAPIURL, strings.NewReader(string(bodyJson)))
 	assert.Nil(t, err)
 	SetupHeader(req)
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -117,7 +117,7 @@ func TestGetContact(t *testing.T) {
 	req, err := http.NewRequest(http.MethodGet, server.URL+BaseContactsAPIURL+"/"+contact.ID, nil)
 	assert.Nil(t, err)
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -154,7 +154,7 @@ func TestGetContactFailed(t *testing.T) {
 	req, err := http.NewRequest(http.MethodGet, server.URL+BaseContactsAPIURL+"/"+uuid.NewString(), nil)
 	assert.Nil(t, err)
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -197,7 +197,7 @@ func TestUpdateContact(t *testing.T) {
 	req, err := http.NewRequest(http.MethodPut, server.URL+BaseContactsAPIURL+"/"+contact.ID, strings.NewReader(string(bodyJson)))
 	assert.Nil(t, err)
 	SetupHeader(req)
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -247,7 +247,7 @@ func TestUpdateContactFailed(t *testing.T) {
 	req, err := http.NewRequest(http.MethodPut, server.URL+BaseContactsAPIURL+"/"+contact.ID, strings.NewReader(string(bodyJson)))
 	assert.Nil(t, err)
 	SetupHeader(req)
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -286,7 +286,7 @@ func TestUpdateContactNotFound(t *testing.T) {
 	req, err := http.NewRequest(http.MethodPut, server.URL+BaseContactsAPIURL+"/"+uuid.NewString(), strings.NewReader(string(bodyJson)))
 	assert.Nil(t, err)
 	SetupHeader(req)
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -320,7 +320,7 @@ func TestDeleteContact(t *testing.T) {
 	req, err := http.NewRequest(http.MethodDelete, server.URL+BaseContactsAPIURL+"/"+contact.ID, nil)
 	assert.Nil(t, err)
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -351,7 +351,7 @@ func TestDeleteContactFailed(t *testing.T) {
 	req, err := http.NewRequest(http.MethodDelete, server.URL+BaseContactsAPIURL+"/"+uuid.NewString(), nil)
 	assert.Nil(t, err)
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -383,7 +383,7 @@ func TestSearchContact(t *testing.T) {
 	req, err := http.NewRequest(http.MethodGet, server.URL+BaseContactsAPIURL, nil)
 	assert.Nil(t, err)
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -420,7 +420,7 @@ func TestSearchContactWithPagination(t *testing.T) {
 	req, err := http.NewRequest(http.MethodGet, server.URL+BaseContactsAPIURL+"?page=2&size=5", nil)
 	assert.Nil(t, err)
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -457,7 +457,7 @@ func TestSearchContactWithFilter(t *testing.T) {
 	req, err := http.NewRequest(http.MethodGet, server.URL+BaseContactsAPIURL+"?name=contact&phone=08000000&email=example.com", nil)
 	assert.Nil(t, err)
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -477,4 +477,241 @@ func TestSearchContactWithFilter(t *testing.T) {
 	assert.Equal(t, int64(2), responseBody.Paging.TotalPage)
 	assert.Equal(t, 1, responseBody.Paging.Page)
 	assert.Equal(t, 10, responseBody.Paging.Size)
-}
\ No newline at end of file
+}
+func TestSearchContactFullText(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	john := CreateContact(t, user, "John", "Smith", "john@example.com", "0811111111")
+	CreateContact(t, user, "Jane", "Doe", "jane@example.com", "0822222222")
+
+	responseBody := SearchContacts(t, user, "q=smith+john")
+	assert.Equal(t, int64(1), responseBody.Paging.TotalItem)
+	assert.Equal(t, john.ID, responseBody.Data[0].ID)
+
+	responseBody = SearchContacts(t, user, "q=SMI")
+	assert.Equal(t, int64(1), responseBody.Paging.TotalItem)
+
+	responseBody = SearchContacts(t, user, "q=jane@example.com")
+	assert.Equal(t, int64(1), responseBody.Paging.TotalItem)
+
+	responseBody = SearchContacts(t, user, "q=0822")
+	assert.Equal(t, int64(1), responseBody.Paging.TotalItem)
+
+	responseBody = SearchContacts(t, user, "q=nobody")
+	assert.Equal(t, int64(0), responseBody.Paging.TotalItem)
+}
+
+func TestSearchContactFullTextAddress(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	bandung := CreateContact(t, user, "Bandung", "Lover", "", "")
+	john := CreateContact(t, user, "John", "Smith", "", "")
+	CreateAddresses(t, john, 1)
+
+	// the address of john is indexed through the trigger on addresses
+	responseBody := SearchContacts(t, user, "q=jakarta")
+	assert.Equal(t, int64(1), responseBody.Paging.TotalItem)
+	assert.Equal(t, john.ID, responseBody.Data[0].ID)
+
+	address := GetFirstAddress(t, john)
+	address.City = "Bandung"
+	assert.Nil(t, db.Save(address).Error)
+
+	// a match on the name ranks above a match on an address
+	responseBody = SearchContacts(t, user, "q=bandung")
+	assert.Equal(t, int64(2), responseBody.Paging.TotalItem)
+	assert.Equal(t, bandung.ID, responseBody.Data[0].ID)
+	assert.Equal(t, john.ID, responseBody.Data[1].ID)
+
+	assert.Nil(t, db.Delete(address).Error)
+
+	responseBody = SearchContacts(t, user, "q=jakarta")
+	assert.Equal(t, int64(0), responseBody.Paging.TotalItem)
+}
+
+func CreateContact(t *testing.T, user *entity.User, firstName string, lastName string, email string, phone string) *entity.Contact {
+	contact := &entity.Contact{
+		ID:        uuid.NewString(),
+		FirstName: firstName,
+		LastName:  lastName,
+		Email:     email,
+		Phone:     phone,
+		UserId:    user.ID,
+	}
+	err := db.Create(contact).Error
+	assert.Nil(t, err)
+	return contact
+}
+
+func SearchContacts(t *testing.T, user *entity.User, query string) *model.WebResponse[[]model.ContactResponse] {
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+BaseContactsAPIURL+"?"+query, nil)
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[[]model.ContactResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	return responseBody
+}
+
+func TestSearchContactFuzzy(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	eko := CreateContact(t, user, "Eko", "Khannedy", "eko@example.com", "")
+	CreateContact(t, user, "Budi", "Nugraha", "budi@example.com", "")
+
+	// full text search needs the words spelled right
+	responseBody := SearchContacts(t, user, "q=khanedy")
+	assert.Equal(t, int64(0), responseBody.Paging.TotalItem)
+
+	responseBody = SearchContacts(t, user, "q=khanedy&fuzzy=true")
+	assert.Equal(t, int64(1), responseBody.Paging.TotalItem)
+	assert.Equal(t, eko.ID, responseBody.Data[0].ID)
+	assert.Greater(t, responseBody.Data[0].Score, 0.3)
+
+	responseBody = SearchContacts(t, user, "q=eko@exampel.com&fuzzy=true")
+	assert.Equal(t, int64(2), responseBody.Paging.TotalItem)
+	assert.Equal(t, eko.ID, responseBody.Data[0].ID)
+	assert.GreaterOrEqual(t, responseBody.Data[0].Score, responseBody.Data[1].Score)
+}
+
+func TestSearchContactSort(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	budi := CreateContact(t, user, "Budi", "Nugraha", "", "")
+	eko := CreateContact(t, user, "Eko", "Khannedy", "", "")
+	joko := CreateContact(t, user, "Joko", "Khannedy", "", "")
+
+	responseBody := SearchContacts(t, user, "sort=last_name,-first_name")
+	assert.Equal(t, 3, len(responseBody.Data))
+	assert.Equal(t, joko.ID, responseBody.Data[0].ID)
+	assert.Equal(t, eko.ID, responseBody.Data[1].ID)
+	assert.Equal(t, budi.ID, responseBody.Data[2].ID)
+
+	// pages never overlap, even when every sorted value is the same
+	first := SearchContacts(t, user, "sort=last_name&size=2&page=1")
+	second := SearchContacts(t, user, "sort=last_name&size=2&page=2")
+	assert.Equal(t, 2, len(first.Data))
+	assert.Equal(t, 1, len(second.Data))
+	assert.NotContains(t, []string{first.Data[0].ID, first.Data[1].ID}, second.Data[0].ID)
+}
+
+func TestSearchContactSortInvalid(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+BaseContactsAPIURL+"?sort=user_id", nil)
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestSearchContactCursor(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	CreateContacts(user, 5)
+
+	first := SearchContacts(t, user, "pagination=cursor&sort=last_name&size=2")
+	assert.Equal(t, []string{"0", "1"}, contactLastNames(first.Data))
+	assert.Equal(t, int64(5), first.Paging.TotalItem)
+	assert.Equal(t, int64(3), first.Paging.TotalPage)
+	assert.NotEmpty(t, first.Paging.NextCursor)
+	assert.Empty(t, first.Paging.PrevCursor)
+
+	second := SearchContacts(t, user, "sort=last_name&size=2&cursor="+first.Paging.NextCursor)
+	assert.Equal(t, []string{"2", "3"}, contactLastNames(second.Data))
+	assert.NotEmpty(t, second.Paging.NextCursor)
+	assert.NotEmpty(t, second.Paging.PrevCursor)
+
+	// a contact added in front does not shift the following pages
+	CreateContact(t, user, "contact", "00", "", "")
+
+	third := SearchContacts(t, user, "sort=last_name&size=2&cursor="+second.Paging.NextCursor)
+	assert.Equal(t, []string{"4"}, contactLastNames(third.Data))
+	assert.Empty(t, third.Paging.NextCursor)
+	assert.NotEmpty(t, third.Paging.PrevCursor)
+
+	back := SearchContacts(t, user, "sort=last_name&size=2&cursor="+third.Paging.PrevCursor)
+	assert.Equal(t, []string{"2", "3"}, contactLastNames(back.Data))
+	assert.NotEmpty(t, back.Paging.NextCursor)
+	assert.NotEmpty(t, back.Paging.PrevCursor)
+
+	front := SearchContacts(t, user, "sort=last_name&size=2&cursor="+back.Paging.PrevCursor)
+	assert.Equal(t, []string{"00", "1"}, contactLastNames(front.Data))
+	assert.NotEmpty(t, front.Paging.PrevCursor)
+}
+
+func TestSearchContactCursorDescending(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	CreateContacts(user, 3)
+	CreateContact(t, user, "contact", "", "", "")
+
+	// nullable columns page like empty strings
+	first := SearchContacts(t, user, "pagination=cursor&sort=-last_name&size=3&skip_count=true")
+	assert.Equal(t, []string{"2", "1", "0"}, contactLastNames(first.Data))
+	assert.Equal(t, int64(-1), first.Paging.TotalItem)
+	assert.Equal(t, int64(-1), first.Paging.TotalPage)
+
+	second := SearchContacts(t, user, "sort=-last_name&size=3&cursor="+first.Paging.NextCursor)
+	assert.Equal(t, []string{""}, contactLastNames(second.Data))
+	assert.Empty(t, second.Paging.NextCursor)
+}
+
+func TestSearchContactCursorInvalid(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	CreateContacts(user, 3)
+
+	first := SearchContacts(t, user, "pagination=cursor&sort=last_name&size=2")
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	// a cursor is only good for the sort it was made with
+	for _, query := range []string{"cursor=garbage", "sort=created_at&cursor=" + first.Paging.NextCursor} {
+		req, err := http.NewRequest(http.MethodGet, server.URL+BaseContactsAPIURL+"?"+query, nil)
+		assert.Nil(t, err)
+		SetupHeader(req)
+		req.Header.Set("Authorization", GetAccessToken(t, user))
+
+		client := &http.Client{}
+		resp, err := client.Do(req)
+		assert.Nil(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func contactLastNames(contacts []model.ContactResponse) []string {
+	lastNames := make([]string, len(contacts))
+	for i, contact := range contacts {
+		lastNames[i] = contact.LastName
+	}
+	return lastNames
+}
@@ -0,0 +1,136 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListDuplicates(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	eko := CreateContact(t, user, "Eko", "Khannedy", "eko@example.com", "081234567")
+	budi := CreateContact(t, user, "Budi", "Nugraha", "EKO@Example.com", "")
+	joko := CreateContact(t, user, "Joko", "Morro", "joko@example.com", "(0812) 34567")
+	rully := CreateContact(t, user, "Rully", "Nugraha", "rully@example.com", "089999999")
+	rul := CreateContact(t, user, "Rully", "Nugrah", "rul@example.com", "087777777")
+	CreateContact(t, user, "Siti", "Aminah", "siti@example.com", "086666666")
+
+	// neither the trash nor other users count
+	trashed := CreateContact(t, user, "Eko", "Trash", "eko@example.com", "")
+	assert.Nil(t, db.Delete(trashed).Error)
+	other := CreateUser(t, "joko", model.RoleUser)
+	CreateContact(t, other, "Eko", "Khannedy", "eko@example.com", "081234567")
+
+	resp, bytes := DoRequest(t, GetAccessToken(t, user), http.MethodGet, BaseContactsAPIURL+"/_duplicates", "")
+
+	responseBody := new(model.WebResponse[[]model.DuplicateResponse])
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int64(2), responseBody.Paging.TotalItem)
+
+	// contacts created in the same millisecond come in any order
+	clusters := make(map[string][]string)
+	for _, duplicate := range responseBody.Data {
+		clusters[strings.Join(duplicate.Reasons, ",")] = duplicateIds(duplicate)
+	}
+	assert.ElementsMatch(t, []string{eko.ID, budi.ID, joko.ID}, clusters["email,phone"])
+	assert.ElementsMatch(t, []string{rully.ID, rul.ID}, clusters["name"])
+}
+
+func TestMergeContacts(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	target := CreateContact(t, user, "Eko", "Khannedy", "", "081234567")
+	source := CreateContact(t, user, "Eko K", "", "eko@example.com", "0899999999")
+	CreateAddresses(t, target, 1)
+	CreateAddresses(t, source, 2)
+	group := CreateGroup(t, user, "Family")
+	AddToGroup(t, group, source.ID)
+
+	resp, bytes := DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseContactsAPIURL+"/_merge",
+		`{"target_id": "`+target.ID+`", "source_ids": ["`+source.ID+`"]}`)
+
+	responseBody := new(model.WebResponse[model.ContactResponse])
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+	// the target keeps its values and only takes the email it did not have
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, target.ID, responseBody.Data.ID)
+	assert.Equal(t, "Eko", responseBody.Data.FirstName)
+	assert.Equal(t, "Khannedy", responseBody.Data.LastName)
+	assert.Equal(t, "eko@example.com", responseBody.Data.Email)
+	assert.Equal(t, "081234567", responseBody.Data.Phone)
+	assert.Equal(t, 3, len(responseBody.Data.Addresses))
+
+	resp, _ = DoRequest(t, GetAccessToken(t, user), http.MethodGet, BaseContactsAPIURL+"/"+source.ID, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	var count int64
+	assert.Nil(t, db.Model(&entity.ContactGroup{}).Where("contact_id = ?", target.ID).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	history := contactHistory(t, user, target.ID)
+	assert.Equal(t, model.RevisionMerge, history[0].Action)
+	assert.Equal(t, model.RevisionChange{New: "eko@example.com"}, history[0].Changes["email"])
+}
+
+func TestMergeContactsNewest(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	target := CreateContact(t, user, "Eko", "Khannedy", "eko@example.com", "081234567")
+	source := CreateContact(t, user, "Eko", "Kurniawan", "", "0899999999")
+	assert.Nil(t, db.Model(source).Update("updated_at", time.Now().Add(time.Hour).UnixMilli()).Error)
+
+	resp, bytes := DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseContactsAPIURL+"/_merge",
+		`{"target_id": "`+target.ID+`", "source_ids": ["`+source.ID+`"], "strategy": "newest", "fields": {"phone": "`+target.ID+`"}}`)
+
+	responseBody := new(model.WebResponse[model.ContactResponse])
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+	// the newer source wins where it has a value, the phone is picked explicitly
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "Kurniawan", responseBody.Data.LastName)
+	assert.Equal(t, "eko@example.com", responseBody.Data.Email)
+	assert.Equal(t, "081234567", responseBody.Data.Phone)
+}
+
+func TestMergeContactsFailed(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	other := CreateUser(t, "joko", model.RoleUser)
+	target := CreateContact(t, user, "Eko", "Khannedy", "eko@example.com", "")
+	source := CreateContact(t, user, "Eko", "Kurniawan", "", "")
+	foreign := CreateContact(t, other, "Eko", "Khannedy", "eko@example.com", "")
+
+	resp, _ := DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseContactsAPIURL+"/_merge",
+		`{"target_id": "`+target.ID+`", "source_ids": ["`+target.ID+`"]}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, _ = DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseContactsAPIURL+"/_merge",
+		`{"target_id": "`+target.ID+`", "source_ids": ["`+source.ID+`"], "fields": {"email": "`+foreign.ID+`"}}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, _ = DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseContactsAPIURL+"/_merge",
+		`{"target_id": "`+target.ID+`", "source_ids": ["`+source.ID+`", "`+foreign.ID+`"]}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// nothing is merged by a failed request
+	resp, _ = DoRequest(t, GetAccessToken(t, user), http.MethodGet, BaseContactsAPIURL+"/"+source.ID, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func duplicateIds(duplicate model.DuplicateResponse) []string {
+	ids := make([]string, len(duplicate.Contacts))
+	for i, contact := range duplicate.Contacts {
+		ids[i] = contact.ID
+	}
+	return ids
+}
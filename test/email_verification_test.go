@@ -0,0 +1,186 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyEmail(t *testing.T) {
+	ClearAll()
+	mailer.Clear()
+	TestRegister(t)
+
+	status, response := verifyEmail(t, GetVerificationToken(t, "khannedy@example.com"))
+	assert.Equal(t, http.StatusOK, status)
+	assert.True(t, response.EmailVerified)
+
+	user := GetUser(t, "khannedy")
+	assert.NotZero(t, user.EmailVerifiedAt)
+
+	var total int64
+	err := db.Model(&entity.EmailVerification{}).Where("user_id = ?", user.ID).Count(&total).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), total)
+}
+
+func TestVerifyEmailInvalidToken(t *testing.T) {
+	ClearAll()
+
+	status, _ := verifyEmail(t, "salah")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestVerifyEmailUsedTwice(t *testing.T) {
+	ClearAll()
+	mailer.Clear()
+	TestRegister(t)
+
+	token := GetVerificationToken(t, "khannedy@example.com")
+
+	status, _ := verifyEmail(t, token)
+	assert.Equal(t, http.StatusOK, status)
+
+	status, _ = verifyEmail(t, token)
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestVerifyEmailAfterEmailChange(t *testing.T) {
+	ClearAll()
+	mailer.Clear()
+	TestRegister(t)
+
+	token := GetVerificationToken(t, "khannedy@example.com")
+
+	err := db.Model(&entity.User{}).Where("id = ?", "khannedy").Update("email", "eko@example.com").Error
+	assert.Nil(t, err)
+
+	status, _ := verifyEmail(t, token)
+	assert.Equal(t, http.StatusGone, status)
+}
+
+func TestSendEmailVerification(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+	mailer.Clear()
+
+	assert.Equal(t, http.StatusOK, sendEmailVerificationStatus(t, GetUser(t, "khannedy")))
+	assert.NotEmpty(t, GetVerificationToken(t, "khannedy@example.com"))
+}
+
+func TestSendEmailVerificationAlreadyVerified(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	assert.Equal(t, http.StatusConflict, sendEmailVerificationStatus(t, user))
+}
+
+func TestRegisterDuplicateEmail(t *testing.T) {
+	ClearAll()
+	CreateUser(t, "joko", model.RoleUser)
+
+	requestBody := model.RegisterUserRequest{
+		ID:       "khannedy",
+		Password: "rahasia",
+		Name:     "Eko Khannedy",
+		Email:    " Joko@Example.com",
+	}
+
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+BaseUsersAPIURL, strings.NewReader(string(bodyJson)))
+	assert.Nil(t, err)
+	SetupHeader(req)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+}
+
+func TestRegisterUnverifiedEmailClaim(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+	mailer.Clear()
+
+	// an address nobody verified is not taken, the owner can still register with it
+	status := registerStatus(t, model.RegisterUserRequest{ID: "eko", Password: "rahasia", Name: "Eko", Email: "khannedy@example.com"})
+	assert.Equal(t, http.StatusOK, status)
+
+	status, _ = verifyEmail(t, GetVerificationToken(t, "khannedy@example.com"))
+	assert.Equal(t, http.StatusOK, status)
+
+	assert.NotZero(t, GetUser(t, "eko").EmailVerifiedAt)
+	assert.Equal(t, "", GetUser(t, "khannedy").Email)
+}
+
+func TestRegisterWithoutEmail(t *testing.T) {
+	ClearAll()
+	mailer.Clear()
+
+	status := registerStatus(t, model.RegisterUserRequest{ID: "khannedy", Password: "rahasia", Name: "Eko Khannedy"})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "", GetUser(t, "khannedy").Email)
+}
+
+func TestRegisterIdLikeEmail(t *testing.T) {
+	ClearAll()
+
+	// an id with @ could shadow the email login of another account
+	status := registerStatus(t, model.RegisterUserRequest{ID: "joko@example.com", Password: "rahasia", Name: "Joko"})
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestLoginByVerifiedEmail(t *testing.T) {
+	ClearAll()
+	CreateUser(t, "khannedy", model.RoleUser)
+
+	response := Login(t, "Khannedy@example.com", "rahasia")
+	assert.NotEmpty(t, response.AccessToken)
+}
+
+func TestLoginByUnverifiedEmail(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	assert.Equal(t, http.StatusUnauthorized, LoginStatus(t, "khannedy@example.com", "rahasia"))
+	assert.Equal(t, http.StatusOK, LoginStatus(t, "khannedy", "rahasia"))
+}
+
+func registerStatus(t *testing.T, requestBody model.RegisterUserRequest) int {
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	resp, _ := DoRequest(t, "", http.MethodPost, BaseUsersAPIURL, string(bodyJson))
+	return resp.StatusCode
+}
+
+func verifyEmail(t *testing.T, token string) (int, *model.UserResponse) {
+	bodyJson, err := json.Marshal(model.VerifyEmailRequest{Token: token})
+	assert.Nil(t, err)
+
+	resp, bytes := DoRequest(t, "", http.MethodPost, BaseUsersAPIURL+"/_verify-email", string(bodyJson))
+
+	responseBody := new(model.WebResponse[model.UserResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	return resp.StatusCode, &responseBody.Data
+}
+
+func sendEmailVerificationStatus(t *testing.T, user *entity.User) int {
+	resp, _ := DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseUsersAPIURL+"/_current/_send-verification", "")
+	return resp.StatusCode
+}
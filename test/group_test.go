@@ -0,0 +1,292 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const BaseGroupsAPIURL = "/api/groups"
+
+func TestCreateGroup(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	resp, bytes := DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseGroupsAPIURL, `{"name": "Family"}`)
+
+	responseBody := new(model.WebResponse[model.GroupResponse])
+	err := json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "Family", responseBody.Data.Name)
+	assert.Equal(t, int64(0), responseBody.Data.ContactCount)
+	assert.NotEmpty(t, responseBody.Data.ID)
+}
+
+func TestCreateGroupDuplicateName(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	CreateGroup(t, user, "Family")
+
+	resp, _ := DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseGroupsAPIURL, `{"name": "Family"}`)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	// names only have to be unique per user
+	other := CreateUser(t, "other", model.RoleUser)
+	resp, _ = DoRequest(t, GetAccessToken(t, other), http.MethodPost, BaseGroupsAPIURL, `{"name": "Family"}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+}
+
+func TestCreateGroupFailed(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	resp, _ := DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseGroupsAPIURL, `{"name": ""}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestListGroups(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	CreateContacts(user, 2)
+	family := CreateGroup(t, user, "Family")
+	CreateGroup(t, user, "Work")
+	AddToGroup(t, family, ContactIds(t, user)...)
+
+	other := CreateUser(t, "other", model.RoleUser)
+	CreateGroup(t, other, "Friends")
+
+	resp, bytes := DoRequest(t, GetAccessToken(t, user), http.MethodGet, BaseGroupsAPIURL, "")
+
+	responseBody := new(model.WebResponse[[]model.GroupResponse])
+	err := json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, len(responseBody.Data))
+	assert.Equal(t, "Family", responseBody.Data[0].Name)
+	assert.Equal(t, int64(2), responseBody.Data[0].ContactCount)
+	assert.Equal(t, "Work", responseBody.Data[1].Name)
+	assert.Equal(t, int64(0), responseBody.Data[1].ContactCount)
+}
+
+func TestGetGroupOfAnotherUser(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	other := CreateUser(t, "other", model.RoleUser)
+	group := CreateGroup(t, other, "Friends")
+
+	resp, _ := DoRequest(t, GetAccessToken(t, user), http.MethodGet, BaseGroupsAPIURL+"/"+group.ID, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestUpdateGroup(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	group := CreateGroup(t, user, "Family")
+	CreateGroup(t, user, "Work")
+
+	resp, bytes := DoRequest(t, GetAccessToken(t, user), http.MethodPut, BaseGroupsAPIURL+"/"+group.ID, `{"name": "Relatives"}`)
+
+	responseBody := new(model.WebResponse[model.GroupResponse])
+	err := json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "Relatives", responseBody.Data.Name)
+
+	resp, _ = DoRequest(t, GetAccessToken(t, user), http.MethodPut, BaseGroupsAPIURL+"/"+group.ID, `{"name": "Work"}`)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+}
+
+func TestDeleteGroupKeepsContacts(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	CreateContacts(user, 2)
+	group := CreateGroup(t, user, "Family")
+	AddToGroup(t, group, ContactIds(t, user)...)
+
+	resp, _ := DoRequest(t, GetAccessToken(t, user), http.MethodDelete, BaseGroupsAPIURL+"/"+group.ID, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var groups, members, contacts int64
+	assert.Nil(t, db.Model(&entity.Group{}).Count(&groups).Error)
+	assert.Nil(t, db.Model(&entity.ContactGroup{}).Count(&members).Error)
+	assert.Nil(t, db.Model(&entity.Contact{}).Count(&contacts).Error)
+	assert.Equal(t, int64(0), groups)
+	assert.Equal(t, int64(0), members)
+	assert.Equal(t, int64(2), contacts)
+}
+
+func TestAddGroupMembers(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	CreateContacts(user, 3)
+	group := CreateGroup(t, user, "Family")
+	contactIds := ContactIds(t, user)
+	AddToGroup(t, group, contactIds[0])
+
+	// adding a contact twice is not an error
+	body, err := json.Marshal(model.GroupMembersRequest{ContactIds: contactIds})
+	assert.Nil(t, err)
+	resp, bytes := DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseGroupsAPIURL+"/"+group.ID+"/members", string(body))
+
+	responseBody := new(model.WebResponse[model.GroupResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int64(3), responseBody.Data.ContactCount)
+}
+
+func TestAddGroupMembersOfAnotherUser(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	other := CreateUser(t, "other", model.RoleUser)
+	CreateContacts(user, 1)
+	CreateContacts(other, 1)
+	group := CreateGroup(t, user, "Family")
+
+	body, err := json.Marshal(model.GroupMembersRequest{ContactIds: append(ContactIds(t, user), ContactIds(t, other)...)})
+	assert.Nil(t, err)
+	resp, _ := DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseGroupsAPIURL+"/"+group.ID+"/members", string(body))
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	var members int64
+	assert.Nil(t, db.Model(&entity.ContactGroup{}).Count(&members).Error)
+	assert.Equal(t, int64(0), members)
+}
+
+func TestAddGroupMembersFailed(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	group := CreateGroup(t, user, "Family")
+
+	resp, _ := DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseGroupsAPIURL+"/"+group.ID+"/members", `{"contact_ids": []}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestRemoveGroupMembers(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	CreateContacts(user, 3)
+	group := CreateGroup(t, user, "Family")
+	contactIds := ContactIds(t, user)
+	AddToGroup(t, group, contactIds...)
+
+	body, err := json.Marshal(model.GroupMembersRequest{ContactIds: contactIds[:2]})
+	assert.Nil(t, err)
+	resp, bytes := DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseGroupsAPIURL+"/"+group.ID+"/members/_remove", string(body))
+
+	responseBody := new(model.WebResponse[model.GroupResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int64(1), responseBody.Data.ContactCount)
+}
+
+func TestSearchContactByGroup(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	CreateContacts(user, 5)
+	group := CreateGroup(t, user, "Family")
+	contactIds := ContactIds(t, user)
+	AddToGroup(t, group, contactIds[:2]...)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+BaseContactsAPIURL+"?group="+group.ID, nil)
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[[]model.ContactResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int64(2), responseBody.Paging.TotalItem)
+	for _, contact := range responseBody.Data {
+		assert.Contains(t, contactIds[:2], contact.ID)
+	}
+}
+
+func TestDeleteContactInGroup(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	CreateContacts(user, 1)
+	group := CreateGroup(t, user, "Family")
+	contact := GetFirstContact(t, user)
+	AddToGroup(t, group, contact.ID)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodDelete, server.URL+BaseContactsAPIURL+"/"+contact.ID, nil)
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// the membership is kept for a restore but a trashed contact is not counted
+	var members int64
+	assert.Nil(t, db.Model(&entity.ContactGroup{}).Count(&members).Error)
+	assert.Equal(t, int64(1), members)
+
+	resp, bytes := DoRequest(t, GetAccessToken(t, user), http.MethodGet, BaseGroupsAPIURL+"/"+group.ID, "")
+
+	responseBody := new(model.WebResponse[model.GroupResponse])
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int64(0), responseBody.Data.ContactCount)
+}
+
+func CreateGroup(t *testing.T, user *entity.User, name string) *entity.Group {
+	group := &entity.Group{
+		ID:     uuid.NewString(),
+		UserId: user.ID,
+		Name:   name,
+	}
+	err := db.Create(group).Error
+	assert.Nil(t, err)
+	return group
+}
+
+func AddToGroup(t *testing.T, group *entity.Group, contactIds ...string) {
+	for _, contactId := range contactIds {
+		err := db.Create(&entity.ContactGroup{ContactId: contactId, GroupId: group.ID}).Error
+		assert.Nil(t, err)
+	}
+}
+
+func ContactIds(t *testing.T, user *entity.User) []string {
+	var contactIds []string
+	err := db.Model(&entity.Contact{}).Where("user_id = ?", user.ID).Order("last_name ASC").Pluck("id", &contactIds).Error
+	assert.Nil(t, err)
+	return contactIds
+}
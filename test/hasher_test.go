@@ -0,0 +1,67 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArgon2idHasher(t *testing.T) {
+	argon2id := helper.NewArgon2idHasher(16*1024, 2, 1)
+
+	hash, err := argon2id.Hash("rahasia")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=16384,t=2,p=1$"))
+
+	ok, err := argon2id.Verify(hash, "rahasia")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	ok, err = argon2id.Verify(hash, "salah")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	assert.False(t, argon2id.NeedsRehash(hash))
+	assert.True(t, helper.NewArgon2idHasher(32*1024, 2, 1).NeedsRehash(hash))
+}
+
+func TestMigratingHasher(t *testing.T) {
+	bcrypt := helper.NewBcryptHasher(4)
+	migrating := helper.NewMigratingHasher(helper.NewArgon2idHasher(16*1024, 2, 1), bcrypt)
+
+	legacy, err := bcrypt.Hash("rahasia")
+	assert.Nil(t, err)
+
+	ok, err := migrating.Verify(legacy, "rahasia")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.True(t, migrating.NeedsRehash(legacy))
+
+	_, err = migrating.Verify("plain", "rahasia")
+	assert.Equal(t, helper.ErrUnknownHash, err)
+}
+
+func TestLoginRehashesPassword(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	assert.True(t, strings.HasPrefix(user.Password, "$2a$"))
+
+	userUseCase := NewUserUseCase(&usecase.TokenConfig{SessionMaxAge: time.Hour, SessionIdleTimeout: time.Hour}, &usecase.PasswordPolicyConfig{})
+	userUseCase.Hasher = helper.NewMigratingHasher(helper.NewArgon2idHasher(16*1024, 2, 1), helper.NewBcryptHasher(4))
+
+	_, err := userUseCase.Login(context.Background(), &model.LoginUserRequest{ID: user.ID, Password: "rahasia"})
+	assert.Nil(t, err)
+
+	user = GetUser(t, user.ID)
+	assert.True(t, strings.HasPrefix(user.Password, "$argon2id$"))
+
+	// the new hash keeps working
+	_, err = userUseCase.Login(context.Background(), &model.LoginUserRequest{ID: user.ID, Password: "rahasia"})
+	assert.Nil(t, err)
+}
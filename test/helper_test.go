@@ -1,13 +1,25 @@
 package test
 
 import (
+	"encoding/json"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/repository"
+	"github.com/iyasz/golang-clean-architecture/internal/usecase"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
 )
 
 const (
@@ -17,10 +29,121 @@ const (
 
 func ClearAll() {
 	ClearAddresses()
+	ClearContactGroups()
+	ClearGroups()
+	ClearContactRevisions()
 	ClearContact()
+	ClearSessions()
+	ClearRecoveryCodes()
+	ClearApiKeys()
+	ClearPasswordResets()
+	ClearEmailVerifications()
+	ClearPasswordHistories()
+	ClearUserIdentities()
+	ClearOidcStates()
+	ClearMagicLinks()
+	ClearAuditLogs()
+	ClearLoginAttempts()
 	ClearUsers()
 }
 
+func ClearPasswordResets() {
+	err := db.Where("id is not null").Delete(&entity.PasswordReset{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear password reset data : %+v", err)
+	}
+}
+
+func ClearPasswordHistories() {
+	err := db.Where("id is not null").Delete(&entity.PasswordHistory{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear password history data : %+v", err)
+	}
+}
+
+func ClearUserIdentities() {
+	err := db.Where("id is not null").Delete(&entity.UserIdentity{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear user identity data : %+v", err)
+	}
+}
+
+func ClearOidcStates() {
+	err := db.Where("id is not null").Delete(&entity.OidcState{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear oidc state data : %+v", err)
+	}
+}
+
+func ClearMagicLinks() {
+	err := db.Where("id is not null").Delete(&entity.MagicLink{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear magic link data : %+v", err)
+	}
+}
+
+func ClearContactGroups() {
+	err := db.Where("contact_id is not null").Delete(&entity.ContactGroup{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear contact group data : %+v", err)
+	}
+}
+
+func ClearGroups() {
+	err := db.Where("id is not null").Delete(&entity.Group{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear group data : %+v", err)
+	}
+}
+
+func ClearAuditLogs() {
+	err := db.Where("id is not null").Delete(&entity.AuditLog{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear audit log data : %+v", err)
+	}
+}
+
+func ClearEmailVerifications() {
+	err := db.Where("id is not null").Delete(&entity.EmailVerification{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear email verification data : %+v", err)
+	}
+}
+
+func ClearRecoveryCodes() {
+	err := db.Where("id is not null").Delete(&entity.RecoveryCode{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear recovery code data : %+v", err)
+	}
+}
+
+func ClearApiKeys() {
+	err := db.Where("id is not null").Delete(&entity.ApiKey{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear api key data : %+v", err)
+	}
+}
+
+func ClearLoginAttempts() {
+	err := db.Where("id is not null").Delete(&entity.LoginAttempt{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear login attempt data : %+v", err)
+	}
+}
+
+// ResetLoginDelay lets the next login attempt through the progressive delay, the lock stays
+func ResetLoginDelay(t *testing.T) {
+	err := db.Model(&entity.LoginAttempt{}).Where("id is not null").Update("retry_at", 0).Error
+	assert.Nil(t, err)
+}
+
+func ClearSessions() {
+	err := db.Where("id is not null").Delete(&entity.Session{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear session data : %+v", err)
+	}
+}
+
 func ClearUsers() {
 	err := db.Where("id is not null").Delete(&entity.User{}).Error
 	if err != nil {
@@ -28,15 +151,22 @@ func ClearUsers() {
 	}
 }
 
+func ClearContactRevisions() {
+	err := db.Where("id is not null").Delete(&entity.ContactRevision{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear contact revision data : %+v", err)
+	}
+}
+
 func ClearContact() {
-	err := db.Where("id is not null").Delete(&entity.Contact{}).Error
+	err := db.Unscoped().Where("id is not null").Delete(&entity.Contact{}).Error
 	if err != nil {
 		log.Fatalf("Failed clear contact data : %+v", err)
 	}
 }
 
 func ClearAddresses() {
-	err := db.Where("id is not null").Delete(&entity.Address{}).Error
+	err := db.Unscoped().Where("id is not null").Delete(&entity.Address{}).Error
 	if err != nil {
 		log.Fatalf("Failed clear address data : %+v", err)
 	}
@@ -75,6 +205,31 @@ func CreateAddresses(t *testing.T, contact *entity.Contact, total int) {
 	}
 }
 
+// CreateUser inserts a user with the password "rahasia" and the verified email id@example.com
+func CreateUser(t *testing.T, id string, role string) *entity.User {
+	password, err := bcrypt.GenerateFromPassword([]byte("rahasia"), bcrypt.MinCost)
+	assert.Nil(t, err)
+
+	user := &entity.User{
+		ID:              id,
+		Password:        string(password),
+		Name:            id,
+		Email:           id + "@example.com",
+		EmailVerifiedAt: time.Now().UnixMilli(),
+		Role:            role,
+	}
+	err = db.Create(user).Error
+	assert.Nil(t, err)
+	return user
+}
+
+func GetUser(t *testing.T, id string) *entity.User {
+	user := new(entity.User)
+	err := db.Where("id = ?", id).Take(user).Error
+	assert.Nil(t, err)
+	return user
+}
+
 func GetFirstUser(t *testing.T) *entity.User {
 	user := new(entity.User)
 	err := db.First(user).Error
@@ -96,6 +251,194 @@ func GetFirstAddress(t *testing.T, contact *entity.Contact) *entity.Address {
 	return address
 }
 
+func CreateSession(t *testing.T, user *entity.User) *entity.Session {
+	session := &entity.Session{
+		ID:         uuid.NewString(),
+		UserId:     user.ID,
+		Token:      helper.HashToken(uuid.NewString()),
+		UserAgent:  "Go-http-client/1.1",
+		IpAddress:  "127.0.0.1",
+		LastSeenAt: time.Now().UnixMilli(),
+	}
+	err := db.Create(session).Error
+	assert.Nil(t, err)
+	return session
+}
+
+func GetAccessToken(t *testing.T, user *entity.User) string {
+	session := CreateSession(t, user)
+	token, _, err := jwt.GenerateAccessToken(&helper.AccessToken{UserId: user.ID, SessionId: session.ID, Role: user.Role})
+	assert.Nil(t, err)
+	return token
+}
+
+// EnableTotp turns totp on for the user directly in the database and returns the secret
+// together with one recovery code
+func EnableTotp(t *testing.T, user *entity.User) (string, string) {
+	secret, err := helper.GenerateTotpSecret()
+	assert.Nil(t, err)
+
+	user.TotpSecret = secret
+	user.TotpEnabled = true
+	user.TotpLastStep = 0
+	err = db.Save(user).Error
+	assert.Nil(t, err)
+
+	code, err := helper.GenerateRecoveryCode()
+	assert.Nil(t, err)
+
+	err = db.Create(&entity.RecoveryCode{
+		ID:     uuid.NewString(),
+		UserId: user.ID,
+		Code:   helper.HashToken(code),
+	}).Error
+	assert.Nil(t, err)
+
+	return secret, code
+}
+
+func GetTotpCode(t *testing.T, secret string, at time.Time) string {
+	code, err := helper.GenerateTotpCode(secret, at)
+	assert.Nil(t, err)
+	return code
+}
+
+func CreateApiKey(t *testing.T, user *entity.User, scopes ...string) string {
+	key, err := helper.GenerateApiKey()
+	assert.Nil(t, err)
+
+	err = db.Create(&entity.ApiKey{
+		ID:     uuid.NewString(),
+		UserId: user.ID,
+		Name:   "test",
+		Prefix: key[:10],
+		Token:  helper.HashToken(key),
+		Scopes: strings.Join(scopes, " "),
+	}).Error
+	assert.Nil(t, err)
+
+	return key
+}
+
+func Login(t *testing.T, id string, password string) *model.TokenResponse {
+	return requestToken(t, "/_login", model.LoginUserRequest{ID: id, Password: password})
+}
+
+func Refresh(t *testing.T, refreshToken string) *model.TokenResponse {
+	return requestToken(t, "/_refresh", model.RefreshTokenRequest{RefreshToken: refreshToken})
+}
+
+func LoginStatus(t *testing.T, id string, password string) int {
+	bodyJson, err := json.Marshal(model.LoginUserRequest{ID: id, Password: password})
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+BaseUsersAPIURL+"/_login", strings.NewReader(string(bodyJson)))
+	assert.Nil(t, err)
+	SetupHeader(req)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	return resp.StatusCode
+}
+
+func requestToken(t *testing.T, path string, requestBody any) *model.TokenResponse {
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+BaseUsersAPIURL+path, strings.NewReader(string(bodyJson)))
+	assert.Nil(t, err)
+	SetupHeader(req)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.TokenResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	return &responseBody.Data
+}
+
+var tokenPattern = regexp.MustCompile(`token=(\S+)`)
+
+func GetResetToken(t *testing.T, email string) string {
+	return getMailToken(t, email)
+}
+
+func GetVerificationToken(t *testing.T, email string) string {
+	return getMailToken(t, email)
+}
+
+func GetMagicLinkToken(t *testing.T, email string) string {
+	return getMailToken(t, email)
+}
+
+func getMailToken(t *testing.T, email string) string {
+	message := mailer.Last(email)
+	assert.NotNil(t, message)
+
+	match := tokenPattern.FindStringSubmatch(message.Body)
+	assert.Len(t, match, 2)
+
+	token, err := url.QueryUnescape(match[1])
+	assert.Nil(t, err)
+	return token
+}
+
+func NewUserUseCase(tokenConfig *usecase.TokenConfig, policyConfig *usecase.PasswordPolicyConfig) *usecase.UserUseCase {
+	userRepository := repository.NewUserRepository(log)
+	emailVerificationRepository := repository.NewEmailVerificationRepository(log)
+	emailVerificationUseCase := usecase.NewEmailVerificationUseCase(db, log, validate, mailer, &usecase.EmailVerificationConfig{TokenTTL: time.Hour},
+		userRepository, emailVerificationRepository)
+	passwordPolicy := usecase.NewPasswordPolicy(log, hasher, policyConfig, repository.NewPasswordHistoryRepository(log))
+
+	return usecase.NewUserUseCase(db, log, validate, hasher, userRepository, repository.NewRecoveryCodeRepository(log),
+		repository.NewSessionRepository(log), repository.NewAuditLogRepository(log), emailVerificationUseCase, passwordPolicy, NewLoginThrottle(), NewTokenUseCase(tokenConfig))
+}
+
+func NewTokenUseCase(config *usecase.TokenConfig) *usecase.TokenUseCase {
+	return usecase.NewTokenUseCase(log, jwt, config, repository.NewSessionRepository(log))
+}
+
+func NewLoginThrottle() *usecase.LoginThrottle {
+	return usecase.NewLoginThrottle(log, &usecase.LoginThrottleConfig{}, repository.NewLoginAttemptRepository(log))
+}
+
+func DoRequest(t *testing.T, token string, method string, path string, body string) (*http.Response, []byte) {
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", token)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	return resp, bytes
+}
+
 func SetupHeader(req *http.Request) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
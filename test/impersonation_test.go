@@ -0,0 +1,329 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImpersonateReadContacts(t *testing.T) {
+	ClearAll()
+	admin := CreateUser(t, "admin", model.RoleAdmin)
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	CreateContacts(user, 3)
+
+	token := Impersonate(t, admin, user, false)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/contacts", nil)
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", token)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[[]model.ContactResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int64(3), responseBody.Paging.TotalItem)
+
+	var auditLogs []entity.AuditLog
+	err = db.Where("action = ?", model.AuditActionRequest).Find(&auditLogs).Error
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(auditLogs))
+	assert.Equal(t, admin.ID, auditLogs[0].ImpersonatorId)
+	assert.Equal(t, user.ID, auditLogs[0].UserId)
+	assert.Equal(t, http.MethodGet, auditLogs[0].Method)
+	assert.Equal(t, "/api/contacts", auditLogs[0].Path)
+	assert.Equal(t, http.StatusOK, auditLogs[0].Status)
+}
+
+func TestImpersonateReadOnly(t *testing.T) {
+	ClearAll()
+	admin := CreateUser(t, "admin", model.RoleAdmin)
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	token := Impersonate(t, admin, user, false)
+
+	resp, _ := DoRequest(t, token, http.MethodPost, "/api/contacts",
+		`{"first_name": "Eko", "last_name": "Khannedy", "email": "eko@example.com", "phone": "088888888"}`)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	var count int64
+	err := db.Model(&entity.Contact{}).Where("user_id = ?", user.ID).Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+
+	// refused writes are part of the trail too
+	auditLog := new(entity.AuditLog)
+	err = db.Where("action = ?", model.AuditActionRequest).First(auditLog).Error
+	assert.Nil(t, err)
+	assert.Equal(t, http.MethodPost, auditLog.Method)
+	assert.Equal(t, http.StatusForbidden, auditLog.Status)
+}
+
+func TestImpersonateAllowWrites(t *testing.T) {
+	ClearAll()
+	admin := CreateUser(t, "admin", model.RoleAdmin)
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	token := Impersonate(t, admin, user, true)
+
+	resp, _ := DoRequest(t, token, http.MethodPost, "/api/contacts",
+		`{"first_name": "Eko", "last_name": "Khannedy", "email": "eko@example.com", "phone": "088888888"}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var count int64
+	err := db.Model(&entity.Contact{}).Where("user_id = ?", user.ID).Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestImpersonateAccountChangesForbidden(t *testing.T) {
+	ClearAll()
+	admin := CreateUser(t, "admin", model.RoleAdmin)
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	token := Impersonate(t, admin, user, true)
+
+	resp, _ := DoRequest(t, token, http.MethodPatch, "/api/users/_current", `{"name": "Hacked"}`)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp, _ = DoRequest(t, token, http.MethodPost, "/api/users/_current/api-keys", `{"name": "backdoor"}`)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp, _ = DoRequest(t, token, http.MethodGet, "/api/users/_current", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestImpersonateAdminRoutesForbidden(t *testing.T) {
+	ClearAll()
+	admin := CreateUser(t, "admin", model.RoleAdmin)
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	token := Impersonate(t, admin, user, true)
+
+	resp, _ := DoRequest(t, token, http.MethodGet, BaseAdminAPIURL+"/users", "")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestImpersonateLogout(t *testing.T) {
+	ClearAll()
+	admin := CreateUser(t, "admin", model.RoleAdmin)
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	token := Impersonate(t, admin, user, false)
+
+	resp, _ := DoRequest(t, token, http.MethodDelete, "/api/users", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var count int64
+	err := db.Model(&entity.Session{}).Where("impersonator_id = ?", admin.ID).Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestImpersonateEndsWhenAdminDemoted(t *testing.T) {
+	ClearAll()
+	root := CreateUser(t, "root", model.RoleAdmin)
+	admin := CreateUser(t, "admin", model.RoleAdmin)
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	token := Impersonate(t, admin, user, false)
+
+	resp, _ := DoRequest(t, GetAccessToken(t, root), http.MethodPut, BaseAdminAPIURL+"/users/"+admin.ID+"/role", `{"role": "user"}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var count int64
+	err := db.Model(&entity.Session{}).Where("impersonator_id = ?", admin.ID).Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+
+	resp, _ = DoRequest(t, token, http.MethodGet, "/api/contacts", "")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestImpersonateEndsWhenAdminDisabled(t *testing.T) {
+	ClearAll()
+	admin := CreateUser(t, "admin", model.RoleAdmin)
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	token := Impersonate(t, admin, user, false)
+
+	// the session is still there, the admin is checked on every request
+	err := db.Model(admin).Update("disabled", true).Error
+	assert.Nil(t, err)
+
+	resp, _ := DoRequest(t, token, http.MethodGet, "/api/contacts", "")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestImpersonateMaxAge(t *testing.T) {
+	tokenUseCase := NewTokenUseCase(&usecase.TokenConfig{SessionMaxAge: 24 * time.Hour, SessionIdleTimeout: 24 * time.Hour, ImpersonationMaxAge: time.Hour})
+	createdAt := time.Now().Add(-2 * time.Hour).UnixMilli()
+	lastSeenAt := time.Now().UnixMilli()
+
+	assert.False(t, tokenUseCase.IsExpired(&entity.Session{CreatedAt: createdAt, LastSeenAt: lastSeenAt}))
+	assert.True(t, tokenUseCase.IsExpired(&entity.Session{CreatedAt: createdAt, LastSeenAt: lastSeenAt, ImpersonatorId: "admin"}))
+}
+
+func TestImpersonateRefreshAudited(t *testing.T) {
+	ClearAll()
+	admin := CreateUser(t, "admin", model.RoleAdmin)
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	resp, bytes := DoRequest(t, GetAccessToken(t, admin), http.MethodPost, BaseAdminAPIURL+"/users/"+user.ID+"/_impersonate", `{}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	responseBody := new(model.WebResponse[*model.TokenResponse])
+	err := json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	Refresh(t, responseBody.Data.RefreshToken)
+
+	auditLog := new(entity.AuditLog)
+	err = db.Where("action = ?", model.AuditActionRefresh).Take(auditLog).Error
+	assert.Nil(t, err)
+	assert.Equal(t, admin.ID, auditLog.ImpersonatorId)
+	assert.Equal(t, user.ID, auditLog.UserId)
+}
+
+func TestImpersonateLongPathAudited(t *testing.T) {
+	ClearAll()
+	admin := CreateUser(t, "admin", model.RoleAdmin)
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	token := Impersonate(t, admin, user, false)
+
+	resp, _ := DoRequest(t, token, http.MethodGet, "/api/contacts?ref="+strings.Repeat("a", 3000), "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	auditLog := new(entity.AuditLog)
+	err := db.Where("action = ?", model.AuditActionRequest).Take(auditLog).Error
+	assert.Nil(t, err)
+	assert.Equal(t, 2048, len(auditLog.Path))
+}
+
+func TestImpersonateAdminForbidden(t *testing.T) {
+	ClearAll()
+	admin := CreateUser(t, "admin", model.RoleAdmin)
+	other := CreateUser(t, "other", model.RoleAdmin)
+
+	resp, _ := DoRequest(t, GetAccessToken(t, admin), http.MethodPost, BaseAdminAPIURL+"/users/"+other.ID+"/_impersonate", `{}`)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestImpersonateSelf(t *testing.T) {
+	ClearAll()
+	admin := CreateUser(t, "admin", model.RoleAdmin)
+
+	resp, _ := DoRequest(t, GetAccessToken(t, admin), http.MethodPost, BaseAdminAPIURL+"/users/"+admin.ID+"/_impersonate", `{}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestImpersonateNotFound(t *testing.T) {
+	ClearAll()
+	admin := CreateUser(t, "admin", model.RoleAdmin)
+
+	resp, _ := DoRequest(t, GetAccessToken(t, admin), http.MethodPost, BaseAdminAPIURL+"/users/notfound/_impersonate", `{}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestImpersonateForbiddenForUser(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	other := CreateUser(t, "other", model.RoleUser)
+
+	resp, _ := DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseAdminAPIURL+"/users/"+other.ID+"/_impersonate", `{}`)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestSearchAuditLogs(t *testing.T) {
+	ClearAll()
+	admin := CreateUser(t, "admin", model.RoleAdmin)
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	other := CreateUser(t, "other", model.RoleUser)
+
+	token := Impersonate(t, admin, user, false)
+	DoRequest(t, token, http.MethodGet, "/api/contacts", "")
+	Impersonate(t, admin, other, false)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+BaseAdminAPIURL+"/audit-logs?user_id="+user.ID, nil)
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, admin))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[[]model.AuditLogResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int64(2), responseBody.Paging.TotalItem)
+	actions := make([]string, len(responseBody.Data))
+	for i, auditLog := range responseBody.Data {
+		actions[i] = auditLog.Action
+		assert.Equal(t, admin.ID, auditLog.ImpersonatorId)
+		assert.Equal(t, user.ID, auditLog.UserId)
+	}
+	assert.ElementsMatch(t, []string{model.AuditActionImpersonate, model.AuditActionRequest}, actions)
+}
+
+func Impersonate(t *testing.T, admin *entity.User, user *entity.User, allowWrites bool) string {
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	body := `{"allow_writes": false}`
+	if allowWrites {
+		body = `{"allow_writes": true}`
+	}
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+BaseAdminAPIURL+"/users/"+user.ID+"/_impersonate", strings.NewReader(body))
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, admin))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[*model.TokenResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	return responseBody.Data.AccessToken
+}
@@ -4,20 +4,29 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/iyasz/golang-clean-architecture/internal/config"
+	"github.com/iyasz/golang-clean-architecture/internal/gateway/mail"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
+var conf *config.Config
 var log *logrus.Logger
 var db *gorm.DB
 var validate *validator.Validate
+var jwt *helper.Jwt
+var hasher helper.PasswordHasher
+var mailer *mail.MemoryMailer
 var app *chi.Mux
 
 func init() {
-	conf := config.Load("test")
+	conf = config.Load("test")
 	log = config.NewLlogger(&conf.Logrus)
 	db = config.NewDatabase(&conf.Database, log)
 	validate = config.NewValidator()
+	jwt = config.NewJwt(&conf.Jwt)
+	hasher = config.NewPasswordHasher(&conf.PasswordHash, log)
+	mailer = mail.NewMemoryMailer()
 	app = config.NewChi(conf)
 
 	config.Bootstrap(&config.BootstrapConfig{
@@ -25,5 +34,9 @@ func init() {
 		App:      app,
 		Log:      log,
 		Validate: validate,
+		Jwt:      jwt,
+		Hasher:   hasher,
+		Mailer:   mailer,
+		Config:   conf,
 	})
 }
@@ -0,0 +1,214 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/repository"
+	"github.com/iyasz/golang-clean-architecture/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMagicLinkLogin(t *testing.T) {
+	ClearAll()
+	mailer.Clear()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	assert.Equal(t, http.StatusOK, sendMagicLink(t, user.Email))
+
+	status, response := loginMagicLink(t, GetMagicLinkToken(t, user.Email))
+	assert.Equal(t, http.StatusOK, status)
+	assert.NotEmpty(t, response.AccessToken)
+	assert.NotEmpty(t, response.RefreshToken)
+
+	var total int64
+	err := db.Model(&entity.Session{}).Where("user_id = ?", user.ID).Count(&total).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+}
+
+func TestMagicLinkUsedTwice(t *testing.T) {
+	ClearAll()
+	mailer.Clear()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	assert.Equal(t, http.StatusOK, sendMagicLink(t, user.Email))
+	token := GetMagicLinkToken(t, user.Email)
+
+	status, _ := loginMagicLink(t, token)
+	assert.Equal(t, http.StatusOK, status)
+
+	status, _ = loginMagicLink(t, token)
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestMagicLinkUsedConcurrently(t *testing.T) {
+	ClearAll()
+	mailer.Clear()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	assert.Equal(t, http.StatusOK, sendMagicLink(t, user.Email))
+	token := GetMagicLinkToken(t, user.Email)
+
+	statuses := make([]int, 5)
+	var wg sync.WaitGroup
+	for i := range statuses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			statuses[i], _ = loginMagicLink(t, token)
+		}(i)
+	}
+	wg.Wait()
+
+	// the link opens one session, the other requests find it already used
+	ok := 0
+	for _, status := range statuses {
+		if status == http.StatusOK {
+			ok++
+		}
+	}
+	assert.Equal(t, 1, ok)
+
+	var total int64
+	err := db.Model(&entity.Session{}).Where("user_id = ?", user.ID).Count(&total).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+}
+
+func TestMagicLinkUnknownEmail(t *testing.T) {
+	ClearAll()
+	mailer.Clear()
+
+	assert.Equal(t, http.StatusOK, sendMagicLink(t, "unknown@example.com"))
+	assert.Nil(t, mailer.Last("unknown@example.com"))
+}
+
+func TestMagicLinkUnverifiedEmail(t *testing.T) {
+	ClearAll()
+	mailer.Clear()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	err := db.Model(user).Update("email_verified_at", 0).Error
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, sendMagicLink(t, user.Email))
+	assert.Nil(t, mailer.Last(user.Email))
+}
+
+func TestMagicLinkAfterEmailChange(t *testing.T) {
+	ClearAll()
+	mailer.Clear()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	assert.Equal(t, http.StatusOK, sendMagicLink(t, user.Email))
+	token := GetMagicLinkToken(t, user.Email)
+
+	err := db.Model(user).Update("email", "other@example.com").Error
+	assert.Nil(t, err)
+
+	status, _ := loginMagicLink(t, token)
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestMagicLinkWrongToken(t *testing.T) {
+	ClearAll()
+
+	status, _ := loginMagicLink(t, "salah")
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestMagicLinkTotpChallenge(t *testing.T) {
+	ClearAll()
+	mailer.Clear()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	err := db.Model(user).Update("totp_enabled", true).Error
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, sendMagicLink(t, user.Email))
+
+	status, response := loginMagicLink(t, GetMagicLinkToken(t, user.Email))
+	assert.Equal(t, http.StatusOK, status)
+	assert.NotEmpty(t, response.ChallengeToken)
+	assert.Empty(t, response.AccessToken)
+}
+
+func TestMagicLinkRateLimit(t *testing.T) {
+	ClearAll()
+	mailer.Clear()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	config := &usecase.MagicLinkConfig{TokenTTL: time.Minute, MaxRequests: 2, Window: time.Hour}
+	magicLinkUseCase := usecase.NewMagicLinkUseCase(db, log, validate, mailer, config, NewTokenUseCase(&usecase.TokenConfig{}),
+		repository.NewUserRepository(log), repository.NewMagicLinkRepository(log))
+
+	for i := 0; i < 2; i++ {
+		ok, err := magicLinkUseCase.Send(context.Background(), &model.MagicLinkRequest{Email: user.Email})
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	}
+	mailer.Clear()
+
+	// over the limit the answer does not change, only no mail is sent
+	ok, err := magicLinkUseCase.Send(context.Background(), &model.MagicLinkRequest{Email: user.Email})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Nil(t, mailer.Last(user.Email))
+
+	var total int64
+	err = db.Model(&entity.MagicLink{}).Where("user_id = ?", user.ID).Count(&total).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+}
+
+func TestMagicLinkRateLimitConcurrently(t *testing.T) {
+	ClearAll()
+	mailer.Clear()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	config := &usecase.MagicLinkConfig{TokenTTL: time.Minute, MaxRequests: 2, Window: time.Hour}
+	magicLinkUseCase := usecase.NewMagicLinkUseCase(db, log, validate, mailer, config, NewTokenUseCase(&usecase.TokenConfig{}),
+		repository.NewUserRepository(log), repository.NewMagicLinkRepository(log))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := magicLinkUseCase.Send(context.Background(), &model.MagicLinkRequest{Email: user.Email})
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+
+	var total int64
+	err := db.Model(&entity.MagicLink{}).Where("user_id = ?", user.ID).Count(&total).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+}
+
+func sendMagicLink(t *testing.T, email string) int {
+	bodyJson, err := json.Marshal(model.MagicLinkRequest{Email: email})
+	assert.Nil(t, err)
+
+	resp, _ := DoRequest(t, "", http.MethodPost, BaseUsersAPIURL+"/_magic-link", string(bodyJson))
+	return resp.StatusCode
+}
+
+func loginMagicLink(t *testing.T, token string) (int, *model.TokenResponse) {
+	bodyJson, err := json.Marshal(model.LoginMagicLinkRequest{Token: token})
+	assert.Nil(t, err)
+
+	resp, bytes := DoRequest(t, "", http.MethodPost, BaseUsersAPIURL+"/_magic-link/_login", string(bodyJson))
+
+	responseBody := new(model.WebResponse[*model.TokenResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	return resp.StatusCode, responseBody.Data
+}
@@ -0,0 +1,474 @@
+package test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/gateway/oidc"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/repository"
+	"github.com/iyasz/golang-clean-architecture/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+const stubClientId = "contacts-app"
+
+type stubIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type stubGrant struct {
+	identity  stubIdentity
+	nonce     string
+	challenge string
+}
+
+// stubProvider is a minimal OpenID Connect provider: discovery, signing keys and a token
+// endpoint that checks the PKCE verifier. The authorization step is done by the test itself
+type stubProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	mutex  sync.Mutex
+	grants map[string]stubGrant
+}
+
+func newStubProvider(t *testing.T) *stubProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+
+	stub := &stubProvider{key: key, grants: map[string]stubGrant{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", stub.discovery)
+	mux.HandleFunc("/jwks", stub.jwks)
+	mux.HandleFunc("/token", stub.token)
+	stub.server = httptest.NewServer(mux)
+	t.Cleanup(stub.server.Close)
+
+	return stub
+}
+
+func (s *stubProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	issuer := s.server.URL
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + "/authorize",
+		"token_endpoint":                        issuer + "/token",
+		"jwks_uri":                              issuer + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (s *stubProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": "stub",
+			"n":   base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (s *stubProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mutex.Lock()
+	grant, ok := s.grants[r.PostForm.Get("code")]
+	delete(s.grants, r.PostForm.Get("code"))
+	s.mutex.Unlock()
+
+	digest := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(digest[:]) != grant.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+		return
+	}
+
+	idToken := gojwt.NewWithClaims(gojwt.SigningMethodRS256, gojwt.MapClaims{
+		"iss":            s.server.URL,
+		"aud":            stubClientId,
+		"sub":            grant.identity.Subject,
+		"email":          grant.identity.Email,
+		"email_verified": grant.identity.EmailVerified,
+		"name":           grant.identity.Name,
+		"nonce":          grant.nonce,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Minute).Unix(),
+	})
+	idToken.Header["kid"] = "stub"
+
+	signed, err := idToken.SignedString(s.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"access_token": uuid.NewString(),
+		"token_type":   "Bearer",
+		"expires_in":   60,
+		"id_token":     signed,
+	})
+}
+
+// authorize plays the user logging in at the provider, it returns the code and the state
+// the provider would redirect back with
+func (s *stubProvider) authorize(t *testing.T, authorizationUrl string, identity stubIdentity) (string, string) {
+	parsed, err := url.Parse(authorizationUrl)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(authorizationUrl, s.server.URL+"/authorize"))
+
+	query := parsed.Query()
+	assert.Equal(t, stubClientId, query.Get("client_id"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+
+	code := uuid.NewString()
+	s.mutex.Lock()
+	s.grants[code] = stubGrant{identity: identity, nonce: query.Get("nonce"), challenge: query.Get("code_challenge")}
+	s.mutex.Unlock()
+
+	return code, query.Get("state")
+}
+
+func newOidcUseCase(stub *stubProvider) *usecase.OidcUseCase {
+	providers := map[string]*oidc.Provider{
+		"stub": oidc.NewProvider("stub", stub.server.URL, stubClientId, "secret", "http://localhost/api/auth/oidc/stub/callback"),
+	}
+
+	return usecase.NewOidcUseCase(db, log, validate, providers, NewTokenUseCase(&usecase.TokenConfig{}),
+		repository.NewUserRepository(log), repository.NewUserIdentityRepository(log), repository.NewOidcStateRepository(log))
+}
+
+func oidcLogin(t *testing.T, stub *stubProvider, oidcUseCase *usecase.OidcUseCase, userId string, identity stubIdentity) (*model.TokenResponse, error) {
+	start, err := oidcUseCase.Start(context.Background(), &model.OidcStartRequest{Provider: "stub", UserId: userId})
+	assert.Nil(t, err)
+
+	code, state := stub.authorize(t, start.AuthorizationUrl, identity)
+
+	return oidcUseCase.Callback(context.Background(), &model.OidcCallbackRequest{Provider: "stub", Code: code, State: state, Binding: start.Binding, UserId: userId})
+}
+
+func CountUsers(t *testing.T) int64 {
+	var total int64
+	err := db.Model(&entity.User{}).Count(&total).Error
+	assert.Nil(t, err)
+	return total
+}
+
+func TestOidcLoginProvisionsUser(t *testing.T) {
+	ClearAll()
+	stub := newStubProvider(t)
+	oidcUseCase := newOidcUseCase(stub)
+
+	identity := stubIdentity{Subject: "248289761001", Email: "Jane@Example.com", EmailVerified: true, Name: "Jane Doe"}
+	response, err := oidcLogin(t, stub, oidcUseCase, "", identity)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, response.AccessToken)
+	assert.NotEmpty(t, response.RefreshToken)
+
+	userIdentity := new(entity.UserIdentity)
+	err = db.Where("provider = ? AND subject = ?", "stub", identity.Subject).Take(userIdentity).Error
+	assert.Nil(t, err)
+
+	user := GetUser(t, userIdentity.UserId)
+	assert.Equal(t, "Jane Doe", user.Name)
+	assert.Equal(t, "jane@example.com", user.Email)
+	assert.NotEqual(t, int64(0), user.EmailVerifiedAt)
+	assert.Equal(t, model.RoleUser, user.Role)
+	assert.Equal(t, "", user.Password)
+
+	_, err = oidcLogin(t, stub, oidcUseCase, "", identity)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), CountUsers(t))
+}
+
+func TestOidcLoginLinksVerifiedEmail(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	stub := newStubProvider(t)
+	oidcUseCase := newOidcUseCase(stub)
+
+	_, err := oidcLogin(t, stub, oidcUseCase, "", stubIdentity{Subject: "1", Email: user.Email, EmailVerified: true})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), CountUsers(t))
+
+	userIdentity := new(entity.UserIdentity)
+	err = db.Where("subject = ?", "1").Take(userIdentity).Error
+	assert.Nil(t, err)
+	assert.Equal(t, user.ID, userIdentity.UserId)
+}
+
+func TestOidcLoginUnverifiedEmailNotLinked(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	stub := newStubProvider(t)
+	oidcUseCase := newOidcUseCase(stub)
+
+	_, err := oidcLogin(t, stub, oidcUseCase, "", stubIdentity{Subject: "1", Email: user.Email, EmailVerified: false})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), CountUsers(t))
+
+	userIdentity := new(entity.UserIdentity)
+	err = db.Where("subject = ?", "1").Take(userIdentity).Error
+	assert.Nil(t, err)
+	assert.NotEqual(t, user.ID, userIdentity.UserId)
+
+	// the address stays with the account that owns it
+	provisioned := GetUser(t, userIdentity.UserId)
+	assert.Equal(t, "", provisioned.Email)
+	assert.Equal(t, user.Email, provisioned.Name)
+}
+
+func TestOidcLoginTotpChallenge(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	err := db.Model(user).Update("totp_enabled", true).Error
+	assert.Nil(t, err)
+	stub := newStubProvider(t)
+	oidcUseCase := newOidcUseCase(stub)
+
+	response, err := oidcLogin(t, stub, oidcUseCase, "", stubIdentity{Subject: "1", Email: user.Email, EmailVerified: true})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, response.ChallengeToken)
+	assert.Empty(t, response.AccessToken)
+}
+
+func TestOidcLinkIdentity(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	stub := newStubProvider(t)
+	oidcUseCase := newOidcUseCase(stub)
+
+	// the provider address does not have to match the account
+	_, err := oidcLogin(t, stub, oidcUseCase, user.ID, stubIdentity{Subject: "1", Email: "other@example.com", EmailVerified: true})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), CountUsers(t))
+
+	identities, err := oidcUseCase.List(context.Background(), &model.ListUserIdentityRequest{UserId: user.ID})
+	assert.Nil(t, err)
+	assert.Len(t, identities, 1)
+	assert.Equal(t, "stub", identities[0].Provider)
+	assert.Equal(t, "1", identities[0].Subject)
+	assert.Equal(t, "other@example.com", identities[0].Email)
+
+	_, err = oidcLogin(t, stub, oidcUseCase, "", stubIdentity{Subject: "1"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), CountUsers(t))
+}
+
+func TestOidcLinkIdentityOfAnotherUser(t *testing.T) {
+	ClearAll()
+	CreateUser(t, "khannedy", model.RoleUser)
+	other := CreateUser(t, "budi", model.RoleUser)
+	stub := newStubProvider(t)
+	oidcUseCase := newOidcUseCase(stub)
+
+	_, err := oidcLogin(t, stub, oidcUseCase, "khannedy", stubIdentity{Subject: "1"})
+	assert.Nil(t, err)
+
+	_, err = oidcLogin(t, stub, oidcUseCase, other.ID, stubIdentity{Subject: "1"})
+	assert.Equal(t, helper.ErrConflict, err)
+}
+
+func TestOidcCallbackInvalidState(t *testing.T) {
+	ClearAll()
+	stub := newStubProvider(t)
+	oidcUseCase := newOidcUseCase(stub)
+
+	start, err := oidcUseCase.Start(context.Background(), &model.OidcStartRequest{Provider: "stub"})
+	assert.Nil(t, err)
+	code, state := stub.authorize(t, start.AuthorizationUrl, stubIdentity{Subject: "1"})
+
+	unknown := uuid.NewString()
+	_, err = oidcUseCase.Callback(context.Background(), &model.OidcCallbackRequest{Provider: "stub", Code: code, State: unknown, Binding: helper.HashToken(unknown)})
+	assert.Equal(t, helper.ErrBadRequest, err)
+
+	_, err = oidcUseCase.Callback(context.Background(), &model.OidcCallbackRequest{Provider: "stub", Code: code, State: state, Binding: start.Binding})
+	assert.Nil(t, err)
+
+	// a state is only redeemed once
+	_, err = oidcUseCase.Callback(context.Background(), &model.OidcCallbackRequest{Provider: "stub", Code: code, State: state, Binding: start.Binding})
+	assert.Equal(t, helper.ErrBadRequest, err)
+}
+
+func TestOidcCallbackWrongVerifier(t *testing.T) {
+	ClearAll()
+	stub := newStubProvider(t)
+	oidcUseCase := newOidcUseCase(stub)
+
+	start, err := oidcUseCase.Start(context.Background(), &model.OidcStartRequest{Provider: "stub"})
+	assert.Nil(t, err)
+	code, state := stub.authorize(t, start.AuthorizationUrl, stubIdentity{Subject: "1"})
+
+	// a code intercepted from another flow cannot be redeemed without its verifier
+	stub.grants[code] = stubGrant{identity: stubIdentity{Subject: "1"}, challenge: "intercepted"}
+
+	_, err = oidcUseCase.Callback(context.Background(), &model.OidcCallbackRequest{Provider: "stub", Code: code, State: state, Binding: start.Binding})
+	assert.Equal(t, helper.ErrUnauthorized, err)
+	assert.Equal(t, int64(0), CountUsers(t))
+}
+
+func TestOidcCallbackFromAnotherUserAgent(t *testing.T) {
+	ClearAll()
+	stub := newStubProvider(t)
+	oidcUseCase := newOidcUseCase(stub)
+
+	start, err := oidcUseCase.Start(context.Background(), &model.OidcStartRequest{Provider: "stub"})
+	assert.Nil(t, err)
+	code, state := stub.authorize(t, start.AuthorizationUrl, stubIdentity{Subject: "1"})
+
+	// a victim sent to the callback url has no cookie, or one of its own flow
+	_, err = oidcUseCase.Callback(context.Background(), &model.OidcCallbackRequest{Provider: "stub", Code: code, State: state})
+	assert.Equal(t, helper.ErrBadRequest, err)
+
+	other, err := oidcUseCase.Start(context.Background(), &model.OidcStartRequest{Provider: "stub"})
+	assert.Nil(t, err)
+	_, err = oidcUseCase.Callback(context.Background(), &model.OidcCallbackRequest{Provider: "stub", Code: code, State: state, Binding: other.Binding})
+	assert.Equal(t, helper.ErrBadRequest, err)
+	assert.Equal(t, int64(0), CountUsers(t))
+}
+
+func TestOidcLinkWithoutAccessToken(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	other := CreateUser(t, "budi", model.RoleUser)
+	stub := newStubProvider(t)
+	oidcUseCase := newOidcUseCase(stub)
+
+	for _, userId := range []string{"", other.ID} {
+		start, err := oidcUseCase.Start(context.Background(), &model.OidcStartRequest{Provider: "stub", UserId: user.ID})
+		assert.Nil(t, err)
+		code, state := stub.authorize(t, start.AuthorizationUrl, stubIdentity{Subject: "1"})
+
+		_, err = oidcUseCase.Callback(context.Background(), &model.OidcCallbackRequest{Provider: "stub", Code: code, State: state, Binding: start.Binding, UserId: userId})
+		assert.Equal(t, helper.ErrUnauthorized, err)
+	}
+
+	identities, err := oidcUseCase.List(context.Background(), &model.ListUserIdentityRequest{UserId: user.ID})
+	assert.Nil(t, err)
+	assert.Len(t, identities, 0)
+}
+
+func TestOidcDeleteLastIdentity(t *testing.T) {
+	ClearAll()
+	stub := newStubProvider(t)
+	oidcUseCase := newOidcUseCase(stub)
+
+	_, err := oidcLogin(t, stub, oidcUseCase, "", stubIdentity{Subject: "1"})
+	assert.Nil(t, err)
+
+	userIdentity := new(entity.UserIdentity)
+	err = db.Where("subject = ?", "1").Take(userIdentity).Error
+	assert.Nil(t, err)
+
+	// without a password the last identity is the only way in
+	err = oidcUseCase.Delete(context.Background(), &model.DeleteUserIdentityRequest{UserId: userIdentity.UserId, ID: userIdentity.ID})
+	assert.Equal(t, helper.ErrConflict, err)
+}
+
+func TestOidcStartUnknownProvider(t *testing.T) {
+	ClearAll()
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/auth/oidc/unknown/start", nil)
+	assert.Nil(t, err)
+	SetupHeader(req)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestListIdentities(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	identity := &entity.UserIdentity{ID: uuid.NewString(), UserId: user.ID, Provider: "stub", Subject: "1"}
+	err := db.Create(identity).Error
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+BaseUsersAPIURL+"/_current/identities", nil)
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[[]model.UserIdentityResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, len(responseBody.Data))
+	assert.Equal(t, identity.ID, responseBody.Data[0].ID)
+}
+
+func TestDeleteIdentity(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	identity := &entity.UserIdentity{ID: uuid.NewString(), UserId: user.ID, Provider: "stub", Subject: "1"}
+	err := db.Create(identity).Error
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	// the account still has a password, its last identity can go
+	req, err := http.NewRequest(http.MethodDelete, server.URL+BaseUsersAPIURL+"/_current/identities/"+identity.ID, nil)
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var total int64
+	err = db.Model(&entity.UserIdentity{}).Where("user_id = ?", user.ID).Count(&total).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), total)
+}
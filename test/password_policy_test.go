@@ -0,0 +1,126 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+var strictPasswordPolicy = &usecase.PasswordPolicyConfig{MinLength: 8, MinCharacterClasses: 3, History: 2}
+
+func TestPasswordPolicyRules(t *testing.T) {
+	ClearAll()
+
+	_, err := NewUserUseCase(&usecase.TokenConfig{}, strictPasswordPolicy).Create(context.Background(), &model.RegisterUserRequest{
+		ID:       "khannedy",
+		Password: "rahasia",
+		Name:     "Eko Khannedy",
+		Email:    "khannedy@example.com",
+	})
+
+	fieldError, ok := err.(*helper.Error)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusBadRequest, fieldError.Code)
+	assert.Len(t, fieldError.Fields["password"], 2)
+
+	var total int64
+	err = db.Model(&entity.User{}).Count(&total).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), total)
+}
+
+func TestPasswordPolicyCommonPassword(t *testing.T) {
+	ClearAll()
+
+	_, err := NewUserUseCase(&usecase.TokenConfig{}, strictPasswordPolicy).Create(context.Background(), &model.RegisterUserRequest{
+		ID:       "khannedy",
+		Password: "P@ssw0rd1",
+		Name:     "Eko Khannedy",
+		Email:    "khannedy@example.com",
+	})
+
+	fieldError, ok := err.(*helper.Error)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"is too common"}, fieldError.Fields["password"])
+}
+
+func TestPasswordPolicyHistory(t *testing.T) {
+	ClearAll()
+	userUseCase := NewUserUseCase(&usecase.TokenConfig{}, strictPasswordPolicy)
+
+	_, err := userUseCase.Create(context.Background(), &model.RegisterUserRequest{
+		ID:       "khannedy",
+		Password: "Rahasia#2024",
+		Name:     "Eko Khannedy",
+		Email:    "khannedy@example.com",
+	})
+	assert.Nil(t, err)
+
+	_, err = userUseCase.Update(context.Background(), &model.UpdateUserRequest{ID: "khannedy", Password: "Rahasia#2025"})
+	assert.Nil(t, err)
+
+	// both the current and the previous password are refused
+	for _, password := range []string{"Rahasia#2025", "Rahasia#2024"} {
+		_, err = userUseCase.Update(context.Background(), &model.UpdateUserRequest{ID: "khannedy", Password: password})
+		fieldError, ok := err.(*helper.Error)
+		assert.True(t, ok)
+		assert.Len(t, fieldError.Fields["password"], 1)
+	}
+
+	_, err = userUseCase.Update(context.Background(), &model.UpdateUserRequest{ID: "khannedy", Password: "Rahasia#2026"})
+	assert.Nil(t, err)
+
+	// only the last passwords are kept
+	var total int64
+	err = db.Model(&entity.PasswordHistory{}).Where("user_id = ?", "khannedy").Count(&total).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+}
+
+func TestRegisterCommonPassword(t *testing.T) {
+	ClearAll()
+	requestBody := model.RegisterUserRequest{
+		ID:       "khannedy",
+		Password: "password",
+		Name:     "Eko Khannedy",
+		Email:    "khannedy@example.com",
+	}
+
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+BaseUsersAPIURL, strings.NewReader(string(bodyJson)))
+	assert.Nil(t, err)
+	SetupHeader(req)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(struct {
+		Error  string              `json:"error"`
+		Fields map[string][]string `json:"fields"`
+	})
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, responseBody.Fields["password"], "is too common")
+}
@@ -0,0 +1,250 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForgotPassword(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+	mailer.Clear()
+
+	err := db.Model(&entity.User{}).Where("id = ?", "khannedy").Update("email_verified_at", time.Now().UnixMilli()).Error
+	assert.Nil(t, err)
+
+	requestBody := model.ForgotPasswordRequest{
+		Email: "khannedy@example.com",
+	}
+
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+BaseUsersAPIURL+"/_forgot-password", strings.NewReader(string(bodyJson)))
+	assert.Nil(t, err)
+	SetupHeader(req)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[bool])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, responseBody.Data)
+
+	message := mailer.Last(requestBody.Email)
+	assert.NotNil(t, message)
+	assert.Regexp(t, tokenPattern, message.Body)
+
+	var total int64
+	err = db.Model(&entity.PasswordReset{}).Where("user_id = ?", "khannedy").Count(&total).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+}
+
+func TestForgotPasswordUnknownEmail(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+	mailer.Clear()
+
+	requestBody := model.ForgotPasswordRequest{
+		Email: "unknown@example.com",
+	}
+
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+BaseUsersAPIURL+"/_forgot-password", strings.NewReader(string(bodyJson)))
+	assert.Nil(t, err)
+	SetupHeader(req)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[bool])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, responseBody.Data)
+	assert.Nil(t, mailer.Last(requestBody.Email))
+}
+
+func TestForgotPasswordUnverifiedEmail(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+	mailer.Clear()
+
+	requestBody := model.ForgotPasswordRequest{
+		Email: "khannedy@example.com",
+	}
+
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+BaseUsersAPIURL+"/_forgot-password", strings.NewReader(string(bodyJson)))
+	assert.Nil(t, err)
+	SetupHeader(req)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Nil(t, mailer.Last(requestBody.Email))
+
+	var total int64
+	err = db.Model(&entity.PasswordReset{}).Where("user_id = ?", "khannedy").Count(&total).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), total)
+}
+
+func TestResetPassword(t *testing.T) {
+	TestForgotPassword(t)
+	Login(t, "khannedy", "rahasia")
+
+	token := GetResetToken(t, "khannedy@example.com")
+	requestBody := model.ResetPasswordRequest{
+		Token:    token,
+		Password: "rahasiabaru",
+	}
+
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+BaseUsersAPIURL+"/_reset-password", strings.NewReader(string(bodyJson)))
+	assert.Nil(t, err)
+	SetupHeader(req)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[bool])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, responseBody.Data)
+
+	// every session is logged out and the token cannot be used twice
+	var total int64
+	err = db.Model(&entity.Session{}).Where("user_id = ?", "khannedy").Count(&total).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), total)
+
+	err = db.Model(&entity.PasswordReset{}).Where("user_id = ?", "khannedy").Count(&total).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), total)
+
+	Login(t, "khannedy", "rahasiabaru")
+}
+
+func TestResetPasswordExpired(t *testing.T) {
+	TestForgotPassword(t)
+
+	err := db.Model(&entity.PasswordReset{}).Where("user_id = ?", "khannedy").Update("expires_at", 0).Error
+	assert.Nil(t, err)
+
+	requestBody := model.ResetPasswordRequest{
+		Token:    GetResetToken(t, "khannedy@example.com"),
+		Password: "rahasiabaru",
+	}
+
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+BaseUsersAPIURL+"/_reset-password", strings.NewReader(string(bodyJson)))
+	assert.Nil(t, err)
+	SetupHeader(req)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[bool])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusGone, resp.StatusCode)
+	assert.NotNil(t, responseBody.Errors)
+}
+
+func TestResetPasswordWrongToken(t *testing.T) {
+	TestForgotPassword(t)
+
+	requestBody := model.ResetPasswordRequest{
+		Token:    "wrong",
+		Password: "rahasiabaru",
+	}
+
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+BaseUsersAPIURL+"/_reset-password", strings.NewReader(string(bodyJson)))
+	assert.Nil(t, err)
+	SetupHeader(req)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[bool])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.NotNil(t, responseBody.Errors)
+}
@@ -0,0 +1,205 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListSessions(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	Login(t, "khannedy", "rahasia")
+	Login(t, "khannedy", "rahasia")
+
+	user := GetFirstUser(t)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+BaseUsersAPIURL+"/_current/sessions", nil)
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[[]model.SessionResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 3, len(responseBody.Data))
+
+	current := 0
+	for _, session := range responseBody.Data {
+		assert.NotEmpty(t, session.UserAgent)
+		assert.NotEmpty(t, session.IpAddress)
+		assert.NotZero(t, session.LastSeenAt)
+		if session.Current {
+			current++
+		}
+	}
+	assert.Equal(t, 1, current)
+}
+
+func TestLoginLongUserAgent(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	bodyJson, err := json.Marshal(model.LoginUserRequest{ID: "khannedy", Password: "rahasia"})
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+BaseUsersAPIURL+"/_login", strings.NewReader(string(bodyJson)))
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("User-Agent", strings.Repeat("a", 1000))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	session := new(entity.Session)
+	err = db.Where("user_agent <> ?", "Go-http-client/1.1").Take(session).Error
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Repeat("a", 255), session.UserAgent)
+}
+
+func TestRevokeSession(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	login := Login(t, "khannedy", "rahasia")
+	claims, err := jwt.ParseAccessToken(login.AccessToken)
+	assert.Nil(t, err)
+
+	user := GetFirstUser(t)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodDelete, server.URL+BaseUsersAPIURL+"/_current/sessions/"+claims.SessionId, nil)
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[bool])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, responseBody.Data)
+
+	var total int64
+	err = db.Model(&entity.Session{}).Where("id = ?", claims.SessionId).Count(&total).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), total)
+
+	// the access token of the revoked session is refused before it expires
+	req, err = http.NewRequest(http.MethodGet, server.URL+BaseUsersAPIURL+"/_current", nil)
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", login.AccessToken)
+
+	resp, err = client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestRevokeSessionNotFound(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	user := GetFirstUser(t)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodDelete, server.URL+BaseUsersAPIURL+"/_current/sessions/"+"d3b07384-d113-4ec6-a0d4-3f2a7b0c9e1f", nil)
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[bool])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.NotNil(t, responseBody.Errors)
+}
+
+func TestRevokeAllSessions(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	Login(t, "khannedy", "rahasia")
+	Login(t, "khannedy", "rahasia")
+
+	user := GetFirstUser(t)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodDelete, server.URL+BaseUsersAPIURL+"/_current/sessions", nil)
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[bool])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, responseBody.Data)
+
+	var total int64
+	err = db.Model(&entity.Session{}).Where("user_id = ?", user.ID).Count(&total).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), total)
+}
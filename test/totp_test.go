@@ -0,0 +1,342 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnrollTotp(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	user := GetFirstUser(t)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+BaseUsersAPIURL+"/_current/totp", nil)
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.TotpResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEmpty(t, responseBody.Data.Secret)
+	assert.True(t, strings.HasPrefix(responseBody.Data.Uri, "otpauth://totp/"))
+	assert.Contains(t, responseBody.Data.Uri, "secret="+responseBody.Data.Secret)
+
+	user = GetFirstUser(t)
+	assert.Equal(t, responseBody.Data.Secret, user.TotpSecret)
+	assert.False(t, user.TotpEnabled)
+}
+
+func TestConfirmTotp(t *testing.T) {
+	TestEnrollTotp(t)
+
+	user := GetFirstUser(t)
+
+	requestBody := model.ConfirmTotpRequest{
+		Code: GetTotpCode(t, user.TotpSecret, time.Now()),
+	}
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+BaseUsersAPIURL+"/_current/totp/_confirm", strings.NewReader(string(bodyJson)))
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.RecoveryCodesResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, responseBody.Data.RecoveryCodes, 10)
+
+	user = GetFirstUser(t)
+	assert.True(t, user.TotpEnabled)
+
+	var count int64
+	err = db.Model(&entity.RecoveryCode{}).Where("user_id = ?", user.ID).Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), count)
+
+	// only digests are stored
+	err = db.Model(&entity.RecoveryCode{}).Where("code = ?", responseBody.Data.RecoveryCodes[0]).Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestConfirmTotpWrongCode(t *testing.T) {
+	TestEnrollTotp(t)
+
+	user := GetFirstUser(t)
+
+	requestBody := model.ConfirmTotpRequest{
+		Code: GetTotpCode(t, user.TotpSecret, time.Now().Add(-10*time.Minute)),
+	}
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+BaseUsersAPIURL+"/_current/totp/_confirm", strings.NewReader(string(bodyJson)))
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	user = GetFirstUser(t)
+	assert.False(t, user.TotpEnabled)
+}
+
+func TestLoginTotp(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	secret, _ := EnableTotp(t, GetFirstUser(t))
+
+	challenge := Login(t, "khannedy", "rahasia")
+	assert.NotEmpty(t, challenge.ChallengeToken)
+	assert.Empty(t, challenge.AccessToken)
+	assert.Empty(t, challenge.RefreshToken)
+
+	code := GetTotpCode(t, secret, time.Now())
+	status, token := LoginTotp(t, model.LoginTotpRequest{ChallengeToken: challenge.ChallengeToken, Code: code})
+	assert.Equal(t, http.StatusOK, status)
+	assert.NotEmpty(t, token.AccessToken)
+	assert.NotEmpty(t, token.RefreshToken)
+
+	// a code is only good once
+	challenge = Login(t, "khannedy", "rahasia")
+	status, _ = LoginTotp(t, model.LoginTotpRequest{ChallengeToken: challenge.ChallengeToken, Code: code})
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestLoginTotpConcurrentReplay(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	secret, recoveryCode := EnableTotp(t, GetFirstUser(t))
+	code := GetTotpCode(t, secret, time.Now())
+
+	for _, request := range []model.LoginTotpRequest{{Code: code}, {RecoveryCode: recoveryCode}} {
+		ClearLoginAttempts()
+
+		challenges := make([]string, 5)
+		for i := range challenges {
+			challenges[i] = Login(t, "khannedy", "rahasia").ChallengeToken
+		}
+
+		// the same code sent by parallel requests still opens a single session
+		statuses := make([]int, len(challenges))
+		var wg sync.WaitGroup
+		for i, challenge := range challenges {
+			wg.Add(1)
+			go func(i int, request model.LoginTotpRequest) {
+				defer wg.Done()
+				request.ChallengeToken = challenge
+				statuses[i], _ = LoginTotp(t, request)
+			}(i, request)
+		}
+		wg.Wait()
+
+		ok := 0
+		for _, status := range statuses {
+			if status == http.StatusOK {
+				ok++
+			}
+		}
+		assert.Equal(t, 1, ok)
+	}
+}
+
+func TestLoginTotpWrongCode(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	secret, _ := EnableTotp(t, GetFirstUser(t))
+
+	challenge := Login(t, "khannedy", "rahasia")
+
+	code := GetTotpCode(t, secret, time.Now().Add(-10*time.Minute))
+	status, _ := LoginTotp(t, model.LoginTotpRequest{ChallengeToken: challenge.ChallengeToken, Code: code})
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	attempt := new(entity.LoginAttempt)
+	err := db.Where("id = ?", "user:khannedy").Take(attempt).Error
+	assert.Nil(t, err)
+	assert.Equal(t, 1, attempt.Failures)
+}
+
+func TestLoginTotpWithoutChallenge(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	user := GetFirstUser(t)
+	secret, _ := EnableTotp(t, user)
+
+	// an access token is not a challenge token
+	status, _ := LoginTotp(t, model.LoginTotpRequest{
+		ChallengeToken: GetAccessToken(t, user),
+		Code:           GetTotpCode(t, secret, time.Now()),
+	})
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestChallengeTokenIsNotARefreshToken(t *testing.T) {
+	challengeToken, err := jwt.GenerateChallengeToken("khannedy", time.Minute)
+	assert.Nil(t, err)
+
+	_, err = jwt.ParseRefreshToken(challengeToken)
+	assert.NotNil(t, err)
+
+	magicLinkToken, err := jwt.GenerateMagicLinkToken("khannedy", "token", time.Minute)
+	assert.Nil(t, err)
+
+	_, err = jwt.ParseRefreshToken(magicLinkToken)
+	assert.NotNil(t, err)
+}
+
+func TestLoginRecoveryCode(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	_, recoveryCode := EnableTotp(t, GetFirstUser(t))
+
+	challenge := Login(t, "khannedy", "rahasia")
+	status, token := LoginTotp(t, model.LoginTotpRequest{ChallengeToken: challenge.ChallengeToken, RecoveryCode: strings.ToUpper(recoveryCode)})
+	assert.Equal(t, http.StatusOK, status)
+	assert.NotEmpty(t, token.AccessToken)
+
+	challenge = Login(t, "khannedy", "rahasia")
+	status, _ = LoginTotp(t, model.LoginTotpRequest{ChallengeToken: challenge.ChallengeToken, RecoveryCode: recoveryCode})
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestDisableTotp(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	user := GetFirstUser(t)
+	EnableTotp(t, user)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	for _, password := range []string{"salah", "rahasia"} {
+		bodyJson, err := json.Marshal(model.DisableTotpRequest{Password: password})
+		assert.Nil(t, err)
+
+		req, err := http.NewRequest(http.MethodDelete, server.URL+BaseUsersAPIURL+"/_current/totp", strings.NewReader(string(bodyJson)))
+		assert.Nil(t, err)
+		SetupHeader(req)
+		req.Header.Set("Authorization", GetAccessToken(t, user))
+
+		client := &http.Client{}
+		resp, err := client.Do(req)
+		assert.Nil(t, err)
+		resp.Body.Close()
+
+		if password == "salah" {
+			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+			ResetLoginDelay(t)
+		} else {
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		}
+	}
+
+	user = GetFirstUser(t)
+	assert.False(t, user.TotpEnabled)
+	assert.Empty(t, user.TotpSecret)
+
+	var count int64
+	err := db.Model(&entity.RecoveryCode{}).Where("user_id = ?", user.ID).Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+
+	token := Login(t, "khannedy", "rahasia")
+	assert.NotEmpty(t, token.AccessToken)
+	assert.Empty(t, token.ChallengeToken)
+}
+
+func TestDisableTotpLocked(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	EnableTotp(t, user)
+
+	for i := 0; i < conf.Login.MaxAttempts; i++ {
+		resp, _ := DoRequest(t, GetAccessToken(t, user), http.MethodDelete, BaseUsersAPIURL+"/_current/totp", `{"password": "salah"}`)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		ResetLoginDelay(t)
+	}
+
+	resp, _ := DoRequest(t, GetAccessToken(t, user), http.MethodDelete, BaseUsersAPIURL+"/_current/totp", `{"password": "rahasia"}`)
+	assert.Equal(t, http.StatusLocked, resp.StatusCode)
+	assert.True(t, GetUser(t, user.ID).TotpEnabled)
+}
+
+func LoginTotp(t *testing.T, requestBody model.LoginTotpRequest) (int, *model.TokenResponse) {
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+BaseUsersAPIURL+"/_login/totp", strings.NewReader(string(bodyJson)))
+	assert.Nil(t, err)
+	SetupHeader(req)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.TokenResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	return resp.StatusCode, &responseBody.Data
+}
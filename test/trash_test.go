@@ -0,0 +1,161 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/iyasz/golang-clean-architecture/internal/repository"
+	"github.com/iyasz/golang-clean-architecture/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrashContact(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	CreateContacts(user, 2)
+	contact := GetFirstContact(t, user)
+	CreateAddresses(t, contact, 1)
+
+	resp, _ := DoRequest(t, GetAccessToken(t, user), http.MethodDelete, BaseContactsAPIURL+"/"+contact.ID, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, _ = DoRequest(t, GetAccessToken(t, user), http.MethodGet, BaseContactsAPIURL+"/"+contact.ID, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, int64(1), SearchContacts(t, user, "").Paging.TotalItem)
+
+	resp, bytes := DoRequest(t, GetAccessToken(t, user), http.MethodGet, BaseContactsAPIURL+"/_trash", "")
+
+	trash := new(model.WebResponse[[]model.ContactResponse])
+	assert.Nil(t, json.Unmarshal(bytes, trash))
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int64(1), trash.Paging.TotalItem)
+	assert.Equal(t, contact.ID, trash.Data[0].ID)
+	assert.NotZero(t, trash.Data[0].DeletedAt)
+
+	resp, bytes = DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseContactsAPIURL+"/"+contact.ID+"/_restore", "")
+
+	restored := new(model.WebResponse[model.ContactResponse])
+	assert.Nil(t, json.Unmarshal(bytes, restored))
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, contact.ID, restored.Data.ID)
+	assert.Zero(t, restored.Data.DeletedAt)
+	assert.Equal(t, int64(2), SearchContacts(t, user, "").Paging.TotalItem)
+
+	// the addresses come back with the contact
+	resp, bytes = DoRequest(t, GetAccessToken(t, user), http.MethodGet, BaseContactsAPIURL+"/"+contact.ID+"/addresses", "")
+
+	addresses := new(model.WebResponse[[]model.AddressResponse])
+	assert.Nil(t, json.Unmarshal(bytes, addresses))
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, len(addresses.Data))
+}
+
+func TestRestoreContactNotInTrash(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	other := CreateUser(t, "joko", model.RoleUser)
+	CreateContacts(user, 1)
+	CreateContacts(other, 1)
+	contact := GetFirstContact(t, user)
+	otherContact := GetFirstContact(t, other)
+
+	resp, _ := DoRequest(t, GetAccessToken(t, other), http.MethodDelete, BaseContactsAPIURL+"/"+otherContact.ID, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, _ = DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseContactsAPIURL+"/"+contact.ID+"/_restore", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseContactsAPIURL+"/"+otherContact.ID+"/_restore", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, bytes := DoRequest(t, GetAccessToken(t, user), http.MethodGet, BaseContactsAPIURL+"/_trash", "")
+
+	trash := new(model.WebResponse[[]model.ContactResponse])
+	assert.Nil(t, json.Unmarshal(bytes, trash))
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 0, len(trash.Data))
+}
+
+func TestTrashAddress(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	CreateContacts(user, 1)
+	contact := GetFirstContact(t, user)
+	CreateAddresses(t, contact, 2)
+	address := GetFirstAddress(t, contact)
+	addressesURL := BaseContactsAPIURL + "/" + contact.ID + "/addresses"
+
+	resp, _ := DoRequest(t, GetAccessToken(t, user), http.MethodDelete, addressesURL+"/"+address.ID, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, _ = DoRequest(t, GetAccessToken(t, user), http.MethodGet, addressesURL+"/"+address.ID, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, bytes := DoRequest(t, GetAccessToken(t, user), http.MethodGet, addressesURL+"/_trash", "")
+
+	trash := new(model.WebResponse[[]model.AddressResponse])
+	assert.Nil(t, json.Unmarshal(bytes, trash))
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, len(trash.Data))
+	assert.Equal(t, address.ID, trash.Data[0].ID)
+	assert.NotZero(t, trash.Data[0].DeletedAt)
+
+	resp, _ = DoRequest(t, GetAccessToken(t, user), http.MethodPost, addressesURL+"/"+address.ID+"/_restore", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, _ = DoRequest(t, GetAccessToken(t, user), http.MethodGet, addressesURL+"/"+address.ID, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, _ = DoRequest(t, GetAccessToken(t, user), http.MethodPost, addressesURL+"/"+address.ID+"/_restore", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestPurgeTrash(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	CreateContacts(user, 3)
+	contacts := ContactIds(t, user)
+	expired := &entity.Contact{ID: contacts[0]}
+	recent := &entity.Contact{ID: contacts[1]}
+	alive := &entity.Contact{ID: contacts[2]}
+	CreateAddresses(t, expired, 1)
+	CreateAddresses(t, alive, 2)
+	group := CreateGroup(t, user, "Family")
+	AddToGroup(t, group, expired.ID)
+
+	old := time.Now().Add(-31 * 24 * time.Hour).UnixMilli()
+	assert.Nil(t, db.Unscoped().Model(expired).Update("deleted_at", old).Error)
+	assert.Nil(t, db.Unscoped().Model(recent).Update("deleted_at", time.Now().UnixMilli()).Error)
+	assert.Nil(t, db.Unscoped().Model(GetFirstAddress(t, alive)).Update("deleted_at", old).Error)
+
+	total, err := newContactUseCase(30 * 24 * time.Hour).PurgeTrash(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 2, total)
+
+	var count int64
+	assert.Nil(t, db.Unscoped().Model(&entity.Contact{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+
+	assert.Nil(t, db.Unscoped().Model(&entity.Address{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	assert.Nil(t, db.Model(&entity.ContactGroup{}).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+}
+
+// newContactUseCase builds a use case with its own trash retention, the one behind app
+// uses whatever the test environment configures
+func newContactUseCase(retention time.Duration) *usecase.ContactUseCase {
+	return usecase.NewContactUseCase(db, log, validate, &usecase.ContactConfig{TrashRetention: retention}, repository.NewContactRepository(log),
+		repository.NewAddressRepository(log), repository.NewContactGroupRepository(log), repository.NewContactRevisionRepository(log))
+}
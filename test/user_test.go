@@ -5,10 +5,13 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/helper"
 	"github.com/iyasz/golang-clean-architecture/internal/model"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/crypto/bcrypt"
@@ -20,6 +23,7 @@ func TestRegister(t *testing.T) {
 		ID:       "khannedy",
 		Password: "rahasia",
 		Name:     "Eko Khannedy",
+		Email:    "khannedy@example.com",
 	}
 
 	bodyJson, err := json.Marshal(requestBody)
@@ -47,6 +51,7 @@ func TestRegister(t *testing.T) {
 	assert.Equal(t, http.StatusCreated, resp.StatusCode)
 	assert.Equal(t, requestBody.ID, responseBody.Data.ID)
 	assert.Equal(t, requestBody.Name, responseBody.Data.Name)
+	assert.Equal(t, requestBody.Email, responseBody.Data.Email)
 	assert.NotNil(t, responseBody.Data.CreatedAt)
 	assert.NotNil(t, responseBody.Data.UpdatedAt)
 }
@@ -93,6 +98,7 @@ func TestRegisterDuplicate(t *testing.T) {
 		ID:       "khannedy",
 		Password: "rahasia",
 		Name:     "Eko Khannedy",
+		Email:    "eko@example.com",
 	}
 
 	bodyJson, err := json.Marshal(requestBody)
@@ -147,17 +153,27 @@ func TestLogin(t *testing.T) {
 	bytes, err := io.ReadAll(resp.Body)
 	assert.Nil(t, err)
 
-	responseBody := new(model.WebResponse[model.UserResponse])
+	responseBody := new(model.WebResponse[model.TokenResponse])
 	err = json.Unmarshal(bytes, responseBody)
 	assert.Nil(t, err)
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
-	assert.NotNil(t, responseBody.Data.Token)
+	assert.NotEmpty(t, responseBody.Data.AccessToken)
+	assert.NotEmpty(t, responseBody.Data.RefreshToken)
 
-	user := new(entity.User)
-	err = db.Where("id = ?", requestBody.ID).First(user).Error
+	claims, err := jwt.ParseAccessToken(responseBody.Data.AccessToken)
+	assert.Nil(t, err)
+	assert.Equal(t, requestBody.ID, claims.Subject)
+
+	claims, err = jwt.ParseRefreshToken(responseBody.Data.RefreshToken)
+	assert.Nil(t, err)
+
+	session := new(entity.Session)
+	err = db.Where("id = ?", claims.SessionId).First(session).Error
 	assert.Nil(t, err)
-	assert.Equal(t, user.Token, responseBody.Data.Token)
+	assert.Equal(t, requestBody.ID, session.UserId)
+	assert.Equal(t, helper.HashToken(claims.ID), session.Token)
+	assert.NotEqual(t, claims.ID, session.Token)
 }
 
 func TestLoginWrongUsername(t *testing.T) {
@@ -230,6 +246,224 @@ func TestLoginWrongPassword(t *testing.T) {
 	assert.NotNil(t, responseBody.Errors)
 }
 
+func TestLoginLocked(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	for i := 0; i < conf.Login.MaxAttempts; i++ {
+		assert.Equal(t, http.StatusUnauthorized, LoginStatus(t, "khannedy", "wrong"))
+		ResetLoginDelay(t)
+	}
+
+	assert.Equal(t, http.StatusLocked, LoginStatus(t, "khannedy", "rahasia"))
+}
+
+func TestLoginUnlockedAfterLockout(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	for i := 0; i < conf.Login.MaxAttempts; i++ {
+		assert.Equal(t, http.StatusUnauthorized, LoginStatus(t, "khannedy", "wrong"))
+		ResetLoginDelay(t)
+	}
+
+	err := db.Model(&entity.LoginAttempt{}).Where("id is not null").Updates(map[string]any{
+		"locked_until":   0,
+		"last_failed_at": 0,
+	}).Error
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, LoginStatus(t, "khannedy", "rahasia"))
+
+	var total int64
+	err = db.Model(&entity.LoginAttempt{}).Where("id = ?", "user:khannedy").Count(&total).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), total)
+}
+
+func TestLoginTooManyRequestsFromIp(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	for i := 0; i < conf.Login.MaxAttemptsPerIp; i++ {
+		assert.Equal(t, http.StatusUnauthorized, LoginStatus(t, "wrong"+strconv.Itoa(i), "wrong"))
+		ResetLoginDelay(t)
+	}
+
+	assert.Equal(t, http.StatusTooManyRequests, LoginStatus(t, "khannedy", "rahasia"))
+}
+
+func TestLoginTooManyRequestsFromSpoofedIp(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	login := func(id string, password string, forwardedFor string) int {
+		bodyJson, err := json.Marshal(model.LoginUserRequest{ID: id, Password: password})
+		assert.Nil(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, server.URL+BaseUsersAPIURL+"/_login", strings.NewReader(string(bodyJson)))
+		assert.Nil(t, err)
+		SetupHeader(req)
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		req.Header.Set("X-Real-IP", forwardedFor)
+
+		resp, err := http.DefaultClient.Do(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+
+		return resp.StatusCode
+	}
+
+	for i := 0; i < conf.Login.MaxAttemptsPerIp; i++ {
+		assert.Equal(t, http.StatusUnauthorized, login("wrong"+strconv.Itoa(i), "wrong", "203.0.113."+strconv.Itoa(i)))
+		ResetLoginDelay(t)
+	}
+
+	assert.Equal(t, http.StatusTooManyRequests, login("khannedy", "rahasia", "198.51.100.1"))
+}
+
+func TestLoginProgressiveDelay(t *testing.T) {
+	if conf.Login.Delay == 0 {
+		t.Skip("login delay is disabled")
+	}
+
+	ClearAll()
+	TestRegister(t)
+
+	assert.Equal(t, http.StatusUnauthorized, LoginStatus(t, "khannedy", "wrong"))
+	assert.Equal(t, http.StatusTooManyRequests, LoginStatus(t, "khannedy", "rahasia"))
+
+	attempt := new(entity.LoginAttempt)
+	err := db.Where("id = ?", "user:khannedy").Take(attempt).Error
+	assert.Nil(t, err)
+	assert.Equal(t, 1, attempt.Failures)
+	assert.Greater(t, attempt.RetryAt, attempt.LastFailedAt)
+}
+
+func TestRefreshToken(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	login := Login(t, "khannedy", "rahasia")
+
+	requestBody := model.RefreshTokenRequest{
+		RefreshToken: login.RefreshToken,
+	}
+
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+BaseUsersAPIURL+"/_refresh", strings.NewReader(string(bodyJson)))
+	assert.Nil(t, err)
+	SetupHeader(req)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.TokenResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEmpty(t, responseBody.Data.AccessToken)
+	assert.NotEmpty(t, responseBody.Data.RefreshToken)
+	assert.NotEqual(t, login.RefreshToken, responseBody.Data.RefreshToken)
+}
+
+func TestRefreshTokenReused(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	login := Login(t, "khannedy", "rahasia")
+	Refresh(t, login.RefreshToken)
+
+	requestBody := model.RefreshTokenRequest{
+		RefreshToken: login.RefreshToken,
+	}
+
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+BaseUsersAPIURL+"/_refresh", strings.NewReader(string(bodyJson)))
+	assert.Nil(t, err)
+	SetupHeader(req)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.TokenResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.NotNil(t, responseBody.Errors)
+}
+
+func TestRefreshTokenIdleTimeout(t *testing.T) {
+	ClearAll()
+	TestRegister(t)
+
+	login := Login(t, "khannedy", "rahasia")
+	claims, err := jwt.ParseRefreshToken(login.RefreshToken)
+	assert.Nil(t, err)
+
+	err = db.Model(&entity.Session{}).Where("id = ?", claims.SessionId).Update("last_seen_at", 0).Error
+	assert.Nil(t, err)
+
+	requestBody := model.RefreshTokenRequest{
+		RefreshToken: login.RefreshToken,
+	}
+
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+BaseUsersAPIURL+"/_refresh", strings.NewReader(string(bodyJson)))
+	assert.Nil(t, err)
+	SetupHeader(req)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := make(map[string]string)
+	err = json.Unmarshal(bytes, &responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, helper.ErrTokenExpired.Message, responseBody["error"])
+
+	var total int64
+	err = db.Model(&entity.Session{}).Where("id = ?", claims.SessionId).Count(&total).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), total)
+}
+
 func TestLogout(t *testing.T) {
 	ClearAll()
 	TestLogin(t) 
@@ -244,7 +478,7 @@ func TestLogout(t *testing.T) {
 	req, err := http.NewRequest(http.MethodDelete, server.URL+BaseUsersAPIURL, nil)
 	assert.Nil(t, err)
 	SetupHeader(req)
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -260,6 +494,12 @@ func TestLogout(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 	assert.True(t, responseBody.Data)
+
+	// only the session used for logout is revoked, the one from TestLogin stays
+	var total int64
+	err = db.Model(&entity.Session{}).Where("user_id = ?", user.ID).Count(&total).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
 }
 
 func TestLogoutWrongAuthorization(t *testing.T) {
@@ -304,7 +544,7 @@ func TestGetCurrentUser(t *testing.T) {
 	req, err := http.NewRequest(http.MethodGet, server.URL+BaseUsersAPIURL+"/_current", nil)
 	assert.Nil(t, err)
 	SetupHeader(req)
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -353,6 +593,40 @@ func TestGetCurrentUserFailed(t *testing.T) {
 	assert.NotNil(t, responseBody.Errors)
 }
 
+func TestGetCurrentUserTokenExpired(t *testing.T) {
+	ClearAll()
+	TestLogin(t)
+
+	user := GetFirstUser(t)
+	session := CreateSession(t, user)
+	token, _, err := jwt.GenerateAccessToken(&helper.AccessToken{UserId: user.ID, SessionId: session.ID, Role: user.Role, NotAfter: time.Now().Add(-time.Minute)})
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+BaseUsersAPIURL+"/_current", nil)
+	assert.Nil(t, err)
+	SetupHeader(req)
+	req.Header.Set("Authorization", token)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	responseBody := make(map[string]string)
+	err = json.Unmarshal(bytes, &responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, helper.ErrTokenExpired.Message, responseBody["error"])
+	assert.Contains(t, resp.Header.Get("WWW-Authenticate"), "invalid_token")
+}
+
 func TestUpdateUserName(t *testing.T) {
 	ClearAll()
 	TestLogin(t) 
@@ -374,7 +648,7 @@ func TestUpdateUserName(t *testing.T) {
 	req, err := http.NewRequest(http.MethodPatch, server.URL+BaseUsersAPIURL+"/_current", strings.NewReader(string(bodyJson)))
 	assert.Nil(t, err)
 	SetupHeader(req)
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -416,7 +690,7 @@ func TestUpdateUserPassword(t *testing.T) {
 	req, err := http.NewRequest(http.MethodPatch, server.URL+BaseUsersAPIURL+"/_current", strings.NewReader(string(bodyJson)))
 	assert.Nil(t, err)
 	SetupHeader(req)
-	req.Header.Set("Authorization", user.Token)
+	req.Header.Set("Authorization", GetAccessToken(t, user))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -0,0 +1,176 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/emersion/go-vcard"
+	"github.com/iyasz/golang-clean-architecture/internal/entity"
+	"github.com/iyasz/golang-clean-architecture/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportContacts(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	CreateContacts(user, 3)
+	contact := GetFirstContact(t, user)
+	CreateAddresses(t, contact, 1)
+
+	resp, bytes := DoRequest(t, GetAccessToken(t, user), http.MethodGet, BaseContactsAPIURL+"/_export.vcf?email="+contact.Email, "")
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/vcard; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	cards := decodeCards(t, string(bytes))
+	assert.Equal(t, 1, len(cards))
+	assert.Equal(t, "3.0", cards[0].Value(vcard.FieldVersion))
+	assert.Equal(t, contact.FirstName, cards[0].Name().GivenName)
+	assert.Equal(t, contact.LastName, cards[0].Name().FamilyName)
+	assert.Equal(t, contact.Email, cards[0].Value(vcard.FieldEmail))
+	assert.Equal(t, contact.Phone, cards[0].Value(vcard.FieldTelephone))
+	assert.Equal(t, "Jakarta", cards[0].Address().Locality)
+
+	resp, bytes = DoRequest(t, GetAccessToken(t, user), http.MethodGet, BaseContactsAPIURL+"/_export.vcf?version=4.0", "")
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	cards = decodeCards(t, string(bytes))
+	assert.Equal(t, 3, len(cards))
+	assert.Equal(t, "4.0", cards[0].Value(vcard.FieldVersion))
+}
+
+func TestExportContactsNoMatch(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	CreateContacts(user, 1)
+
+	resp, bytes := DoRequest(t, GetAccessToken(t, user), http.MethodGet, BaseContactsAPIURL+"/_export.vcf?email=nobody@example.com", "")
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/vcard; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Empty(t, bytes)
+}
+
+func TestExportContact(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+	other := CreateUser(t, "joko", model.RoleUser)
+	CreateContacts(user, 2)
+	CreateContacts(other, 1)
+	contact := GetFirstContact(t, user)
+
+	resp, bytes := DoRequest(t, GetAccessToken(t, user), http.MethodGet, BaseContactsAPIURL+"/"+contact.ID+".vcf", "")
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	cards := decodeCards(t, string(bytes))
+	assert.Equal(t, 1, len(cards))
+	assert.Equal(t, "urn:uuid:"+contact.ID, cards[0].Value(vcard.FieldUID))
+
+	resp, _ = DoRequest(t, GetAccessToken(t, user), http.MethodGet, BaseContactsAPIURL+"/"+GetFirstContact(t, other).ID+".vcf", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = DoRequest(t, GetAccessToken(t, user), http.MethodGet, BaseContactsAPIURL+"/"+contact.ID+".vcf?version=2.1", "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestImportContacts(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	cards := "BEGIN:VCARD\r\nVERSION:3.0\r\nN:Khannedy;Eko;;;\r\nFN:Eko Khannedy\r\nEMAIL;TYPE=INTERNET:eko@example.com\r\n" +
+		"TEL;TYPE=CELL:08123456789\r\nADR;TYPE=HOME:;;Jalan Belum Ada;Jakarta;DKI Jakarta;12345;Indonesia\r\nEND:VCARD\r\n" +
+		"BEGIN:VCARD\r\nVERSION:4.0\r\nFN:Budi Nugraha\r\nEMAIL:budi@example.com\r\nTEL;VALUE=uri:tel:+62-811-1111\r\nEND:VCARD\r\n" +
+		"BEGIN:VCARD\r\nVERSION:3.0\r\nFN:Joko Morro\r\nEMAIL:not an email\r\nEND:VCARD\r\n"
+
+	resp, bytes := DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseContactsAPIURL+"/_import", cards)
+
+	responseBody := new(model.WebResponse[model.ImportContactResponse])
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, responseBody.Data.Imported)
+	assert.Equal(t, 1, responseBody.Data.Failed)
+	assert.Equal(t, 1, responseBody.Data.Results[0].Addresses)
+	assert.NotEmpty(t, responseBody.Data.Results[1].ContactId)
+	assert.Equal(t, "Joko Morro", responseBody.Data.Results[2].Name)
+	assert.Equal(t, []string{"email"}, responseBody.Data.Results[2].Errors)
+	assert.Empty(t, responseBody.Data.Results[2].ContactId)
+
+	eko := new(entity.Contact)
+	assert.Nil(t, db.Preload("Addresses").Where("id = ?", responseBody.Data.Results[0].ContactId).Take(eko).Error)
+	assert.Equal(t, "Eko", eko.FirstName)
+	assert.Equal(t, "Khannedy", eko.LastName)
+	assert.Equal(t, "08123456789", eko.Phone)
+	assert.Equal(t, 1, len(eko.Addresses))
+	assert.Equal(t, "Jalan Belum Ada", eko.Addresses[0].Street)
+	assert.Equal(t, "12345", eko.Addresses[0].PostalCode)
+
+	// a name without N is split from FN
+	budi := new(entity.Contact)
+	assert.Nil(t, db.Where("id = ?", responseBody.Data.Results[1].ContactId).Take(budi).Error)
+	assert.Equal(t, "Budi", budi.FirstName)
+	assert.Equal(t, "Nugraha", budi.LastName)
+	assert.Equal(t, "+62-811-1111", budi.Phone)
+	assert.Equal(t, int64(2), SearchContacts(t, user, "").Paging.TotalItem)
+}
+
+func TestImportContactWithoutEmail(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	cards := "BEGIN:VCARD\r\nVERSION:3.0\r\nFN:Joko Morro\r\nTEL;TYPE=CELL:08123456789\r\nEND:VCARD\r\n"
+	resp, bytes := DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseContactsAPIURL+"/_import", cards)
+
+	responseBody := new(model.WebResponse[model.ImportContactResponse])
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, responseBody.Data.Imported)
+	assert.Empty(t, responseBody.Data.Results[0].Errors)
+
+	joko := new(entity.Contact)
+	assert.Nil(t, db.Where("id = ?", responseBody.Data.Results[0].ContactId).Take(joko).Error)
+	assert.Equal(t, "", joko.Email)
+	assert.Equal(t, "08123456789", joko.Phone)
+}
+
+func TestImportContactsFailed(t *testing.T) {
+	ClearAll()
+	user := CreateUser(t, "khannedy", model.RoleUser)
+
+	resp, _ := DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseContactsAPIURL+"/_import", "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// nothing that even starts a card
+	resp, _ = DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseContactsAPIURL+"/_import", "not a vcard at all\r\n")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// a card without an end is reported, the ones before it are still imported
+	cards := "BEGIN:VCARD\r\nVERSION:3.0\r\nN:Khannedy;Eko;;;\r\nEMAIL:eko@example.com\r\nEND:VCARD\r\n" +
+		"BEGIN:VCARD\r\nVERSION:3.0\r\nN:Morro;Joko;;;\r\n"
+	resp, bytes := DoRequest(t, GetAccessToken(t, user), http.MethodPost, BaseContactsAPIURL+"/_import", cards)
+
+	responseBody := new(model.WebResponse[model.ImportContactResponse])
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, responseBody.Data.Imported)
+	assert.Equal(t, []string{"card"}, responseBody.Data.Results[1].Errors)
+	assert.Equal(t, int64(1), SearchContacts(t, user, "").Paging.TotalItem)
+}
+
+func decodeCards(t *testing.T, text string) []vcard.Card {
+	var cards []vcard.Card
+	decoder := vcard.NewDecoder(strings.NewReader(text))
+	for {
+		card, err := decoder.Decode()
+		if err == io.EOF {
+			return cards
+		}
+		assert.Nil(t, err)
+		cards = append(cards, card)
+	}
+}